@@ -11,10 +11,14 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
+	"github.com/ibnuzaman/ewallet-ums/database"
 	"github.com/ibnuzaman/ewallet-ums/helpers"
 	"github.com/ibnuzaman/ewallet-ums/internal/api"
 	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/geoip"
 	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/notifier"
+	"github.com/ibnuzaman/ewallet-ums/internal/repository"
 	"github.com/ibnuzaman/ewallet-ums/internal/services"
 )
 
@@ -33,6 +37,87 @@ func ServerHTTP() {
 
 	// Routes
 	r.Get("/healthcheck", dependency.HealthcheckAPI.HealthcheckHandlerHTTP)
+	r.Get("/.well-known/jwks.json", dependency.AuthAPI.JWKSHandlerHTTP)
+
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Post("/users/register", dependency.UserAPI.RegisterHandlerHTTP)
+		r.Post("/auth/login", dependency.AuthAPI.LoginHandlerHTTP)
+		r.Post("/auth/login/mfa", dependency.AuthAPI.LoginMFAHandlerHTTP)
+		r.Post("/auth/login/mfa/passkey/options", dependency.AuthAPI.MFAPasskeyOptionsHandlerHTTP)
+		r.Post("/auth/passkey/options", dependency.AuthAPI.PasskeyLoginOptionsHandlerHTTP)
+		r.Post("/auth/passkey/login", dependency.AuthAPI.PasskeyLoginHandlerHTTP)
+		r.Post("/auth/refresh", dependency.AuthAPI.RefreshHandlerHTTP)
+		r.Post("/auth/verify-email", dependency.UserAPI.VerifyEmailHandlerHTTP)
+		r.Post("/auth/verify-email/resend", dependency.UserAPI.ResendEmailVerificationHandlerHTTP)
+		r.Post("/auth/password/forgot", dependency.PasswordAPI.ForgotPasswordHandlerHTTP)
+		r.Post("/auth/password/reset", dependency.PasswordAPI.ResetPasswordHandlerHTTP)
+
+		// Phone verification, limited per client IP on top of the per-phone limit
+		r.Group(func(r chi.Router) {
+			r.Use(dependency.OTPRateLimit)
+
+			r.Post("/auth/phone/otp", dependency.PhoneVerificationAPI.RequestOTPHandlerHTTP)
+			r.Post("/auth/phone/verify", dependency.PhoneVerificationAPI.VerifyOTPHandlerHTTP)
+		})
+
+		// Protected routes
+		r.Group(func(r chi.Router) {
+			r.Use(dependency.Authenticate)
+
+			r.Get("/users/me", dependency.UserAPI.GetMeHandlerHTTP)
+			r.Patch("/users/me", dependency.UserAPI.UpdateMeHandlerHTTP)
+			r.Post("/users/me/password", dependency.PasswordAPI.ChangePasswordHandlerHTTP)
+			r.Post("/users/me/mfa/totp", dependency.MFAAPI.EnrollTOTPHandlerHTTP)
+			r.Post("/users/me/mfa/totp/confirm", dependency.MFAAPI.ConfirmTOTPHandlerHTTP)
+			r.Post("/users/me/mfa/totp/disable", dependency.MFAAPI.DisableTOTPHandlerHTTP)
+			r.Post("/users/me/mfa/recovery-codes", dependency.MFAAPI.RegenerateRecoveryCodesHandlerHTTP)
+			r.Post("/users/me/passkeys/options", dependency.PasskeyAPI.RegistrationOptionsHandlerHTTP)
+			r.Post("/users/me/passkeys", dependency.PasskeyAPI.RegisterHandlerHTTP)
+			r.Get("/users/me/passkeys", dependency.PasskeyAPI.ListHandlerHTTP)
+			r.Delete("/users/me/passkeys/{id}", dependency.PasskeyAPI.DeleteHandlerHTTP)
+			r.Get("/users/me/sessions", dependency.DeviceAPI.ListSessionsHandlerHTTP)
+			r.Delete("/users/me/sessions/{id}", dependency.DeviceAPI.RevokeSessionHandlerHTTP)
+			r.Delete("/users/me/devices/{id}", dependency.DeviceAPI.RevokeDeviceHandlerHTTP)
+			r.Post("/auth/logout", dependency.AuthAPI.LogoutHandlerHTTP)
+			r.Post("/auth/logout-all", dependency.AuthAPI.LogoutAllHandlerHTTP)
+
+			// Transaction PIN, only for verified accounts
+			r.Group(func(r chi.Router) {
+				r.Use(helpers.RequireVerified)
+
+				r.Post("/users/me/pin", dependency.PINAPI.SetPINHandlerHTTP)
+				r.Post("/users/me/pin/change", dependency.PINAPI.ChangePINHandlerHTTP)
+				r.Post("/users/me/pin/reset/otp", dependency.PINAPI.RequestPINResetHandlerHTTP)
+				r.Post("/users/me/pin/reset", dependency.PINAPI.ResetPINHandlerHTTP)
+			})
+		})
+
+		// Admin routes for support staff
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(dependency.Authenticate)
+
+			r.With(helpers.RequirePermission(constants.PermissionUsersRead)).
+				Get("/users", dependency.AdminUserAPI.ListUsersHandlerHTTP)
+			r.With(helpers.RequirePermission(constants.PermissionUsersRead)).
+				Get("/users/{id}", dependency.AdminUserAPI.GetUserHandlerHTTP)
+			// Checks users:write and users:deactivate depending on the fields being changed
+			r.Patch("/users/{id}", dependency.AdminUserAPI.UpdateUserHandlerHTTP)
+			r.With(helpers.RequirePermission(constants.PermissionUsersDelete)).
+				Delete("/users/{id}", dependency.AdminUserAPI.DeleteUserHandlerHTTP)
+			r.With(helpers.RequirePermission(constants.PermissionSessionsRevoke)).
+				Post("/users/{id}/sessions/revoke", dependency.AdminUserAPI.RevokeSessionsHandlerHTTP)
+			r.With(helpers.RequirePermission(constants.PermissionUsersDeactivate)).
+				Post("/users/{id}/unlock", dependency.AdminUserAPI.UnlockUserHandlerHTTP)
+		})
+
+		// Internal routes for other e-wallet services
+		r.Route("/internal", func(r chi.Router) {
+			r.Use(helpers.RequireServiceKey)
+
+			r.Get("/token/validate", dependency.AuthAPI.ValidateTokenHandlerHTTP)
+			r.Post("/pin/verify", dependency.PINAPI.VerifyPINHandlerHTTP)
+		})
+	})
 
 	// Server configuration
 	port := helpers.GetEnv("PORT", "8080")
@@ -71,16 +156,185 @@ func ServerHTTP() {
 
 // Dependency holds all API dependencies.
 type Dependency struct {
-	HealthcheckAPI interfaces.IHealthcheckAPI
+	HealthcheckAPI       interfaces.IHealthcheckAPI
+	UserAPI              interfaces.IUserAPI
+	AuthAPI              interfaces.IAuthAPI
+	AdminUserAPI         interfaces.IAdminUserAPI
+	PhoneVerificationAPI interfaces.IPhoneVerificationAPI
+	PasswordAPI          interfaces.IPasswordAPI
+	MFAAPI               interfaces.IMFAAPI
+	PasskeyAPI           interfaces.IPasskeyAPI
+	PINAPI               interfaces.IPINAPI
+	DeviceAPI            interfaces.IDeviceAPI
+	Authenticate         func(http.Handler) http.Handler
+	OTPRateLimit         func(http.Handler) http.Handler
 }
 
 func dependencyInject() Dependency {
+	db := database.GetPostgresDB()
+
+	// Repositories
+	userRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	phoneOTPRepo := repository.NewPhoneOTPRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	webAuthnRepo := repository.NewWebAuthnRepository(db)
+	deviceRepo := repository.NewDeviceRepository(db)
+
+	relyingParty, err := helpers.NewWebAuthn()
+	if err != nil {
+		helpers.Logger.Fatalf("Failed to configure WebAuthn: %v", err)
+	}
+
+	// Notifications are logged and SMS written to an outbox until delivery providers are configured
+	notify := &notifier.Log{}
+	sms := &notifier.SMSOutbox{Path: helpers.GetEnv("SMS_OUTBOX_FILE", "")}
+
+	// Shared so that verification, new device login and PIN reset codes count towards the
+	// same per-phone limit
+	phoneLimiter := helpers.NewRateLimiter(
+		helpers.GetEnvInt("OTP_PHONE_RATE_LIMIT", constants.DefaultOTPPhoneRateLimit), constants.OTPRateLimitWindow)
+
+	loginIPDenylist, err := helpers.ParseIPPrefixes(helpers.GetEnv("LOGIN_IP_DENYLIST", ""))
+	if err != nil {
+		helpers.Logger.Fatalf("Invalid LOGIN_IP_DENYLIST: %v", err)
+	}
+	loginRiskSvc := &services.LoginRisk{
+		SessionRepository: sessionRepo,
+		Notifier:          notify,
+		Denylist:          loginIPDenylist,
+	}
+	// Impossible travel is only detected with a local GeoIP database
+	if path := helpers.GetEnv("GEOIP_DATABASE_FILE", ""); path != "" {
+		geoLocator, err := geoip.OpenMaxMind(path)
+		if err != nil {
+			helpers.Logger.Fatalf("Failed to load GeoIP database: %v", err)
+		}
+		loginRiskSvc.GeoLocator = geoLocator
+	}
+
 	healthcheckSvc := &services.Healthcheck{}
 	healthcheckAPI := &api.Healthcheck{
 		HealthcheckServices: healthcheckSvc,
 	}
 
+	userSvc := &services.User{
+		UserRepository:      userRepo,
+		UserTokenRepository: userTokenRepo,
+		Notifier:            notify,
+	}
+	userAPI := &api.User{
+		UserServices: userSvc,
+	}
+
+	authSvc := &services.Auth{
+		UserRepository:      userRepo,
+		SessionRepository:   sessionRepo,
+		RoleRepository:      roleRepo,
+		MFARepository:       mfaRepo,
+		UserTokenRepository: userTokenRepo,
+		WebAuthnRepository:  webAuthnRepo,
+		WebAuthn:            relyingParty,
+		LoginIPLimiter: helpers.NewRateLimiter(
+			helpers.GetEnvInt("LOGIN_IP_MAX_FAILURES", constants.DefaultLoginIPMaxFailures), constants.LoginIPFailureWindow),
+		DeviceRepository:   deviceRepo,
+		PhoneOTPRepository: phoneOTPRepo,
+		SMSProvider:        sms,
+		PhoneLimiter:       phoneLimiter,
+		LoginRiskAssessor:  loginRiskSvc,
+	}
+	authAPI := &api.Auth{
+		AuthServices: authSvc,
+	}
+
+	adminUserSvc := &services.AdminUser{
+		UserRepository:      userRepo,
+		SessionRepository:   sessionRepo,
+		UserTokenRepository: userTokenRepo,
+	}
+	adminUserAPI := &api.AdminUser{
+		AdminUserServices: adminUserSvc,
+	}
+
+	passwordSvc := &services.Password{
+		UserRepository:      userRepo,
+		SessionRepository:   sessionRepo,
+		UserTokenRepository: userTokenRepo,
+		WebAuthnRepository:  webAuthnRepo,
+		Notifier:            notify,
+	}
+	passwordAPI := &api.Password{
+		PasswordServices: passwordSvc,
+	}
+
+	phoneVerificationSvc := &services.PhoneVerification{
+		UserRepository:     userRepo,
+		PhoneOTPRepository: phoneOTPRepo,
+		SMSProvider:        sms,
+		PhoneLimiter:       phoneLimiter,
+	}
+	phoneVerificationAPI := &api.PhoneVerification{
+		PhoneVerificationServices: phoneVerificationSvc,
+	}
+	mfaSvc := &services.MFA{
+		UserRepository: userRepo,
+		MFARepository:  mfaRepo,
+	}
+	mfaAPI := &api.MFA{
+		MFAServices: mfaSvc,
+	}
+
+	passkeySvc := &services.Passkey{
+		UserRepository:     userRepo,
+		MFARepository:      mfaRepo,
+		WebAuthnRepository: webAuthnRepo,
+		WebAuthn:           relyingParty,
+	}
+	passkeyAPI := &api.Passkey{
+		PasskeyServices: passkeySvc,
+	}
+
+	pinSvc := &services.PIN{
+		UserRepository:     userRepo,
+		PhoneOTPRepository: phoneOTPRepo,
+		SMSProvider:        sms,
+		PhoneLimiter:       phoneLimiter,
+	}
+	pinAPI := &api.PIN{
+		PINServices: pinSvc,
+	}
+
+	deviceSvc := &services.Device{
+		SessionRepository: sessionRepo,
+		DeviceRepository:  deviceRepo,
+	}
+	deviceAPI := &api.Device{
+		DeviceServices: deviceSvc,
+	}
+
+	otpRateLimit := helpers.RateLimitByIP(helpers.NewRateLimiter(
+		helpers.GetEnvInt("OTP_IP_RATE_LIMIT", constants.DefaultOTPIPRateLimit), constants.OTPRateLimitWindow))
+
+	// Reject access tokens whose session was revoked or replaced
+	authenticate := helpers.Authenticate(func(ctx context.Context, accessToken string, _ *helpers.Principal) error {
+		_, err := authSvc.Authenticate(ctx, accessToken)
+		return err
+	})
+
 	return Dependency{
-		HealthcheckAPI: healthcheckAPI,
+		HealthcheckAPI:       healthcheckAPI,
+		UserAPI:              userAPI,
+		AuthAPI:              authAPI,
+		AdminUserAPI:         adminUserAPI,
+		PhoneVerificationAPI: phoneVerificationAPI,
+		PasswordAPI:          passwordAPI,
+		MFAAPI:               mfaAPI,
+		PasskeyAPI:           passkeyAPI,
+		PINAPI:               pinAPI,
+		DeviceAPI:            deviceAPI,
+		Authenticate:         authenticate,
+		OTPRateLimit:         otpRateLimit,
 	}
 }
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_users_phone_number;
+CREATE INDEX IF NOT EXISTS idx_users_phone ON users(phone_number);
+
+ALTER TABLE users ALTER COLUMN updated_at DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN created_at DROP NOT NULL;
+
+ALTER TABLE users ALTER COLUMN full_name DROP NOT NULL;
+
+-- Keep VARCHAR(20) so rolling back cannot truncate stored numbers
+ALTER TABLE users ALTER COLUMN phone_number DROP NOT NULL;
+
+-- Users created after this migration have no username
+UPDATE users SET username = id::text WHERE username IS NULL;
+ALTER TABLE users ALTER COLUMN username SET NOT NULL;
+
+ALTER TABLE users RENAME COLUMN password_hash TO password;
@@ -0,0 +1,20 @@
+-- Store the password hash under an explicit name
+ALTER TABLE users RENAME COLUMN password TO password_hash;
+
+-- Username is not part of the user model
+ALTER TABLE users ALTER COLUMN username DROP NOT NULL;
+
+-- Phone is a required identifier (E.164, up to 15 digits plus "+")
+ALTER TABLE users ALTER COLUMN phone_number TYPE VARCHAR(20);
+ALTER TABLE users ALTER COLUMN phone_number SET NOT NULL;
+
+-- Full name is required, backfill legacy rows
+UPDATE users SET full_name = '' WHERE full_name IS NULL;
+ALTER TABLE users ALTER COLUMN full_name SET NOT NULL;
+
+ALTER TABLE users ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE users ALTER COLUMN updated_at SET NOT NULL;
+
+-- Phone numbers are unique among non-deleted users
+DROP INDEX IF EXISTS idx_users_phone;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone_number ON users(phone_number) WHERE deleted_at IS NULL;
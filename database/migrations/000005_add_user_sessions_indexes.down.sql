@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_user_sessions_refresh_token_expires_at;
+DROP INDEX IF EXISTS idx_user_sessions_user_id;
+
+ALTER TABLE user_sessions ALTER COLUMN is_revoked DROP NOT NULL;
@@ -0,0 +1,7 @@
+-- Revocation state must always be known
+UPDATE user_sessions SET is_revoked = FALSE WHERE is_revoked IS NULL;
+ALTER TABLE user_sessions ALTER COLUMN is_revoked SET NOT NULL;
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_refresh_token_expires_at ON user_sessions(refresh_token_expires_at);
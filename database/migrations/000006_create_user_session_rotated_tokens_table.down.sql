@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_session_rotated_tokens_session_id;
+
+DROP TABLE IF EXISTS user_session_rotated_tokens;
@@ -0,0 +1,10 @@
+-- Refresh tokens that were already exchanged. Presenting one again means the
+-- token was stolen or replayed, so the owning session gets revoked.
+CREATE TABLE IF NOT EXISTS user_session_rotated_tokens (
+    refresh_token TEXT PRIMARY KEY NOT NULL,
+    session_id uuid NOT NULL REFERENCES user_sessions(id) ON DELETE CASCADE,
+    rotated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_user_session_rotated_tokens_session_id ON user_session_rotated_tokens(session_id);
@@ -0,0 +1,63 @@
+CREATE TABLE IF NOT EXISTS roles (
+    id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) UNIQUE NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) UNIQUE NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id uuid NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id uuid NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id uuid NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, role_id)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_role_permissions_permission_id ON role_permissions(permission_id);
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles(role_id);
+
+-- Seed roles and permissions
+INSERT INTO roles (name, description) VALUES
+    ('customer', 'E-wallet end user'),
+    ('merchant', 'Business accepting payments'),
+    ('support', 'Customer support staff'),
+    ('admin', 'Full administrative access'),
+    ('service', 'Internal e-wallet service account')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:read', 'View user accounts'),
+    ('users:write', 'Edit user profile details'),
+    ('users:deactivate', 'Activate or deactivate user accounts'),
+    ('users:delete', 'Delete user accounts'),
+    ('sessions:revoke', 'Revoke sessions of other users')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON
+    r.name = 'admin'
+    OR (r.name = 'support' AND p.name IN ('users:read', 'users:deactivate', 'sessions:revoke'))
+    OR (r.name = 'service' AND p.name = 'users:read')
+ON CONFLICT DO NOTHING;
+
+-- Existing users are customers
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id
+FROM users u
+JOIN roles r ON r.name = 'customer'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_user_tokens_expires_at;
+DROP INDEX IF EXISTS idx_user_tokens_user_id_purpose;
+
+DROP TABLE IF EXISTS user_tokens;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_phone_otps_expires_at;
+DROP INDEX IF EXISTS idx_phone_otps_phone_number;
+
+DROP TABLE IF EXISTS phone_otps;
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_phone_verified;
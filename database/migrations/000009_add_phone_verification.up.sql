@@ -0,0 +1,18 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_phone_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- One-time passwords sent by SMS to verify users.phone_number.
+-- Only the SHA-256 hash of each code is stored.
+CREATE TABLE IF NOT EXISTS phone_otps (
+    id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    phone_number VARCHAR(20) NOT NULL,
+    code_hash TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    consumed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_phone_otps_phone_number ON phone_otps(phone_number, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_phone_otps_expires_at ON phone_otps(expires_at);
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS hard_locked_at,
+    DROP COLUMN IF EXISTS locked_until,
+    DROP COLUMN IF EXISTS lockout_count,
+    DROP COLUMN IF EXISTS failed_login_attempts;
@@ -0,0 +1,8 @@
+-- Login lockout state. failed_login_attempts counts wrong passwords since the last lockout
+-- or successful login, lockout_count the temporary lockouts since the last successful login.
+-- hard_locked_at is set once temporary lockouts are exhausted and cleared by a password reset.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS lockout_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS hard_locked_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_mfa_recovery_codes_user_id_code_hash;
+
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_totp;
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_mfa_enabled;
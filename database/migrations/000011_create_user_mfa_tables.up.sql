@@ -0,0 +1,25 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- TOTP authenticators. The secret is encrypted with DATA_ENCRYPTION_KEY and only
+-- used for login once confirmed_at is set. last_used_step stops codes being replayed.
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id uuid PRIMARY KEY NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    secret_encrypted TEXT NOT NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Single-use recovery codes for users who lost their authenticator.
+-- Only the SHA-256 hash of each code is stored.
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id_code_hash ON mfa_recovery_codes(user_id, code_hash);
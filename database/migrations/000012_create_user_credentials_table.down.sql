@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_webauthn_sessions_expires_at;
+DROP INDEX IF EXISTS idx_user_credentials_user_id;
+
+DROP TABLE IF EXISTS webauthn_sessions;
+DROP TABLE IF EXISTS user_credentials;
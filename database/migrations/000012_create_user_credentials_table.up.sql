@@ -0,0 +1,33 @@
+-- WebAuthn credentials (passkeys and security keys). Registering one makes it
+-- usable for passwordless login and as a second factor after the password.
+CREATE TABLE IF NOT EXISTS user_credentials (
+    id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id BYTEA UNIQUE NOT NULL,
+    public_key BYTEA NOT NULL,
+    attestation_type VARCHAR(50) NOT NULL,
+    aaguid BYTEA,
+    transports TEXT[] NOT NULL DEFAULT '{}',
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    backup_state BOOLEAN NOT NULL DEFAULT FALSE,
+    name VARCHAR(100) NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Pending WebAuthn ceremonies, looked up by the challenge the client signs.
+-- user_id is NULL for passwordless logins, where the user is not known yet.
+CREATE TABLE IF NOT EXISTS webauthn_sessions (
+    id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    user_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(50) NOT NULL,
+    challenge TEXT UNIQUE NOT NULL,
+    session_data JSONB NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_user_credentials_user_id ON user_credentials(user_id);
+CREATE INDEX IF NOT EXISTS idx_webauthn_sessions_expires_at ON webauthn_sessions(expires_at);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_phone_otps_phone_number;
+CREATE INDEX IF NOT EXISTS idx_phone_otps_phone_number ON phone_otps(phone_number, created_at DESC);
+
+ALTER TABLE phone_otps DROP COLUMN IF EXISTS purpose;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS pin_updated_at,
+    DROP COLUMN IF EXISTS pin_locked_until,
+    DROP COLUMN IF EXISTS pin_failed_attempts,
+    DROP COLUMN IF EXISTS pin_hash;
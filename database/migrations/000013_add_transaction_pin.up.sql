@@ -0,0 +1,14 @@
+-- Transaction PIN confirming money movements, separate from the login password.
+-- pin_failed_attempts counts wrong PINs since the last lockout or correct PIN.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS pin_hash TEXT,
+    ADD COLUMN IF NOT EXISTS pin_failed_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS pin_locked_until TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS pin_updated_at TIMESTAMP WITH TIME ZONE;
+
+-- SMS codes are also sent to reset the PIN, so each code records what it was sent for
+-- and can only be used for that.
+ALTER TABLE phone_otps ADD COLUMN IF NOT EXISTS purpose VARCHAR(50) NOT NULL DEFAULT 'phone_verification';
+
+DROP INDEX IF EXISTS idx_phone_otps_phone_number;
+CREATE INDEX IF NOT EXISTS idx_phone_otps_phone_number ON phone_otps(phone_number, purpose, created_at DESC);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_user_sessions_device_id;
+
+ALTER TABLE user_sessions
+    DROP COLUMN IF EXISTS platform,
+    DROP COLUMN IF EXISTS device_name,
+    DROP COLUMN IF EXISTS device_id;
+
+DROP TABLE IF EXISTS user_devices;
@@ -0,0 +1,23 @@
+-- Devices a user completed a verified login from. Clients send a random device_id
+-- generated once per install; only its SHA-256 hash is stored since a known device
+-- skips the step-up verification of new devices.
+CREATE TABLE IF NOT EXISTS user_devices (
+    id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_id_hash TEXT NOT NULL,
+    name VARCHAR(100),
+    platform VARCHAR(20),
+    last_ip_address INET,
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (user_id, device_id_hash)
+);
+
+-- Sessions remember the device they were created on
+ALTER TABLE user_sessions
+    ADD COLUMN IF NOT EXISTS device_id uuid REFERENCES user_devices(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS device_name VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS platform VARCHAR(20);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_user_sessions_device_id ON user_sessions(device_id);
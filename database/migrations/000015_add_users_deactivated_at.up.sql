@@ -0,0 +1,10 @@
+-- Set while support staff keep an account deactivated. Verifying an email address or
+-- phone number only activates accounts without it, so a deactivated user cannot turn
+-- their account back on.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP WITH TIME ZONE;
+
+-- Verification activates an account, so inactive accounts that were verified before were
+-- deactivated by support staff
+UPDATE users
+SET deactivated_at = updated_at
+WHERE is_active = FALSE AND (is_verified = TRUE OR is_phone_verified = TRUE);
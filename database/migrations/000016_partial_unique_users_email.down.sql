@@ -0,0 +1,4 @@
+-- Fails while a deleted user shares an email address with another user
+DROP INDEX IF EXISTS idx_users_email;
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
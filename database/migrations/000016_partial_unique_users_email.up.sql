@@ -0,0 +1,5 @@
+-- Email addresses are unique among non-deleted users, like phone numbers, so the address
+-- of a deleted user can be registered again
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email) WHERE deleted_at IS NULL;
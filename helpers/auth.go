@@ -0,0 +1,142 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+type principalContextKey struct{}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Permissions []string
+	UserID      uuid.UUID
+	SessionID   uuid.UUID
+	Verified    bool
+}
+
+// HasPermission reports whether the principal was granted permission.
+func (p *Principal) HasPermission(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
+}
+
+// SessionValidator checks that the session behind a verified access token is still usable,
+// e.g. that it has not been revoked.
+type SessionValidator func(ctx context.Context, accessToken string, principal *Principal) error
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored by the Authenticate middleware.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// Authenticate returns a middleware that requires a valid "Authorization: Bearer" access token.
+// It verifies the token signature and expiry, runs validateSession when it is not nil and
+// stores the resulting Principal in the request context. Failures get a 401 ErrorResponse.
+func Authenticate(validateSession SessionValidator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := BearerToken(r)
+			if token == "" {
+				sendUnauthorized(w, r, "Missing bearer token", errors.New("missing bearer token"))
+				return
+			}
+
+			claims, err := ParseAccessToken(token)
+			if err != nil {
+				sendUnauthorized(w, r, "Invalid access token", err)
+				return
+			}
+
+			principal, err := principalFromClaims(claims)
+			if err != nil {
+				sendUnauthorized(w, r, "Invalid access token", err)
+				return
+			}
+
+			if validateSession != nil {
+				if err := validateSession(r.Context(), token, principal); err != nil {
+					sendUnauthorized(w, r, "Invalid access token", err)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func principalFromClaims(claims *AccessTokenClaims) (*Principal, error) {
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token subject: %w", err)
+	}
+
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token session: %w", err)
+	}
+
+	return &Principal{
+		UserID:      userID,
+		SessionID:   sessionID,
+		Permissions: claims.Permissions,
+		Verified:    claims.Verified,
+	}, nil
+}
+
+func sendUnauthorized(w http.ResponseWriter, r *http.Request, message string, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="ewallet-ums"`)
+	SendErrorResponse(w, r, message, err, http.StatusUnauthorized)
+}
+
+// RequirePermission returns a middleware that only lets through principals granted permission.
+// It must run after Authenticate; requests without a principal get a 401, others a 403.
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				sendUnauthorized(w, r, "Unauthorized", errors.New("missing principal"))
+				return
+			}
+
+			if !principal.HasPermission(permission) {
+				SendErrorResponse(w, r, "Forbidden", fmt.Errorf("missing permission %s", permission), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireVerified returns a middleware that rejects principals whose account is not verified,
+// meaning neither their email address nor their phone number is verified, with a 403.
+// Wallet-sensitive operations must run behind it. It must run after Authenticate.
+func RequireVerified(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			sendUnauthorized(w, r, "Unauthorized", errors.New("missing principal"))
+			return
+		}
+
+		if !principal.Verified {
+			SendErrorResponse(w, r, "Account not verified", errors.New("account not verified"), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
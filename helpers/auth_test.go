@@ -0,0 +1,147 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestAuthenticate(t *testing.T) {
+	userID, sessionID := uuid.New(), uuid.New()
+	validToken, err := GenerateAccessToken(AccessTokenParams{
+		UserID:      userID,
+		SessionID:   sessionID,
+		Permissions: []string{"users:read"},
+		Verified:    true,
+		ExpiresAt:   time.Now().Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	expiredToken, err := GenerateAccessToken(AccessTokenParams{
+		UserID:    userID,
+		SessionID: sessionID,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	rejectSession := func(context.Context, string, *Principal) error { return errors.New("session revoked") }
+
+	tests := []struct {
+		validator  SessionValidator
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{name: "valid token", header: "Bearer " + validToken, wantStatus: http.StatusOK},
+		{name: "missing header", wantStatus: http.StatusUnauthorized},
+		{name: "wrong scheme", header: "Basic " + validToken, wantStatus: http.StatusUnauthorized},
+		{name: "malformed token", header: "Bearer not-a-jwt", wantStatus: http.StatusUnauthorized},
+		{name: "expired token", header: "Bearer " + expiredToken, wantStatus: http.StatusUnauthorized},
+		{name: "revoked session", header: "Bearer " + validToken, validator: rejectSession, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var got *Principal
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = PrincipalFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/protected", http.NoBody)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			Authenticate(tt.validator)(next).ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantStatus == http.StatusOK && (got == nil || got.UserID != userID || got.SessionID != sessionID) {
+				t.Errorf("Expected principal for user %s and session %s, got %+v", userID, sessionID, got)
+			}
+			if tt.wantStatus == http.StatusOK && (!got.HasPermission("users:read") || !got.Verified) {
+				t.Errorf("Expected principal to carry token permissions and verification, got %+v", got)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected WWW-Authenticate header on 401")
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		principal  *Principal
+		name       string
+		wantStatus int
+	}{
+		{name: "granted", principal: &Principal{Permissions: []string{"users:read", "users:delete"}}, wantStatus: http.StatusOK},
+		{name: "not granted", principal: &Principal{Permissions: []string{"users:read"}}, wantStatus: http.StatusForbidden},
+		{name: "missing principal", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+			req := httptest.NewRequest(http.MethodGet, "/admin", http.NoBody)
+			if tt.principal != nil {
+				req = req.WithContext(WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			RequirePermission("users:delete")(next).ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequireVerified(t *testing.T) {
+	tests := []struct {
+		principal  *Principal
+		name       string
+		wantStatus int
+	}{
+		{name: "verified", principal: &Principal{Verified: true}, wantStatus: http.StatusOK},
+		{name: "unverified", principal: &Principal{}, wantStatus: http.StatusForbidden},
+		{name: "missing principal", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+			req := httptest.NewRequest(http.MethodPost, "/sensitive", http.NoBody)
+			if tt.principal != nil {
+				req = req.WithContext(WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			RequireVerified(next).ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package helpers
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // SHA-1 is the format of breached password lists, not used for security
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// breachedPasswords is a list of SHA-1 digests of breached passwords searched on disk, so
+// lists of any size, such as the roughly 900 million digests of Have I Been Pwned, need no
+// memory. The file holds one digest in hex per line, optionally followed by ":<count>",
+// sorted by digest as in the "ordered by hash" Have I Been Pwned download.
+type breachedPasswords struct {
+	file *os.File
+	path string
+	size int64
+}
+
+// openBreachedPasswords opens a breached password list and checks that its first line is
+// a SHA-1 digest. Sorting cannot be checked without reading the whole file.
+func openBreachedPasswords(path string) (*breachedPasswords, error) {
+	f, err := os.Open(path) //nolint:gosec // path comes from trusted configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list %s: %w", path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to read breached password list %s: %w", path, err)
+	}
+
+	list := &breachedPasswords{file: f, path: path, size: info.Size()}
+	if list.size > 0 {
+		_, line, err := list.lineFrom(0)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		if _, ok := parseBreachedDigest(line); !ok {
+			_ = f.Close()
+			return nil, fmt.Errorf("breached password list %s does not start with a SHA-1 digest", path)
+		}
+	}
+
+	return list, nil
+}
+
+// Contains reports whether the SHA-1 digest of password is listed. It binary searches the
+// byte offsets of the file, reading about 40 lines for a list of a billion digests.
+func (l *breachedPasswords) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec // see import
+	target := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	// Every line starting before lo sorts before target and every line starting at or
+	// after hi sorts after it
+	lo, hi := int64(0), l.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, line, err := l.lineFrom(mid)
+		if err != nil {
+			return false, err
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+
+		digest, _ := parseBreachedDigest(line)
+		switch strings.Compare(digest, target) {
+		case 0:
+			return true, nil
+		case -1:
+			lo = start + int64(len(line))
+		default:
+			hi = mid
+		}
+	}
+
+	return false, nil
+}
+
+// lineFrom returns the first line starting at or after offset, including its line break,
+// and where it starts. At the end of the file the line is empty and starts at the size.
+func (l *breachedPasswords) lineFrom(offset int64) (int64, string, error) {
+	start := offset
+	if offset > 0 {
+		// Start one byte early to tell whether offset is the start of a line
+		start = offset - 1
+	}
+	reader := bufio.NewReaderSize(io.NewSectionReader(l.file, start, l.size-start), 128)
+
+	if offset > 0 {
+		skipped, err := reader.ReadString('\n')
+		start += int64(len(skipped))
+		if errors.Is(err, io.EOF) {
+			return l.size, "", nil
+		}
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to read breached password list %s: %w", l.path, err)
+		}
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, "", fmt.Errorf("failed to read breached password list %s: %w", l.path, err)
+	}
+	return start, line, nil
+}
+
+// parseBreachedDigest returns the upper case hex digest of a list line and whether it is
+// a SHA-1 digest.
+func parseBreachedDigest(line string) (string, bool) {
+	digest, _, _ := strings.Cut(strings.TrimRight(line, "\r\n"), ":")
+	digest = strings.ToUpper(digest)
+	if len(digest) != 2*sha1.Size {
+		return digest, false
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return digest, false
+	}
+	return digest, true
+}
@@ -0,0 +1,105 @@
+package helpers
+
+import (
+	"crypto/sha1" //nolint:gosec // breached password lists use SHA-1
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeBreachedList writes the sorted digests of passwords in the given case, each line
+// followed by a count and lineEnd, and opens the list.
+func writeBreachedList(t *testing.T, passwords []string, upper bool, lineEnd string) *breachedPasswords {
+	t.Helper()
+
+	digests := make([]string, 0, len(passwords))
+	for _, password := range passwords {
+		sum := sha1.Sum([]byte(password)) //nolint:gosec // see import
+		digest := hex.EncodeToString(sum[:])
+		if upper {
+			digest = strings.ToUpper(digest)
+		}
+		digests = append(digests, digest)
+	}
+	slices.Sort(digests)
+
+	var b strings.Builder
+	for i, digest := range digests {
+		fmt.Fprintf(&b, "%s:%d%s", digest, i*37+1, lineEnd)
+	}
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		t.Fatalf("Failed to write breached list: %v", err)
+	}
+
+	list, err := openBreachedPasswords(path)
+	if err != nil {
+		t.Fatalf("Failed to open breached list: %v", err)
+	}
+	t.Cleanup(func() { _ = list.file.Close() })
+	return list
+}
+
+func TestBreachedPasswords_Contains(t *testing.T) {
+	t.Parallel()
+
+	listed := make([]string, 0, 500)
+	for i := range 500 {
+		listed = append(listed, fmt.Sprintf("listed-%d", i))
+	}
+
+	tests := []struct {
+		name    string
+		lineEnd string
+		upper   bool
+	}{
+		{name: "Have I Been Pwned format", upper: true, lineEnd: "\r\n"},
+		{name: "lower case sha1sum output", upper: false, lineEnd: "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			list := writeBreachedList(t, listed, tt.upper, tt.lineEnd)
+
+			for i := range 500 {
+				// Act
+				found, err := list.Contains(fmt.Sprintf("listed-%d", i))
+				missing, missingErr := list.Contains(fmt.Sprintf("unlisted-%d", i))
+
+				// Assert
+				if err != nil || missingErr != nil {
+					t.Fatalf("Expected no error, got %v and %v", err, missingErr)
+				}
+				if !found {
+					t.Errorf("Expected listed-%d to be found", i)
+				}
+				if missing {
+					t.Errorf("Expected unlisted-%d not to be found", i)
+				}
+			}
+		})
+	}
+}
+
+func TestBreachedPasswords_SingleEntryWithoutLineBreak(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	list := writeBreachedList(t, []string{"only"}, true, "")
+
+	// Act
+	found, err := list.Contains("only")
+
+	// Assert
+	if err != nil || !found {
+		t.Errorf("Expected the only entry to be found, got %v, %v", found, err)
+	}
+}
@@ -3,7 +3,9 @@ package helpers
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -82,3 +84,54 @@ func GetRequiredEnv(key string) (string, error) {
 	}
 	return val, nil
 }
+
+// GetEnvDuration retrieves a duration environment variable (e.g. "15m") or returns default value.
+func GetEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	val := GetEnv(key, "")
+	if val == "" {
+		return defaultVal
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		if Logger != nil {
+			Logger.Warnf("Invalid duration for %s: %q, using default %s", key, val, defaultVal)
+		}
+		return defaultVal
+	}
+	return d
+}
+
+// GetEnvInt retrieves an integer environment variable or returns default value.
+func GetEnvInt(key string, defaultVal int) int {
+	val := GetEnv(key, "")
+	if val == "" {
+		return defaultVal
+	}
+
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		if Logger != nil {
+			Logger.Warnf("Invalid integer for %s: %q, using default %d", key, val, defaultVal)
+		}
+		return defaultVal
+	}
+	return n
+}
+
+// GetEnvBool retrieves a boolean environment variable (e.g. "true", "1") or returns default value.
+func GetEnvBool(key string, defaultVal bool) bool {
+	val := GetEnv(key, "")
+	if val == "" {
+		return defaultVal
+	}
+
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		if Logger != nil {
+			Logger.Warnf("Invalid boolean for %s: %q, using default %t", key, val, defaultVal)
+		}
+		return defaultVal
+	}
+	return b
+}
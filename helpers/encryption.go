@@ -0,0 +1,127 @@
+package helpers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const encryptionKeySize = 32
+
+var (
+	// encryptionSetupMu serializes the lazy key setup so concurrent first requests share one key
+	encryptionSetupMu sync.Mutex
+	encryptionMu      sync.RWMutex
+	encryptionKey     []byte
+)
+
+// SetupEncryptionKey loads the AES-256 key used to encrypt secrets at rest, such as TOTP
+// secrets, from DATA_ENCRYPTION_KEY (32 bytes, base64 encoded). Outside production an
+// ephemeral key is generated when none is configured.
+func SetupEncryptionKey() error {
+	var key []byte
+	if encoded := GetEnv("DATA_ENCRYPTION_KEY", ""); encoded != "" {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(decoded) != encryptionKeySize {
+			return fmt.Errorf("DATA_ENCRYPTION_KEY must be %d bytes, base64 encoded", encryptionKeySize)
+		}
+		key = decoded
+	} else {
+		if GetEnv("ENVIRONMENT", "development") == "production" {
+			return errors.New("required environment variable DATA_ENCRYPTION_KEY is not set")
+		}
+
+		if Logger != nil {
+			Logger.Warn("DATA_ENCRYPTION_KEY not set, using an ephemeral key; encrypted secrets will not survive restarts")
+		}
+		key = make([]byte, encryptionKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return fmt.Errorf("failed to generate encryption key: %w", err)
+		}
+	}
+
+	encryptionMu.Lock()
+	encryptionKey = key
+	encryptionMu.Unlock()
+	return nil
+}
+
+// dataCipher returns an AES-GCM cipher for the encryption key, loading the key on first use.
+func dataCipher() (cipher.AEAD, error) {
+	key, err := currentEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func currentEncryptionKey() ([]byte, error) {
+	if key := loadedEncryptionKey(); key != nil {
+		return key, nil
+	}
+
+	encryptionSetupMu.Lock()
+	defer encryptionSetupMu.Unlock()
+
+	// Another request may have loaded the key while this one waited
+	if key := loadedEncryptionKey(); key != nil {
+		return key, nil
+	}
+	if err := SetupEncryptionKey(); err != nil {
+		return nil, err
+	}
+	return loadedEncryptionKey(), nil
+}
+
+func loadedEncryptionKey() []byte {
+	encryptionMu.RLock()
+	defer encryptionMu.RUnlock()
+	return encryptionKey
+}
+
+// EncryptSecret encrypts plaintext with AES-256-GCM and returns the nonce and ciphertext,
+// base64 encoded. associatedData, such as the owning user ID, is authenticated but not
+// stored, so a ciphertext copied to another row fails to decrypt.
+func EncryptSecret(plaintext string, associatedData []byte) (string, error) {
+	aead, err := dataCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), associatedData)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret decrypts a value produced by EncryptSecret with the same associatedData.
+func DecryptSecret(ciphertext string, associatedData []byte) (string, error) {
+	aead, err := dataCipher()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("failed to decrypt secret: malformed ciphertext")
+	}
+
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, associatedData)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestEncryptSecret(t *testing.T) {
+	// Arrange
+	t.Setenv("DATA_ENCRYPTION_KEY", "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE=")
+	if err := SetupEncryptionKey(); err != nil {
+		t.Fatalf("SetupEncryptionKey returned error: %v", err)
+	}
+
+	// Act
+	ciphertext, err := EncryptSecret("JBSWY3DPEHPK3PXP", []byte("user-1"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("EncryptSecret returned error: %v", err)
+	}
+	if plaintext, err := DecryptSecret(ciphertext, []byte("user-1")); err != nil || plaintext != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("Expected the secret back, got %q, %v", plaintext, err)
+	}
+	if _, err := DecryptSecret(ciphertext, []byte("user-2")); err == nil {
+		t.Error("Expected decryption with other associated data to fail")
+	}
+	if again, _ := EncryptSecret("JBSWY3DPEHPK3PXP", []byte("user-1")); again == ciphertext {
+		t.Error("Expected a fresh nonce for every encryption")
+	}
+}
+
+func TestSetupEncryptionKey_Invalid(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		name string
+	}{
+		{name: "wrong length", env: map[string]string{"DATA_ENCRYPTION_KEY": "c2hvcnQ="}},
+		{name: "not base64", env: map[string]string{"DATA_ENCRYPTION_KEY": "not base64!"}},
+		{name: "missing in production", env: map[string]string{"DATA_ENCRYPTION_KEY": "", "ENVIRONMENT": "production"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			// Act
+			err := SetupEncryptionKey()
+
+			// Assert
+			if err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestDataCipher_ConcurrentFirstUse(t *testing.T) {
+	// Arrange
+	t.Setenv("DATA_ENCRYPTION_KEY", "")
+	encryptionMu.Lock()
+	encryptionKey = nil
+	encryptionMu.Unlock()
+	t.Cleanup(func() {
+		encryptionMu.Lock()
+		encryptionKey = nil
+		encryptionMu.Unlock()
+	})
+
+	// Act
+	ciphertexts := make([]string, 16)
+	var wg sync.WaitGroup
+	for i := range ciphertexts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ciphertexts[i], _ = EncryptSecret("JBSWY3DPEHPK3PXP", []byte("user-1"))
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	for _, ciphertext := range ciphertexts {
+		if plaintext, err := DecryptSecret(ciphertext, []byte("user-1")); err != nil || plaintext != "JBSWY3DPEHPK3PXP" {
+			t.Fatal("Expected every request to use the same ephemeral encryption key")
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package helpers
+
+import (
+	"errors"
+	"net/http"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+)
+
+// ErrorStatus returns the HTTP status for an error: 429 for rate limited requests, the status
+// of its domain kind, or 500 for errors of no kind.
+func ErrorStatus(err error) int {
+	var retryErr *domain.RetryAfterError
+	switch {
+	case errors.As(err, &retryErr):
+		return http.StatusTooManyRequests
+	case errors.Is(err, domain.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrLocked):
+		return http.StatusLocked
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// MapError turns an error into its HTTP status and error response. Errors of a domain kind
+// are described by their own message, so message only describes errors of no kind, which
+// are answered with 500 Internal Server Error.
+func MapError(r *http.Request, message string, err error) (int, ErrorResponse) {
+	status := ErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		return status, newErrorResponse(r, message, err)
+	}
+
+	var validationErr *ValidationError
+	var domainErr *domain.Error
+	switch {
+	case status == http.StatusTooManyRequests:
+		message = "Too many requests, try again later"
+	case errors.As(err, &validationErr):
+		message = "Validation failed"
+	case errors.As(err, &domainErr):
+		message = upperFirst(domainErr.Error())
+	default:
+		message = http.StatusText(status)
+	}
+
+	return status, newErrorResponse(r, message, err)
+}
+
+// SendError sends the error response MapError builds for err, with a Retry-After header for
+// rate limited requests. message describes errors of no kind, e.g. "Failed to update profile".
+func SendError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	var retryErr *domain.RetryAfterError
+	if errors.As(err, &retryErr) {
+		SendTooManyRequests(w, r, retryErr.RetryAfter, err)
+		return
+	}
+
+	status, resp := MapError(r, message, err)
+	writeErrorResponse(w, r, resp, err, status)
+}
+
+// newErrorResponse builds the error response for err, listing the invalid fields of
+// validation errors. The text of err is only included for errors of a domain kind and rate
+// limited requests; any other error may carry internal details such as SQL errors, so
+// clients only get message and the request ID to find the logged error.
+func newErrorResponse(r *http.Request, message string, err error) ErrorResponse {
+	resp := ErrorResponse{
+		Success:   false,
+		Message:   message,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+	if err == nil || ErrorStatus(err) == http.StatusInternalServerError {
+		return resp
+	}
+
+	resp.Error = err.Error()
+	// Expose field-level details for validation failures
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		resp.Errors = validationErr.Fields
+	}
+	return resp
+}
+
+// upperFirst returns s with its first letter in upper case.
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
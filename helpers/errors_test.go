@@ -0,0 +1,141 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+)
+
+func TestSendError(t *testing.T) {
+	tests := []struct {
+		err            error
+		name           string
+		wantMessage    string
+		wantRetryAfter string
+		wantStatus     int
+		wantFields     int
+		hideError      bool
+	}{
+		{
+			name:        "validation error",
+			err:         &ValidationError{Fields: []FieldError{{Field: "email", Message: "is required"}}},
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "Validation failed",
+			wantFields:  1,
+		},
+		{
+			name:        "wrapped not found error",
+			err:         fmt.Errorf("failed to get user: %w", domain.NewError(domain.ErrNotFound, "user not found")),
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "User not found",
+		},
+		{
+			name:        "conflict",
+			err:         domain.NewError(domain.ErrConflict, "email already registered"),
+			wantStatus:  http.StatusConflict,
+			wantMessage: "Email already registered",
+		},
+		{
+			name:        "unauthorized",
+			err:         domain.NewError(domain.ErrUnauthorized, "invalid credentials"),
+			wantStatus:  http.StatusUnauthorized,
+			wantMessage: "Invalid credentials",
+		},
+		{
+			name:        "forbidden",
+			err:         domain.NewError(domain.ErrForbidden, "user account is not active"),
+			wantStatus:  http.StatusForbidden,
+			wantMessage: "User account is not active",
+		},
+		{
+			name:        "locked",
+			err:         domain.NewError(domain.ErrLocked, "account locked"),
+			wantStatus:  http.StatusLocked,
+			wantMessage: "Account locked",
+		},
+		{
+			name:        "bare kind",
+			err:         fmt.Errorf("lookup failed: %w", domain.ErrNotFound),
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "Not Found",
+		},
+		{
+			name:           "rate limited",
+			err:            &domain.RetryAfterError{Err: errors.New("too many requests"), RetryAfter: 1500 * time.Millisecond},
+			wantStatus:     http.StatusTooManyRequests,
+			wantMessage:    "Too many requests, try again later",
+			wantRetryAfter: "2",
+		},
+		{
+			name:        "error of no kind",
+			err:         errors.New("connection refused"),
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "Failed to update profile",
+			hideError:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", nil)
+			w := httptest.NewRecorder()
+
+			SendError(w, req, "Failed to update profile", tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tt.wantRetryAfter, got)
+			}
+
+			var resp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			wantError := tt.err.Error()
+			if tt.hideError {
+				wantError = ""
+			}
+			if resp.Success || resp.Message != tt.wantMessage || resp.Error != wantError {
+				t.Errorf("Unexpected response %+v", resp)
+			}
+			if len(resp.Errors) != tt.wantFields {
+				t.Errorf("Expected %d field errors, got %v", tt.wantFields, resp.Errors)
+			}
+		})
+	}
+}
+
+func TestSendError_InternalErrorDetails(t *testing.T) {
+	// Arrange
+	err := fmt.Errorf("failed to update user: %w", errors.New(`pq: relation "users" does not exist`))
+	req := httptest.NewRequest(http.MethodPut, "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.RequestIDKey, "req-123"))
+	w := httptest.NewRecorder()
+
+	// Act
+	SendError(w, req, "Failed to update profile", err)
+
+	// Assert
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	body := w.Body.String()
+	if strings.Contains(body, "pq:") || strings.Contains(body, "failed to update user") {
+		t.Errorf("Expected the wrapped error to stay out of the response, got %s", body)
+	}
+	if !strings.Contains(body, "Failed to update profile") || !strings.Contains(body, "req-123") {
+		t.Errorf("Expected the generic message and the request ID, got %s", body)
+	}
+}
@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"math"
+	"net/netip"
+	"testing"
+)
+
+func TestDistanceKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{name: "same point", lat1: -6.2, lon1: 106.8, lat2: -6.2, lon2: 106.8, want: 0},
+		{name: "Jakarta to Singapore", lat1: -6.2088, lon1: 106.8456, lat2: 1.3521, lon2: 103.8198, want: 900},
+		{name: "Jakarta to London", lat1: -6.2088, lon1: 106.8456, lat2: 51.5074, lon2: -0.1278, want: 11710},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DistanceKm(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if math.Abs(got-tt.want) > tt.want*0.02+1 {
+				t.Errorf("Expected about %.0f km, got %.0f km", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseIPPrefixes(t *testing.T) {
+	prefixes, err := ParseIPPrefixes(" 203.0.113.0/24, 198.51.100.7,2001:db8::/32,, ")
+	if err != nil {
+		t.Fatalf("ParseIPPrefixes returned error: %v", err)
+	}
+	if len(prefixes) != 3 {
+		t.Fatalf("Expected 3 prefixes, got %v", prefixes)
+	}
+
+	for _, ip := range []string{"203.0.113.200", "198.51.100.7", "2001:db8::1"} {
+		addr := netip.MustParseAddr(ip)
+		matched := false
+		for _, prefix := range prefixes {
+			matched = matched || prefix.Contains(addr)
+		}
+		if !matched {
+			t.Errorf("Expected %s to be in a range", ip)
+		}
+	}
+	if prefixes[1].Contains(netip.MustParseAddr("198.51.100.8")) {
+		t.Error("Expected a single address to only match itself")
+	}
+
+	if _, err := ParseIPPrefixes("203.0.113.0/33"); err == nil {
+		t.Error("Expected an invalid range to be rejected")
+	}
+}
+
+func TestParseIP(t *testing.T) {
+	for _, value := range []string{"203.0.113.7", "203.0.113.7/32", "::ffff:203.0.113.7"} {
+		addr, ok := ParseIP(value)
+		if !ok || addr != netip.MustParseAddr("203.0.113.7") {
+			t.Errorf("ParseIP(%q) = %s, %v", value, addr, ok)
+		}
+	}
+	if _, ok := ParseIP("not-an-ip"); ok {
+		t.Error("Expected an invalid address to be rejected")
+	}
+}
@@ -51,3 +51,12 @@ func LoggerMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(ww, r)
 	})
 }
+
+// LogSecurityEvent logs a security relevant event with a stable shape so it can be alerted on.
+func LogSecurityEvent(event string, fields logrus.Fields) {
+	if Logger == nil {
+		return
+	}
+
+	Logger.WithFields(fields).WithField("security_event", event).Warn("Security event")
+}
@@ -0,0 +1,171 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+)
+
+const (
+	argon2idPrefix = "$argon2id$"
+	argon2SaltSize = 16
+	argon2KeySize  = 32
+
+	// argon2MinMemoryPerLane is the smallest memory cost in KiB argon2 accepts per lane.
+	argon2MinMemoryPerLane = 8
+)
+
+var errMalformedPasswordHash = errors.New("malformed password hash")
+
+var (
+	hashParamsMu sync.RWMutex
+	hashParams   *PasswordHashParams
+)
+
+// PasswordHashParams are the argon2id cost parameters used for new password hashes.
+type PasswordHashParams struct {
+	Memory      uint32 // KiB
+	Time        uint32
+	Parallelism uint8
+}
+
+// SetupPasswordHashing loads the argon2id cost parameters from PASSWORD_HASH_MEMORY (KiB),
+// PASSWORD_HASH_TIME and PASSWORD_HASH_PARALLELISM. Existing hashes created with other
+// parameters keep verifying and are upgraded on the next successful login.
+func SetupPasswordHashing() error {
+	memory := GetEnvInt("PASSWORD_HASH_MEMORY", constants.DefaultPasswordHashMemory)
+	timeCost := GetEnvInt("PASSWORD_HASH_TIME", constants.DefaultPasswordHashTime)
+	parallelism := GetEnvInt("PASSWORD_HASH_PARALLELISM", constants.DefaultPasswordHashParallelism)
+	if timeCost < 1 || int64(timeCost) > math.MaxUint32 || parallelism < 1 || parallelism > math.MaxUint8 ||
+		memory < argon2MinMemoryPerLane*parallelism || int64(memory) > math.MaxUint32 {
+		return fmt.Errorf("invalid password hash parameters: memory %d KiB, time %d, parallelism %d", memory, timeCost, parallelism)
+	}
+
+	params := &PasswordHashParams{
+		Memory:      uint32(memory),     //nolint:gosec // range checked above
+		Time:        uint32(timeCost),   //nolint:gosec // range checked above
+		Parallelism: uint8(parallelism), //nolint:gosec // range checked above
+	}
+
+	hashParamsMu.Lock()
+	hashParams = params
+	hashParamsMu.Unlock()
+
+	if Logger != nil {
+		Logger.Infof("Password hashing: argon2id m=%d,t=%d,p=%d", params.Memory, params.Time, params.Parallelism)
+	}
+	return nil
+}
+
+// currentHashParams returns the password hash parameters, loading them on first use.
+func currentHashParams() (*PasswordHashParams, error) {
+	hashParamsMu.RLock()
+	params := hashParams
+	hashParamsMu.RUnlock()
+
+	if params != nil {
+		return params, nil
+	}
+
+	if err := SetupPasswordHashing(); err != nil {
+		return nil, err
+	}
+
+	hashParamsMu.RLock()
+	defer hashParamsMu.RUnlock()
+	return hashParams, nil
+}
+
+// HashPassword hashes a plain text password with argon2id for storage in password_hash.
+// The result is a PHC string such as $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>.
+func HashPassword(password string) (string, error) {
+	params, err := currentHashParams()
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	salt := make([]byte, argon2SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Parallelism, argon2KeySize)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		params.Memory, params.Time, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// CheckPassword reports whether password matches the stored hash. Both argon2id PHC
+// strings and legacy bcrypt hashes are accepted.
+func CheckPassword(hash, password string) bool {
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+
+	params, salt, key, err := parseArgon2idHash(hash)
+	if err != nil {
+		return false
+	}
+
+	//nolint:gosec // key length comes from our own encoding and is far below uint32 range
+	candidate := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(candidate, key) == 1
+}
+
+// NeedsRehash reports whether a stored hash was not created with argon2id and the
+// current cost parameters, so it should be replaced after the next successful login.
+func NeedsRehash(hash string) bool {
+	current, err := currentHashParams()
+	if err != nil {
+		return false
+	}
+
+	params, _, key, err := parseArgon2idHash(hash)
+	if err != nil {
+		return true
+	}
+	return *params != *current || len(key) != argon2KeySize
+}
+
+// parseArgon2idHash splits an argon2id PHC string into its parameters, salt and key.
+func parseArgon2idHash(hash string) (params *PasswordHashParams, salt, key []byte, err error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, errMalformedPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, errMalformedPasswordHash
+	}
+
+	params = &PasswordHashParams{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Parallelism); err != nil {
+		return nil, nil, nil, errMalformedPasswordHash
+	}
+	if params.Time < 1 || params.Parallelism < 1 {
+		return nil, nil, nil, errMalformedPasswordHash
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, errMalformedPasswordHash
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, errMalformedPasswordHash
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,175 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+)
+
+// minPersonalInfoLength is the shortest email or name part that passwords may not contain,
+// so that short names do not rule out common words.
+const minPersonalInfoLength = 3
+
+var (
+	policyMu       sync.RWMutex
+	passwordPolicy *PasswordPolicy
+)
+
+// PasswordPolicy holds the rules new passwords must follow.
+type PasswordPolicy struct {
+	// breached lists known breached passwords; nil disables the check
+	breached      *breachedPasswords
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// SetupPasswordPolicy loads the password policy from the environment:
+// PASSWORD_MIN_LENGTH, PASSWORD_REQUIRE_UPPERCASE, PASSWORD_REQUIRE_LOWERCASE,
+// PASSWORD_REQUIRE_DIGIT, PASSWORD_REQUIRE_SYMBOL and PASSWORD_BREACHED_LIST_FILE.
+func SetupPasswordPolicy() error {
+	policy := &PasswordPolicy{
+		MinLength:     GetEnvInt("PASSWORD_MIN_LENGTH", constants.DefaultPasswordMinLength),
+		RequireUpper:  GetEnvBool("PASSWORD_REQUIRE_UPPERCASE", false),
+		RequireLower:  GetEnvBool("PASSWORD_REQUIRE_LOWERCASE", false),
+		RequireDigit:  GetEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+		RequireSymbol: GetEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+	}
+
+	if path := GetEnv("PASSWORD_BREACHED_LIST_FILE", ""); path != "" {
+		breached, err := openBreachedPasswords(path)
+		if err != nil {
+			return err
+		}
+		policy.breached = breached
+	}
+
+	policyMu.Lock()
+	passwordPolicy = policy
+	policyMu.Unlock()
+
+	if Logger != nil {
+		Logger.Infof("Password policy loaded: min length %d, breached password check %t", policy.MinLength, policy.breached != nil)
+	}
+	return nil
+}
+
+// currentPasswordPolicy returns the password policy, loading it on first use.
+func currentPasswordPolicy() (*PasswordPolicy, error) {
+	policyMu.RLock()
+	policy := passwordPolicy
+	policyMu.RUnlock()
+
+	if policy != nil {
+		return policy, nil
+	}
+
+	if err := SetupPasswordPolicy(); err != nil {
+		return nil, err
+	}
+
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return passwordPolicy, nil
+}
+
+// ValidatePassword checks a new password against the password policy. Violations are
+// returned as a *ValidationError on field. personal lists the user's email and name,
+// which the password must not contain.
+func ValidatePassword(field, password string, personal ...string) error {
+	policy, err := currentPasswordPolicy()
+	if err != nil {
+		return err
+	}
+
+	violations := policy.Violations(password, personal...)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	fields := make([]FieldError, 0, len(violations))
+	for _, message := range violations {
+		fields = append(fields, FieldError{Field: field, Message: message})
+	}
+	return &ValidationError{Fields: fields}
+}
+
+// Violations returns a message for every rule the password breaks.
+func (p *PasswordPolicy) Violations(password string, personal ...string) []string {
+	var violations []string
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, "must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, "must contain a symbol")
+	}
+
+	if containsPersonalInfo(password, personal) {
+		violations = append(violations, "must not contain your email or name")
+	}
+
+	if p.breached != nil {
+		// A list that cannot be read must not stop users from setting passwords
+		breached, err := p.breached.Contains(password)
+		if err != nil && Logger != nil {
+			Logger.Errorf("Failed to check breached passwords: %v", err)
+		}
+		if breached {
+			violations = append(violations, "has appeared in a data breach, choose a different password")
+		}
+	}
+
+	return violations
+}
+
+// containsPersonalInfo reports whether the password contains the local part of an
+// email address or a word of a name, ignoring case.
+func containsPersonalInfo(password string, personal []string) bool {
+	lower := strings.ToLower(password)
+	for _, value := range personal {
+		value = strings.ToLower(value)
+		if local, _, found := strings.Cut(value, "@"); found {
+			value = local
+		}
+
+		parts := strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, part := range parts {
+			if utf8.RuneCountInString(part) >= minPersonalInfoLength && strings.Contains(lower, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
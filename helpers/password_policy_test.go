@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"crypto/sha1" //nolint:gosec // breached password lists use SHA-1
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicy_Violations(t *testing.T) {
+	strict := &PasswordPolicy{MinLength: 10, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		policy   *PasswordPolicy
+		name     string
+		password string
+		personal []string
+		want     int
+	}{
+		{name: "strong password", policy: strict, password: "Correct-Horse-42", want: 0},
+		{name: "too short", policy: strict, password: "Ab1!", want: 1},
+		{name: "lowercase letters only", policy: strict, password: "correcthorse", want: 3},
+		{name: "classes not required", policy: &PasswordPolicy{MinLength: 8}, password: "correcthorse", want: 0},
+		{
+			name:     "contains email local part",
+			policy:   &PasswordPolicy{MinLength: 8},
+			password: "myJohnny2024",
+			personal: []string{"johnny@example.com"},
+			want:     1,
+		},
+		{
+			name:     "contains name word",
+			policy:   &PasswordPolicy{MinLength: 8},
+			password: "doe-secret-1",
+			personal: []string{"John Doe"},
+			want:     1,
+		},
+		{
+			name:     "short name parts are ignored",
+			policy:   &PasswordPolicy{MinLength: 8},
+			password: "always-secret",
+			personal: []string{"Al Li"},
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.Violations(tt.password, tt.personal...)
+
+			if len(got) != tt.want {
+				t.Errorf("Expected %d violations, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// usePasswordPolicy loads the password policy from the environment and restores the default afterwards.
+func usePasswordPolicy(t *testing.T, env map[string]string) {
+	t.Helper()
+
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	if err := SetupPasswordPolicy(); err != nil {
+		t.Fatalf("Failed to load password policy: %v", err)
+	}
+
+	t.Cleanup(func() {
+		policyMu.Lock()
+		passwordPolicy = nil
+		policyMu.Unlock()
+	})
+}
+
+func TestValidatePassword_BreachedList(t *testing.T) {
+	sum := sha1.Sum([]byte("P@ssw0rd2024")) //nolint:gosec // see import
+	list := strings.ToUpper(hex.EncodeToString(sum[:])) + ":52341\n"
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(list), 0o600); err != nil {
+		t.Fatalf("Failed to write breached list: %v", err)
+	}
+	usePasswordPolicy(t, map[string]string{"PASSWORD_BREACHED_LIST_FILE": path})
+
+	err := ValidatePassword("password", "P@ssw0rd2024")
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != "password" {
+		t.Fatalf("Expected a single breached password violation, got %v", err)
+	}
+	if err := ValidatePassword("password", "an unlisted passphrase"); err != nil {
+		t.Errorf("Expected unlisted password to pass, got %v", err)
+	}
+}
+
+func TestSetupPasswordPolicy_InvalidBreachedList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte("password123\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write breached list: %v", err)
+	}
+	t.Setenv("PASSWORD_BREACHED_LIST_FILE", path)
+
+	if err := SetupPasswordPolicy(); err == nil {
+		t.Error("Expected error for a list that is not SHA-1 digests, got nil")
+	}
+}
@@ -0,0 +1,102 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashPassword(t *testing.T) {
+	// Arrange
+	usePasswordHashParams(t, &PasswordHashParams{Memory: 1024, Time: 1, Parallelism: 1})
+
+	// Act
+	hash, err := HashPassword("password123")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("HashPassword returned error: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("Expected an argon2id PHC string, got %q", hash)
+	}
+	if !CheckPassword(hash, "password123") {
+		t.Error("Expected the password to match its hash")
+	}
+	if CheckPassword(hash, "password124") {
+		t.Error("Expected a different password not to match")
+	}
+	if NeedsRehash(hash) {
+		t.Error("Expected a hash with the current parameters not to need a rehash")
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	usePasswordHashParams(t, &PasswordHashParams{Memory: 1024, Time: 1, Parallelism: 1})
+	oldHash, err := HashPassword("password123")
+	if err != nil {
+		t.Fatalf("HashPassword returned error: %v", err)
+	}
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to create bcrypt hash: %v", err)
+	}
+
+	// Arrange
+	usePasswordHashParams(t, &PasswordHashParams{Memory: 2048, Time: 2, Parallelism: 1})
+
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{name: "older parameters", hash: oldHash, want: true},
+		{name: "bcrypt", hash: string(bcryptHash), want: true},
+		{name: "malformed", hash: "$argon2id$v=19$m=1024$salt$key", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := NeedsRehash(tt.hash)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("Expected NeedsRehash %v, got %v", tt.want, got)
+			}
+			if tt.name != "malformed" && !CheckPassword(tt.hash, "password123") {
+				t.Error("Expected the old hash to keep verifying")
+			}
+		})
+	}
+}
+
+func TestSetupPasswordHashing_InvalidParameters(t *testing.T) {
+	// Arrange
+	t.Setenv("PASSWORD_HASH_TIME", "0")
+
+	// Act
+	err := SetupPasswordHashing()
+
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for a zero time cost")
+	}
+}
+
+// usePasswordHashParams replaces the hash parameters for the duration of the test.
+func usePasswordHashParams(t *testing.T, params *PasswordHashParams) {
+	t.Helper()
+
+	hashParamsMu.Lock()
+	previous := hashParams
+	hashParams = params
+	hashParamsMu.Unlock()
+
+	t.Cleanup(func() {
+		hashParamsMu.Lock()
+		hashParams = previous
+		hashParamsMu.Unlock()
+	})
+}
@@ -0,0 +1,40 @@
+package helpers
+
+import "strings"
+
+// ValidatePIN checks that a new transaction PIN is not easy to guess: all one digit, a run
+// of consecutive digits such as 123456 or 654321, or part of the user's phone number.
+// Violations are returned as a *ValidationError on field. The PIN format itself is checked
+// by the request validation.
+func ValidatePIN(field, pin, phone string) error {
+	var message string
+	switch {
+	case isRepeatedDigits(pin):
+		message = "must not repeat a single digit"
+	case isDigitSequence(pin, 1) || isDigitSequence(pin, -1):
+		message = "must not be a sequence of consecutive digits"
+	case phone != "" && strings.Contains(phone, pin):
+		message = "must not be part of your phone number"
+	default:
+		return nil
+	}
+
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+func isRepeatedDigits(pin string) bool {
+	return pin != "" && strings.Count(pin, pin[:1]) == len(pin)
+}
+
+// isDigitSequence reports whether every digit of pin is the previous one plus step.
+func isDigitSequence(pin string, step int) bool {
+	if pin == "" {
+		return false
+	}
+	for i := 1; i < len(pin); i++ {
+		if int(pin[i])-int(pin[i-1]) != step {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidatePIN(t *testing.T) {
+	tests := []struct {
+		name    string
+		pin     string
+		phone   string
+		wantErr bool
+	}{
+		{name: "random PIN", pin: "582931", phone: "+6281234567890"},
+		{name: "repeated digit", pin: "000000", wantErr: true},
+		{name: "ascending sequence", pin: "123456", wantErr: true},
+		{name: "descending sequence", pin: "987654", wantErr: true},
+		{name: "part of phone number", pin: "567890", phone: "+6281234567890", wantErr: true},
+		{name: "no phone number", pin: "567890"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePIN("pin", tt.pin, tt.phone)
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != "pin" {
+				t.Errorf("Expected validation error on pin, got %v", err)
+			}
+		})
+	}
+}
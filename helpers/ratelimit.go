@@ -0,0 +1,104 @@
+package helpers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter counts hits per key, such as an IP address or phone number, in fixed
+// windows. Counters are kept in memory, so every instance enforces its own limit.
+type RateLimiter struct {
+	nextSweep time.Time
+	windows   map[string]*rateWindow
+	window    time.Duration
+	limit     int
+	mu        sync.Mutex
+}
+
+type rateWindow struct {
+	resetAt time.Time
+	hits    int
+}
+
+// NewRateLimiter creates a limiter allowing limit hits per key in each window.
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		windows: make(map[string]*rateWindow),
+		window:  window,
+		limit:   limit,
+	}
+}
+
+// Allow records a hit for key and reports whether it is within the limit.
+// When it is not, the returned duration is the time until the window resets.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = &rateWindow{resetAt: now.Add(l.window)}
+		l.windows[key] = w
+	}
+
+	if w.hits >= l.limit {
+		return false, w.resetAt.Sub(now)
+	}
+	w.hits++
+	return true, 0
+}
+
+// Exceeded reports whether key has used up its limit in the current window without
+// recording a hit, and if so the time until the window resets. Together with Allow it
+// limits events that are only known afterwards, such as failed logins.
+func (l *RateLimiter) Exceeded(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.resetAt) || w.hits < l.limit {
+		return false, 0
+	}
+	return true, w.resetAt.Sub(now)
+}
+
+// sweep drops expired windows at most once per window so idle keys do not accumulate.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+	for key, w := range l.windows {
+		if !now.Before(w.resetAt) {
+			delete(l.windows, key)
+		}
+	}
+	l.nextSweep = now.Add(l.window)
+}
+
+// RateLimitByIP is a middleware that rejects requests with 429 Too Many Requests and
+// a Retry-After header once the client IP exceeds the limiter's limit.
+func RateLimitByIP(limiter *RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, retryAfter := limiter.Allow(ClientIP(r)); !ok {
+				SendTooManyRequests(w, r, retryAfter, errors.New("ip rate limit exceeded"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SendTooManyRequests sends a 429 Too Many Requests error with a Retry-After header in whole seconds.
+func SendTooManyRequests(w http.ResponseWriter, r *http.Request, retryAfter time.Duration, err error) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	SendErrorResponse(w, r, "Too many requests, try again later", err, http.StatusTooManyRequests)
+}
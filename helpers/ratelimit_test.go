@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	limiter := NewRateLimiter(2, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.Allow("a"); !ok {
+			t.Fatalf("Expected hit %d to be allowed", i+1)
+		}
+	}
+
+	ok, retryAfter := limiter.Allow("a")
+	if ok || retryAfter <= 0 || retryAfter > time.Hour {
+		t.Errorf("Expected third hit to be rejected with a retry delay, got %v, %s", ok, retryAfter)
+	}
+	if ok, _ := limiter.Allow("b"); !ok {
+		t.Error("Expected other keys to have their own limit")
+	}
+}
+
+func TestRateLimiter_WindowResets(t *testing.T) {
+	limiter := NewRateLimiter(1, 10*time.Millisecond)
+
+	limiter.Allow("a")
+	if ok, _ := limiter.Allow("a"); ok {
+		t.Fatal("Expected second hit in the window to be rejected")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if ok, _ := limiter.Allow("a"); !ok {
+		t.Error("Expected hit in a new window to be allowed")
+	}
+}
+
+func TestRateLimiter_Exceeded(t *testing.T) {
+	limiter := NewRateLimiter(2, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if exceeded, _ := limiter.Exceeded("a"); exceeded {
+			t.Fatalf("Expected the limit not to be exceeded after %d hits", i)
+		}
+		limiter.Allow("a")
+	}
+
+	exceeded, retryAfter := limiter.Exceeded("a")
+	if !exceeded || retryAfter <= 0 || retryAfter > time.Hour {
+		t.Errorf("Expected the limit to be exceeded with a retry delay, got %v, %s", exceeded, retryAfter)
+	}
+	if exceeded, _ := limiter.Exceeded("b"); exceeded {
+		t.Error("Expected other keys to have their own limit")
+	}
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	handler := RateLimitByIP(NewRateLimiter(1, time.Minute))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		wantStatus int
+	}{
+		{name: "first request", remoteAddr: "192.0.2.1:1234", wantStatus: http.StatusOK},
+		{name: "same IP again", remoteAddr: "192.0.2.1:5678", wantStatus: http.StatusTooManyRequests},
+		{name: "other IP", remoteAddr: "192.0.2.2:1234", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantStatus == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "60" {
+				t.Errorf("Expected Retry-After 60, got %q", w.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+)
+
+// DecodeJSON decodes a JSON request body into dst, limiting the body size.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	r.Body = http.MaxBytesReader(w, r.Body, constants.MaxRequestBodySize)
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// ClientIP returns the client IP address of the request without the port.
+// It relies on middleware.RealIP to resolve proxy headers into RemoteAddr.
+// An empty string is returned when the address is not a valid IP.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if net.ParseIP(host) == nil {
+		return ""
+	}
+	return host
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header.
+// An empty string is returned when the header is missing or malformed.
+func BearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -17,10 +17,11 @@ type Response struct {
 
 // ErrorResponse represents an API error response.
 type ErrorResponse struct {
-	RequestID string `json:"request_id,omitempty"`
-	Message   string `json:"message"`
-	Error     string `json:"error,omitempty"`
-	Success   bool   `json:"success"`
+	RequestID string       `json:"request_id,omitempty"`
+	Message   string       `json:"message"`
+	Error     string       `json:"error,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	Success   bool         `json:"success"`
 }
 
 func SendResponse(w http.ResponseWriter, r *http.Request, data interface{}, message string, code int) {
@@ -42,27 +43,21 @@ func SendResponse(w http.ResponseWriter, r *http.Request, data interface{}, mess
 }
 
 func SendErrorResponse(w http.ResponseWriter, r *http.Request, message string, err error, code int) {
+	writeErrorResponse(w, r, newErrorResponse(r, message, err), err, code)
+}
+
+// writeErrorResponse logs err and writes the error response with the given status.
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, resp ErrorResponse, err error, code int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 
-	errorMsg := ""
-	if err != nil {
-		errorMsg = err.Error()
-		// Log the error if logger is available
-		if Logger != nil {
-			Logger.WithFields(map[string]interface{}{
-				"error":      err,
-				"message":    message,
-				"request_id": middleware.GetReqID(r.Context()),
-			}).Error("Error response")
-		}
-	}
-
-	resp := ErrorResponse{
-		Success:   false,
-		Message:   message,
-		Error:     errorMsg,
-		RequestID: middleware.GetReqID(r.Context()),
+	// Log the error if logger is available
+	if err != nil && Logger != nil {
+		Logger.WithFields(map[string]interface{}{
+			"error":      err,
+			"message":    resp.Message,
+			"request_id": resp.RequestID,
+		}).Error("Error response")
 	}
 
 	if encodeErr := json.NewEncoder(w).Encode(resp); encodeErr != nil {
@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ServiceKeyHeader carries the credential of internal service callers.
+const ServiceKeyHeader = "X-Service-Key"
+
+// RequireServiceKey is a middleware that only admits internal services presenting a key
+// configured in INTERNAL_SERVICE_KEYS, a comma separated list of "name:key" pairs.
+func RequireServiceKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(ServiceKeyHeader)
+		if key == "" {
+			SendErrorResponse(w, r, "Missing service credential", errors.New("missing service key"), http.StatusUnauthorized)
+			return
+		}
+
+		name, ok := matchServiceKey(key)
+		if !ok {
+			SendErrorResponse(w, r, "Invalid service credential", errors.New("invalid service key"), http.StatusUnauthorized)
+			return
+		}
+
+		if Logger != nil {
+			Logger.WithField("service", name).Debugf("Internal request %s %s", r.Method, r.URL.Path)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// matchServiceKey returns the name of the service owning key.
+func matchServiceKey(key string) (string, bool) {
+	for _, entry := range strings.Split(GetEnv("INTERNAL_SERVICE_KEYS", ""), ",") {
+		name, configured, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found || configured == "" {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(configured), []byte(key)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
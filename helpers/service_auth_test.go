@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireServiceKey(t *testing.T) {
+	t.Setenv("INTERNAL_SERVICE_KEYS", "wallet:wallet-key, transaction:transaction-key")
+
+	tests := []struct {
+		name       string
+		key        string
+		wantStatus int
+	}{
+		{name: "first configured key", key: "wallet-key", wantStatus: http.StatusOK},
+		{name: "second configured key", key: "transaction-key", wantStatus: http.StatusOK},
+		{name: "missing key", wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", key: "other-key", wantStatus: http.StatusUnauthorized},
+		{name: "service name is not a key", key: "wallet", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/internal/token/validate", http.NoBody)
+			if tt.key != "" {
+				req.Header.Set(ServiceKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			RequireServiceKey(next).ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
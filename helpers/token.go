@@ -0,0 +1,138 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+)
+
+const opaqueTokenBytes = 32
+
+// AccessTokenClaims are the claims carried by an access token.
+type AccessTokenClaims struct {
+	jwt.RegisteredClaims
+	SessionID   string   `json:"sid"`
+	Permissions []string `json:"permissions,omitempty"`
+	Verified    bool     `json:"verified"`
+}
+
+// AccessTokenParams describes the user session an access token is issued for.
+type AccessTokenParams struct {
+	ExpiresAt   time.Time
+	Permissions []string
+	UserID      uuid.UUID
+	SessionID   uuid.UUID
+	Verified    bool
+}
+
+// GenerateAccessToken issues a signed JWT access token for a user session
+// carrying the permissions granted to the user and whether the user is verified.
+func GenerateAccessToken(params AccessTokenParams) (string, error) {
+	key, err := currentSigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := AccessTokenClaims{
+		SessionID:   params.SessionID.String(),
+		Permissions: params.Permissions,
+		Verified:    params.Verified,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   params.UserID.String(),
+			Issuer:    GetEnv("JWT_ISSUER", constants.DefaultTokenIssuer),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(params.ExpiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+
+	signed, err := token.SignedString(key.private)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return signed, nil
+}
+
+// ParseAccessToken verifies the signature, issuer and expiry of an access token
+// against the key named by its kid header and returns its claims.
+func ParseAccessToken(tokenString string) (*AccessTokenClaims, error) {
+	claims := &AccessTokenClaims{}
+	_, err := jwt.ParseWithClaims(
+		tokenString,
+		claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			key, err := verificationKey(kid)
+			if err != nil {
+				return nil, err
+			}
+			if token.Method.Alg() != key.method.Alg() {
+				return nil, fmt.Errorf("unexpected signing method %s for key %s", token.Method.Alg(), kid)
+			}
+			return key.public, nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(GetEnv("JWT_ISSUER", constants.DefaultTokenIssuer)),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %w", err)
+	}
+
+	if claims.Subject == "" || claims.SessionID == "" {
+		return nil, errors.New("invalid access token: missing subject or session")
+	}
+	return claims, nil
+}
+
+// GenerateRefreshToken returns a random opaque refresh token.
+func GenerateRefreshToken() (string, error) {
+	return GenerateOpaqueToken()
+}
+
+// GenerateOpaqueToken returns a random URL-safe token with 256 bits of entropy,
+// used for refresh tokens and single-use tokens sent to users.
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateNumericCode returns a random code of the given number of decimal digits,
+// such as a one-time password sent by SMS.
+func GenerateNumericCode(digits int) (string, error) {
+	var b strings.Builder
+	for i := 0; i < digits; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate code: %w", err)
+		}
+		b.WriteByte(byte('0' + n.Int64()))
+	}
+	return b.String(), nil
+}
+
+// HashToken returns the SHA-256 hex digest of a token. Only token hashes
+// are persisted so a database leak does not expose usable tokens.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,292 @@
+package helpers
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const minRSAKeyBits = 2048
+
+var (
+	// setupMu serializes the lazy key setup so concurrent first requests share one key
+	setupMu          sync.Mutex
+	keysMu           sync.RWMutex
+	signingKey       *tokenKey
+	verificationKeys map[string]*tokenKey
+)
+
+// tokenKey is a key used to sign or verify access tokens.
+type tokenKey struct {
+	public  crypto.PublicKey
+	private crypto.Signer // nil for verification-only keys
+	method  jwt.SigningMethod
+	id      string
+}
+
+// JSONWebKey is the public part of a token key in RFC 7517 format.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet is a set of public keys published at /.well-known/jwks.json.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// SetupTokenKeys loads the access token signing key from JWT_SIGNING_KEY_FILE and any
+// additional verification keys from JWT_VERIFICATION_KEY_FILES (comma separated PEM files).
+// Keeping the previous signing key as a verification key lets keys rotate without downtime.
+// Outside production an ephemeral Ed25519 key is generated when no signing key is configured.
+func SetupTokenKeys() error {
+	signing, err := loadSigningKey()
+	if err != nil {
+		return err
+	}
+
+	verification := map[string]*tokenKey{signing.id: signing}
+	for _, path := range strings.Split(GetEnv("JWT_VERIFICATION_KEY_FILES", ""), ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		key, err := loadKeyFile(path)
+		if err != nil {
+			return err
+		}
+		if _, exists := verification[key.id]; !exists {
+			verification[key.id] = key
+		}
+	}
+
+	keysMu.Lock()
+	signingKey = signing
+	verificationKeys = verification
+	keysMu.Unlock()
+
+	if Logger != nil {
+		Logger.Infof("Token keys loaded: signing kid %s, %d verification keys", signing.id, len(verification))
+	}
+	return nil
+}
+
+func loadSigningKey() (*tokenKey, error) {
+	path := GetEnv("JWT_SIGNING_KEY_FILE", "")
+	if path == "" {
+		if GetEnv("ENVIRONMENT", "development") == "production" {
+			return nil, errors.New("required environment variable JWT_SIGNING_KEY_FILE is not set")
+		}
+
+		if Logger != nil {
+			Logger.Warn("JWT_SIGNING_KEY_FILE not set, using an ephemeral signing key; tokens will not survive restarts")
+		}
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		return newTokenKey(private.Public(), private)
+	}
+
+	key, err := loadKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if key.private == nil {
+		return nil, fmt.Errorf("signing key %s does not contain a private key", path)
+	}
+	return key, nil
+}
+
+// loadKeyFile reads a PEM encoded RSA or Ed25519 private or public key.
+func loadKeyFile(path string) (*tokenKey, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from trusted configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key file %s is not PEM encoded", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key file %s has unsupported PEM type %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", path, err)
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return newTokenKey(&k.PublicKey, k)
+	case ed25519.PrivateKey:
+		return newTokenKey(k.Public(), k)
+	default:
+		return newTokenKey(k, nil)
+	}
+}
+
+func newTokenKey(public crypto.PublicKey, private crypto.Signer) (*tokenKey, error) {
+	key := &tokenKey{public: public, private: private}
+
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSAKeyBits)
+		}
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", public)
+	}
+
+	thumbprint, err := jwkThumbprint(key.jwk())
+	if err != nil {
+		return nil, err
+	}
+	key.id = thumbprint
+	return key, nil
+}
+
+// jwk returns the public JWK of the key without kid, use and alg.
+func (k *tokenKey) jwk() JSONWebKey {
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return JSONWebKey{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}
+	default:
+		return JSONWebKey{}
+	}
+}
+
+// jwkThumbprint computes the RFC 7638 SHA-256 thumbprint used as kid.
+func jwkThumbprint(jwk JSONWebKey) (string, error) {
+	var members map[string]string
+	if jwk.Kty == "RSA" {
+		members = map[string]string{"e": jwk.E, "kty": jwk.Kty, "n": jwk.N}
+	} else {
+		members = map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X}
+	}
+
+	// encoding/json sorts map keys, giving the canonical member order
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute key thumbprint: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// currentSigningKey returns the signing key, loading the key configuration on first use.
+func currentSigningKey() (*tokenKey, error) {
+	if key := loadedSigningKey(); key != nil {
+		return key, nil
+	}
+
+	setupMu.Lock()
+	defer setupMu.Unlock()
+
+	// Another request may have loaded the keys while this one waited
+	if key := loadedSigningKey(); key != nil {
+		return key, nil
+	}
+	if err := SetupTokenKeys(); err != nil {
+		return nil, err
+	}
+	return loadedSigningKey(), nil
+}
+
+func loadedSigningKey() *tokenKey {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	return signingKey
+}
+
+// verificationKey returns the key with the given kid.
+func verificationKey(kid string) (*tokenKey, error) {
+	if _, err := currentSigningKey(); err != nil {
+		return nil, err
+	}
+
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+
+	key, ok := verificationKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+// PublicJWKS returns every verification key as a JSON Web Key Set.
+func PublicJWKS() (*JSONWebKeySet, error) {
+	if _, err := currentSigningKey(); err != nil {
+		return nil, err
+	}
+
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+
+	// Publish the signing key first, then the remaining keys in a stable order
+	keys := make([]*tokenKey, 0, len(verificationKeys))
+	for id, key := range verificationKeys {
+		if id != signingKey.id {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].id < keys[j].id })
+	keys = append([]*tokenKey{signingKey}, keys...)
+
+	set := &JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(keys))}
+	for _, key := range keys {
+		jwk := key.jwk()
+		jwk.Kid = key.id
+		jwk.Use = "sig"
+		jwk.Alg = key.method.Alg()
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set, nil
+}
@@ -0,0 +1,195 @@
+package helpers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// writeKeyFile writes a PKCS#8 PEM private key to a temporary file.
+func writeKeyFile(t *testing.T, key interface{}) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	return path
+}
+
+func newEd25519KeyFile(t *testing.T) string {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return writeKeyFile(t, key)
+}
+
+// useTokenKeys loads the given key configuration and restores an ephemeral key afterwards.
+func useTokenKeys(t *testing.T, signingFile, verificationFiles string) {
+	t.Helper()
+
+	t.Setenv("JWT_SIGNING_KEY_FILE", signingFile)
+	t.Setenv("JWT_VERIFICATION_KEY_FILES", verificationFiles)
+	if err := SetupTokenKeys(); err != nil {
+		t.Fatalf("Failed to load token keys: %v", err)
+	}
+
+	t.Cleanup(func() {
+		keysMu.Lock()
+		signingKey, verificationKeys = nil, nil
+		keysMu.Unlock()
+	})
+}
+
+func TestAccessToken_SigningAlgorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		keyFile string
+		wantAlg string
+	}{
+		{name: "RSA", keyFile: writeKeyFile(t, rsaKey), wantAlg: "RS256"},
+		{name: "Ed25519", keyFile: newEd25519KeyFile(t), wantAlg: "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			useTokenKeys(t, tt.keyFile, "")
+			userID := uuid.New()
+
+			// Act
+			token, err := GenerateAccessToken(AccessTokenParams{
+				UserID:    userID,
+				SessionID: uuid.New(),
+				ExpiresAt: time.Now().Add(time.Minute),
+			})
+			if err != nil {
+				t.Fatalf("Failed to generate token: %v", err)
+			}
+			claims, err := ParseAccessToken(token)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected token to verify, got %v", err)
+			}
+			if claims.Subject != userID.String() {
+				t.Errorf("Expected subject %s, got %s", userID, claims.Subject)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &AccessTokenClaims{})
+			if err != nil {
+				t.Fatalf("Failed to parse token header: %v", err)
+			}
+			jwks, err := PublicJWKS()
+			if err != nil {
+				t.Fatalf("Failed to build JWKS: %v", err)
+			}
+			if parsed.Header["alg"] != tt.wantAlg || parsed.Header["kid"] != jwks.Keys[0].Kid {
+				t.Errorf("Expected alg %s and kid %s, got header %v", tt.wantAlg, jwks.Keys[0].Kid, parsed.Header)
+			}
+		})
+	}
+}
+
+func TestAccessToken_KeyRotation(t *testing.T) {
+	oldKey, newKey := newEd25519KeyFile(t), newEd25519KeyFile(t)
+
+	useTokenKeys(t, oldKey, "")
+	oldToken, err := GenerateAccessToken(AccessTokenParams{
+		UserID:    uuid.New(),
+		SessionID: uuid.New(),
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	t.Run("previous key still verifies", func(t *testing.T) {
+		useTokenKeys(t, newKey, oldKey)
+
+		if _, err := ParseAccessToken(oldToken); err != nil {
+			t.Errorf("Expected token signed by previous key to verify, got %v", err)
+		}
+
+		jwks, err := PublicJWKS()
+		if err != nil {
+			t.Fatalf("Failed to build JWKS: %v", err)
+		}
+		if len(jwks.Keys) != 2 {
+			t.Errorf("Expected 2 published keys, got %d", len(jwks.Keys))
+		}
+	})
+
+	t.Run("retired key is rejected", func(t *testing.T) {
+		useTokenKeys(t, newKey, "")
+
+		if _, err := ParseAccessToken(oldToken); err == nil {
+			t.Error("Expected token signed by retired key to be rejected, got nil")
+		}
+	})
+}
+
+func TestSetupTokenKeys_Production(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("JWT_SIGNING_KEY_FILE", "")
+
+	if err := SetupTokenKeys(); err == nil {
+		t.Error("Expected error when no signing key is configured in production, got nil")
+	}
+}
+
+func TestCurrentSigningKey_ConcurrentFirstUse(t *testing.T) {
+	// Arrange
+	t.Setenv("JWT_SIGNING_KEY_FILE", "")
+	t.Setenv("JWT_VERIFICATION_KEY_FILES", "")
+	keysMu.Lock()
+	signingKey, verificationKeys = nil, nil
+	keysMu.Unlock()
+	t.Cleanup(func() {
+		keysMu.Lock()
+		signingKey, verificationKeys = nil, nil
+		keysMu.Unlock()
+	})
+
+	// Act
+	keys := make([]*tokenKey, 16)
+	var wg sync.WaitGroup
+	for i := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keys[i], _ = currentSigningKey()
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	for _, key := range keys {
+		if key == nil || key != keys[0] {
+			t.Fatal("Expected every request to use the same ephemeral signing key")
+		}
+	}
+}
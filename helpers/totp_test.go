@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 test key from RFC 6238 appendix B.
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	// Six-digit suffixes of the RFC 6238 appendix B SHA-1 test vectors
+	tests := []struct {
+		want string
+		unix int64
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		// Act
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+
+		// Assert
+		if err != nil {
+			t.Fatalf("TOTPCode returned error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Expected code %s at %d, got %s", tt.want, tt.unix, got)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	// Arrange
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret returned error: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	previous, _ := TOTPCode(secret, TOTPStep(now)-1)
+	stale, _ := TOTPCode(secret, TOTPStep(now)-3)
+
+	// Act
+	step, ok := ValidateTOTP(secret, previous, now)
+	_, staleOK := ValidateTOTP(secret, stale, now)
+
+	// Assert
+	if !ok || step != TOTPStep(now)-1 {
+		t.Errorf("Expected the previous step's code to match step %d, got %v, %d", TOTPStep(now)-1, ok, step)
+	}
+	if staleOK {
+		t.Error("Expected a code outside the allowed skew to be rejected")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	// Act
+	uri := TOTPURI("E-Wallet", "john@example.com", "JBSWY3DPEHPK3PXP")
+
+	// Assert
+	if !strings.HasPrefix(uri, "otpauth://totp/E-Wallet:john@example.com?") {
+		t.Errorf("Expected an otpauth URI with issuer and account label, got %s", uri)
+	}
+	if !strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP") || !strings.Contains(uri, "issuer=E-Wallet") {
+		t.Errorf("Expected secret and issuer parameters, got %s", uri)
+	}
+}
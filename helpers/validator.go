@@ -0,0 +1,118 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+)
+
+var validate = newValidator()
+
+// FieldError describes a single invalid request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when a request fails validation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+" "+f.Message)
+	}
+	return "validation failed: " + strings.Join(parts, ", ")
+}
+
+// Unwrap makes validation errors match domain.ErrValidation.
+func (e *ValidationError) Unwrap() error {
+	return domain.ErrValidation
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by their JSON name so clients can map errors to inputs
+	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	return v
+}
+
+// ValidateStruct validates a struct using its `validate` tags.
+// It returns a *ValidationError listing every invalid field.
+func ValidateStruct(s interface{}) error {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return fmt.Errorf("failed to validate request: %w", err)
+	}
+
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, FieldError{
+			Field:   fe.Field(),
+			Message: validationMessage(fe),
+		})
+	}
+
+	return &ValidationError{Fields: fields}
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return fmt.Sprintf("is required when %s is not provided", strings.ToLower(fe.Param()))
+	case "email":
+		return "must be a valid email address"
+	case "e164":
+		return "must be a valid phone number in E.164 format"
+	case "min":
+		return fmt.Sprintf("must be at least %s characters", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "len":
+		return fmt.Sprintf("must be exactly %s characters", fe.Param())
+	case "numeric", "number":
+		return "must contain only digits"
+	case "nefield":
+		return fmt.Sprintf("must be different from %s", snakeCase(fe.Param()))
+	default:
+		return "is invalid"
+	}
+}
+
+// snakeCase converts a Go field name such as CurrentPassword to its JSON name current_password.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const webAuthnCeremonyTimeout = 5 * time.Minute
+
+// NewWebAuthn creates the WebAuthn relying party from WEBAUTHN_RP_ID, WEBAUTHN_RP_DISPLAY_NAME
+// and WEBAUTHN_RP_ORIGINS (comma separated, including android:apk-key-hash: origins of the
+// mobile apps). Outside production it defaults to localhost.
+func NewWebAuthn() (*webauthn.WebAuthn, error) {
+	rpID := GetEnv("WEBAUTHN_RP_ID", "")
+	rawOrigins := GetEnv("WEBAUTHN_RP_ORIGINS", "")
+	if rpID == "" || rawOrigins == "" {
+		if GetEnv("ENVIRONMENT", "development") == "production" {
+			return nil, errors.New("required environment variables WEBAUTHN_RP_ID and WEBAUTHN_RP_ORIGINS are not set")
+		}
+		rpID = "localhost"
+		rawOrigins = "http://localhost:" + GetEnv("PORT", "8080")
+	}
+
+	var origins []string
+	for _, origin := range strings.Split(rawOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: webAuthnCeremonyTimeout, TimeoutUVD: webAuthnCeremonyTimeout}
+	relyingParty, err := webauthn.New(&webauthn.Config{
+		RPID:                  rpID,
+		RPDisplayName:         GetEnv("WEBAUTHN_RP_DISPLAY_NAME", "E-Wallet"),
+		RPOrigins:             origins,
+		AttestationPreference: protocol.PreferNoAttestation,
+		Timeouts:              webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebAuthn configuration: %w", err)
+	}
+	return relyingParty, nil
+}
@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestNewWebAuthn(t *testing.T) {
+	// Arrange
+	t.Setenv("WEBAUTHN_RP_ID", "wallet.example.com")
+	t.Setenv("WEBAUTHN_RP_ORIGINS", "https://wallet.example.com, android:apk-key-hash:abc")
+
+	// Act
+	relyingParty, err := NewWebAuthn()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("NewWebAuthn returned error: %v", err)
+	}
+	if relyingParty.Config.RPID != "wallet.example.com" || len(relyingParty.Config.RPOrigins) != 2 {
+		t.Errorf("Expected the configured relying party, got %+v", relyingParty.Config)
+	}
+}
+
+func TestNewWebAuthn_MissingInProduction(t *testing.T) {
+	// Arrange
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("WEBAUTHN_RP_ID", "")
+	t.Setenv("WEBAUTHN_RP_ORIGINS", "")
+
+	// Act
+	_, err := NewWebAuthn()
+
+	// Assert
+	if err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+type AdminUser struct {
+	AdminUserServices interfaces.IAdminUserServices
+}
+
+func (api *AdminUser) ListUsersHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseUserFilter(r)
+	if err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid query parameters", err, http.StatusBadRequest)
+		return
+	}
+
+	result, err := api.AdminUserServices.ListUsers(r.Context(), filter)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to list users", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, result, "Users retrieved successfully", http.StatusOK)
+}
+
+func (api *AdminUser) GetUserHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid user ID", err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := api.AdminUserServices.GetUser(r.Context(), id)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to get user", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, user, "User retrieved successfully", http.StatusOK)
+}
+
+func (api *AdminUser) UpdateUserHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid user ID", err, http.StatusBadRequest)
+		return
+	}
+
+	var req models.AdminUpdateUserRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+	for _, permission := range requiredUpdatePermissions(&req) {
+		if !principal.HasPermission(permission) {
+			helpers.SendErrorResponse(w, r, "Forbidden", fmt.Errorf("missing permission %s", permission), http.StatusForbidden)
+			return
+		}
+	}
+
+	user, err := api.AdminUserServices.UpdateUser(r.Context(), id, &req)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to update user", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, user, "User updated successfully", http.StatusOK)
+}
+
+func (api *AdminUser) DeleteUserHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid user ID", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.AdminUserServices.DeleteUser(r.Context(), id); err != nil {
+		helpers.SendError(w, r, "Failed to delete user", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "User deleted successfully", http.StatusOK)
+}
+
+func (api *AdminUser) RevokeSessionsHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid user ID", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.AdminUserServices.RevokeSessions(r.Context(), id); err != nil {
+		helpers.SendError(w, r, "Failed to revoke sessions", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Sessions revoked successfully", http.StatusOK)
+}
+
+func (api *AdminUser) UnlockUserHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid user ID", err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := api.AdminUserServices.UnlockUser(r.Context(), id)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to unlock user", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, user, "User unlocked successfully", http.StatusOK)
+}
+
+// requiredUpdatePermissions returns the permissions needed to apply the update:
+// users:deactivate to change is_active and users:write for any other field.
+func requiredUpdatePermissions(req *models.AdminUpdateUserRequest) []string {
+	var permissions []string
+	if req.IsActive != nil {
+		permissions = append(permissions, constants.PermissionUsersDeactivate)
+	}
+	if req.Email != nil || req.Phone != nil || req.FullName != nil || req.IsVerified != nil {
+		permissions = append(permissions, constants.PermissionUsersWrite)
+	}
+	return permissions
+}
+
+// parseUserFilter reads the user list filters and pagination from the query string.
+func parseUserFilter(r *http.Request) (models.UserFilter, error) {
+	query := r.URL.Query()
+	filter := models.UserFilter{
+		Email: query.Get("email"),
+		Phone: query.Get("phone"),
+	}
+
+	var fields []helpers.FieldError
+	parseBool := func(name string) *bool {
+		raw := query.Get(name)
+		if raw == "" {
+			return nil
+		}
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			fields = append(fields, helpers.FieldError{Field: name, Message: "must be true or false"})
+			return nil
+		}
+		return &value
+	}
+	parseInt := func(name string, minValue, maxValue, defaultValue int) int {
+		raw := query.Get(name)
+		if raw == "" {
+			return defaultValue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < minValue || value > maxValue {
+			fields = append(fields, helpers.FieldError{
+				Field:   name,
+				Message: fmt.Sprintf("must be a number between %d and %d", minValue, maxValue),
+			})
+			return defaultValue
+		}
+		return value
+	}
+
+	filter.IsActive = parseBool("is_active")
+	filter.IsVerified = parseBool("is_verified")
+	filter.Limit = parseInt("limit", 1, constants.MaxPageSize, constants.DefaultPageSize)
+	filter.Offset = parseInt("offset", 0, math.MaxInt32, 0)
+
+	if len(fields) > 0 {
+		return filter, &helpers.ValidationError{Fields: fields}
+	}
+	return filter, nil
+}
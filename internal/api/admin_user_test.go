@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+// Mock service for testing.
+type mockAdminUserService struct {
+	err        error
+	lastFilter models.UserFilter
+}
+
+func (m *mockAdminUserService) ListUsers(_ context.Context, filter models.UserFilter) (*models.UserListResponse, error) {
+	m.lastFilter = filter
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.UserListResponse{Users: []*models.User{}, PageSize: filter.Limit, Page: 1}, nil
+}
+
+func (m *mockAdminUserService) GetUser(_ context.Context, id uuid.UUID) (*models.User, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.User{ID: id}, nil
+}
+
+func (m *mockAdminUserService) UpdateUser(_ context.Context, id uuid.UUID, _ *models.AdminUpdateUserRequest) (*models.User, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.User{ID: id}, nil
+}
+
+func (m *mockAdminUserService) DeleteUser(_ context.Context, _ uuid.UUID) error {
+	return m.err
+}
+
+func (m *mockAdminUserService) RevokeSessions(_ context.Context, _ uuid.UUID) error {
+	return m.err
+}
+
+func (m *mockAdminUserService) UnlockUser(_ context.Context, id uuid.UUID) (*models.User, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.User{ID: id}, nil
+}
+
+// newAdminUserRouter mounts the admin user handlers behind a principal with the given permissions.
+func newAdminUserRouter(svc *mockAdminUserService, permissions ...string) http.Handler {
+	handler := &AdminUser{AdminUserServices: svc}
+	principal := &helpers.Principal{UserID: uuid.New(), Permissions: permissions}
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(helpers.WithPrincipal(r.Context(), principal)))
+		})
+	})
+	r.Get("/api/v1/admin/users", handler.ListUsersHandlerHTTP)
+	r.Get("/api/v1/admin/users/{id}", handler.GetUserHandlerHTTP)
+	r.Patch("/api/v1/admin/users/{id}", handler.UpdateUserHandlerHTTP)
+	r.Delete("/api/v1/admin/users/{id}", handler.DeleteUserHandlerHTTP)
+	r.Post("/api/v1/admin/users/{id}/sessions/revoke", handler.RevokeSessionsHandlerHTTP)
+	r.Post("/api/v1/admin/users/{id}/unlock", handler.UnlockUserHandlerHTTP)
+	return r
+}
+
+func TestAdminUser_ListUsersHandlerHTTP(t *testing.T) {
+	tests := []struct {
+		err        error
+		name       string
+		query      string
+		wantLimit  int
+		wantStatus int
+	}{
+		{name: "defaults", wantLimit: 20, wantStatus: http.StatusOK},
+		{name: "filters", query: "?email=a@example.com&is_active=true&limit=50&offset=100", wantLimit: 50, wantStatus: http.StatusOK},
+		{name: "invalid bool", query: "?is_verified=maybe", wantStatus: http.StatusBadRequest},
+		{name: "limit too large", query: "?limit=1000", wantStatus: http.StatusBadRequest},
+		{name: "negative offset", query: "?offset=-1", wantStatus: http.StatusBadRequest},
+		{name: "service error", err: errors.New("db down"), wantLimit: 20, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			svc := &mockAdminUserService{err: tt.err}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users"+tt.query, http.NoBody)
+			w := httptest.NewRecorder()
+
+			// Act
+			newAdminUserRouter(svc).ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantLimit != 0 && svc.lastFilter.Limit != tt.wantLimit {
+				t.Errorf("Expected limit %d, got %d", tt.wantLimit, svc.lastFilter.Limit)
+			}
+		})
+	}
+}
+
+func TestAdminUser_ListUsersHandlerHTTP_Envelope(t *testing.T) {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users?limit=5", http.NoBody)
+	w := httptest.NewRecorder()
+
+	// Act
+	newAdminUserRouter(&mockAdminUserService{}).ServeHTTP(w, req)
+
+	// Assert
+	var resp struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	for _, key := range []string{"users", "total", "page", "page_size"} {
+		if _, ok := resp.Data[key]; !ok {
+			t.Errorf("Expected %q in paginated envelope, got %v", key, resp.Data)
+		}
+	}
+}
+
+func TestAdminUser_UserHandlers(t *testing.T) {
+	userPath := "/api/v1/admin/users/" + uuid.NewString()
+
+	tests := []struct {
+		err         error
+		name        string
+		method      string
+		path        string
+		body        string
+		permissions []string
+		wantStatus  int
+	}{
+		{name: "get", method: http.MethodGet, path: userPath, wantStatus: http.StatusOK},
+		{name: "get invalid id", method: http.MethodGet, path: "/api/v1/admin/users/123", wantStatus: http.StatusBadRequest},
+		{name: "get not found", method: http.MethodGet, path: userPath, err: services.ErrUserNotFound, wantStatus: http.StatusNotFound},
+		{name: "update", method: http.MethodPatch, path: userPath, body: `{"is_active":false}`, wantStatus: http.StatusOK},
+		{
+			name:        "deactivate without permission",
+			method:      http.MethodPatch,
+			path:        userPath,
+			body:        `{"is_active":false}`,
+			permissions: []string{"users:write"},
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "edit without permission",
+			method:      http.MethodPatch,
+			path:        userPath,
+			body:        `{"is_active":false,"full_name":"Jane"}`,
+			permissions: []string{"users:deactivate"},
+			wantStatus:  http.StatusForbidden,
+		},
+		{name: "update unknown field", method: http.MethodPatch, path: userPath, body: `{"password":"x"}`, wantStatus: http.StatusBadRequest},
+		{
+			name:       "update conflict",
+			method:     http.MethodPatch,
+			path:       userPath,
+			body:       `{"email":"jane@example.com"}`,
+			err:        services.ErrEmailAlreadyRegistered,
+			wantStatus: http.StatusConflict,
+		},
+		{name: "delete", method: http.MethodDelete, path: userPath, wantStatus: http.StatusOK},
+		{name: "delete not found", method: http.MethodDelete, path: userPath, err: services.ErrUserNotFound, wantStatus: http.StatusNotFound},
+		{name: "delete error", method: http.MethodDelete, path: userPath, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+		{name: "revoke sessions", method: http.MethodPost, path: userPath + "/sessions/revoke", wantStatus: http.StatusOK},
+		{
+			name:       "revoke sessions not found",
+			method:     http.MethodPost,
+			path:       userPath + "/sessions/revoke",
+			err:        services.ErrUserNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{name: "unlock", method: http.MethodPost, path: userPath + "/unlock", wantStatus: http.StatusOK},
+		{name: "unlock invalid id", method: http.MethodPost, path: "/api/v1/admin/users/abc/unlock", wantStatus: http.StatusBadRequest},
+		{
+			name:       "unlock not found",
+			method:     http.MethodPost,
+			path:       userPath + "/unlock",
+			err:        services.ErrUserNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			permissions := tt.permissions
+			if permissions == nil {
+				permissions = []string{"users:read", "users:write", "users:deactivate", "users:delete", "sessions:revoke"}
+			}
+			newAdminUserRouter(&mockAdminUserService{err: tt.err}, permissions...).ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+type Auth struct {
+	AuthServices interfaces.IAuthServices
+}
+
+func (api *Auth) LoginHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.LoginRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	req.IPAddress = helpers.ClientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	resp, err := api.AuthServices.Login(r.Context(), &req)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to login", err)
+		return
+	}
+
+	if resp.MFARequired && resp.MFA.Reason == constants.MFAReasonNewDevice {
+		helpers.SendResponse(w, r, resp, "New device verification required", http.StatusOK)
+		return
+	}
+	if resp.MFARequired {
+		helpers.SendResponse(w, r, resp, "Two-factor authentication required", http.StatusOK)
+		return
+	}
+
+	helpers.SendResponse(w, r, resp, "Login successful", http.StatusOK)
+}
+
+func (api *Auth) LoginMFAHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.LoginMFARequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	req.IPAddress = helpers.ClientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	tokens, err := api.AuthServices.CompleteMFALogin(r.Context(), &req)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to login", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, tokens, "Login successful", http.StatusOK)
+}
+
+func (api *Auth) MFAPasskeyOptionsHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.MFAPasskeyOptionsRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	options, err := api.AuthServices.BeginMFAPasskey(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPasskey) {
+			helpers.SendErrorResponse(w, r, "No passkey registered", err, http.StatusBadRequest)
+			return
+		}
+		helpers.SendError(w, r, "Failed to start passkey verification", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, options, "Passkey challenge created", http.StatusOK)
+}
+
+func (api *Auth) PasskeyLoginOptionsHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	options, err := api.AuthServices.BeginPasskeyLogin(r.Context())
+	if err != nil {
+		helpers.SendError(w, r, "Failed to start passkey login", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, options, "Passkey challenge created", http.StatusOK)
+}
+
+func (api *Auth) PasskeyLoginHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.PasskeyLoginRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	req.IPAddress = helpers.ClientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	tokens, err := api.AuthServices.FinishPasskeyLogin(r.Context(), &req)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to login", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, tokens, "Login successful", http.StatusOK)
+}
+
+func (api *Auth) RefreshHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshTokenRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	req.IPAddress = helpers.ClientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	tokens, err := api.AuthServices.Refresh(r.Context(), &req)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to refresh token", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, tokens, "Token refreshed successfully", http.StatusOK)
+}
+
+func (api *Auth) LogoutHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	if err := api.AuthServices.Logout(r.Context(), principal.SessionID); err != nil {
+		helpers.SendError(w, r, "Failed to logout", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Logout successful", http.StatusOK)
+}
+
+func (api *Auth) LogoutAllHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	if err := api.AuthServices.LogoutAll(r.Context(), principal.UserID); err != nil {
+		helpers.SendError(w, r, "Failed to logout from all sessions", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Logged out from all sessions", http.StatusOK)
+}
+
+func (api *Auth) ValidateTokenHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	token := helpers.BearerToken(r)
+	if token == "" {
+		helpers.SendErrorResponse(w, r, "Missing bearer token", services.ErrInvalidAccessToken, http.StatusBadRequest)
+		return
+	}
+
+	result, err := api.AuthServices.ValidateToken(r.Context(), token)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to validate token", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, result, "Token validated", http.StatusOK)
+}
+
+func (api *Auth) JWKSHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	jwks, err := helpers.PublicJWKS()
+	if err != nil {
+		helpers.SendError(w, r, "Failed to load signing keys", err)
+		return
+	}
+
+	// Served as a bare JWK Set so standard JWT libraries can consume it directly
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(constants.JWKSCacheMaxAge.Seconds())))
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(jwks); err != nil && helpers.Logger != nil {
+		helpers.Logger.Errorf("Failed to encode JWKS: %v", err)
+	}
+}
@@ -0,0 +1,445 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+// Mock service for testing.
+type mockAuthService struct {
+	err         error
+	lastReq     *models.LoginRequest
+	mfaRequired bool
+}
+
+func (m *mockAuthService) Login(_ context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
+	m.lastReq = req
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.mfaRequired {
+		return &models.LoginResponse{MFARequired: true, MFA: &models.MFAChallenge{Token: "mfa-token"}}, nil
+	}
+	return &models.LoginResponse{
+		TokenResponse: &models.TokenResponse{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer"},
+	}, nil
+}
+
+func (m *mockAuthService) CompleteMFALogin(_ context.Context, _ *models.LoginMFARequest) (*models.TokenResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.TokenResponse{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer"}, nil
+}
+
+func (m *mockAuthService) BeginMFAPasskey(_ context.Context, _ *models.MFAPasskeyOptionsRequest) (*protocol.CredentialAssertion, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &protocol.CredentialAssertion{}, nil
+}
+
+func (m *mockAuthService) BeginPasskeyLogin(_ context.Context) (*protocol.CredentialAssertion, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &protocol.CredentialAssertion{}, nil
+}
+
+func (m *mockAuthService) FinishPasskeyLogin(_ context.Context, _ *models.PasskeyLoginRequest) (*models.TokenResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.TokenResponse{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer"}, nil
+}
+
+func (m *mockAuthService) Refresh(_ context.Context, _ *models.RefreshTokenRequest) (*models.TokenResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.TokenResponse{AccessToken: "new-access", RefreshToken: "new-refresh", TokenType: "Bearer"}, nil
+}
+
+func (m *mockAuthService) Authenticate(_ context.Context, _ string) (*models.UserSession, error) {
+	return &models.UserSession{ID: uuid.New(), UserID: uuid.New()}, nil
+}
+
+func (m *mockAuthService) Logout(_ context.Context, _ uuid.UUID) error {
+	return m.err
+}
+
+func (m *mockAuthService) LogoutAll(_ context.Context, _ uuid.UUID) error {
+	return m.err
+}
+
+func (m *mockAuthService) ValidateToken(_ context.Context, accessToken string) (*models.TokenValidationResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.TokenValidationResponse{Active: accessToken == "valid-token"}, nil
+}
+
+const loginBody = `{"email":"john@example.com","password":"password123"}`
+
+func TestAuth_LoginHandlerHTTP(t *testing.T) {
+	tests := []struct {
+		err        error
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", body: loginBody, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", body: loginBody, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "invalid credentials", body: loginBody, err: services.ErrInvalidCredentials, wantStatus: http.StatusUnauthorized},
+		{name: "inactive user", body: loginBody, err: services.ErrUserInactive, wantStatus: http.StatusForbidden},
+		{
+			name:       "temporarily locked",
+			body:       loginBody,
+			err:        &domain.RetryAfterError{Err: services.ErrAccountTemporarilyLocked, RetryAfter: time.Minute},
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{name: "hard locked", body: loginBody, err: services.ErrAccountLocked, wantStatus: http.StatusLocked},
+		{name: "service error", body: loginBody, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &Auth{AuthServices: &mockAuthService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.LoginHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuth_LoginHandlerHTTP_SessionMetadata(t *testing.T) {
+	// Arrange
+	svc := &mockAuthService{}
+	handler := &Auth{AuthServices: svc}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(loginBody))
+	req.RemoteAddr = "203.0.113.7:54321"
+	req.Header.Set("User-Agent", "ewallet-android/1.0")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.LoginHandlerHTTP(w, req)
+
+	// Assert
+	if svc.lastReq.IPAddress != "203.0.113.7" {
+		t.Errorf("Expected IP '203.0.113.7', got '%s'", svc.lastReq.IPAddress)
+	}
+	if svc.lastReq.UserAgent != "ewallet-android/1.0" {
+		t.Errorf("Expected user agent 'ewallet-android/1.0', got '%s'", svc.lastReq.UserAgent)
+	}
+}
+
+func TestAuth_LoginHandlerHTTP_MFAChallenge(t *testing.T) {
+	// Arrange
+	handler := &Auth{AuthServices: &mockAuthService{mfaRequired: true}}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(loginBody))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.LoginHandlerHTTP(w, req)
+
+	// Assert
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Data["mfa_required"] != true {
+		t.Errorf("Expected mfa_required to be true, got %v", resp.Data["mfa_required"])
+	}
+	if _, ok := resp.Data["access_token"]; ok {
+		t.Error("Expected no access token before the second factor")
+	}
+}
+
+func TestAuth_LoginMFAHandlerHTTP(t *testing.T) {
+	const body = `{"mfa_token":"mfa-token","code":"123456"}`
+
+	tests := []struct {
+		err        error
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", body: body, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", body: body, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "invalid mfa token", body: body, err: services.ErrInvalidMFAToken, wantStatus: http.StatusUnauthorized},
+		{name: "invalid code", body: body, err: services.ErrInvalidMFACode, wantStatus: http.StatusUnauthorized},
+		{name: "invalid passkey", body: body, err: services.ErrInvalidPasskey, wantStatus: http.StatusUnauthorized},
+		{
+			name:       "temporarily locked",
+			body:       body,
+			err:        &domain.RetryAfterError{Err: services.ErrAccountTemporarilyLocked, RetryAfter: time.Minute},
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{name: "hard locked", body: body, err: services.ErrAccountLocked, wantStatus: http.StatusLocked},
+		{name: "inactive user", body: body, err: services.ErrUserInactive, wantStatus: http.StatusForbidden},
+		{name: "service error", body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &Auth{AuthServices: &mockAuthService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login/mfa", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.LoginMFAHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuth_MFAPasskeyOptionsHandlerHTTP(t *testing.T) {
+	const body = `{"mfa_token":"mfa-token"}`
+
+	tests := []struct {
+		err        error
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", body: body, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", body: body, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "invalid mfa token", body: body, err: services.ErrInvalidMFAToken, wantStatus: http.StatusUnauthorized},
+		{name: "no passkey", body: body, err: services.ErrInvalidPasskey, wantStatus: http.StatusBadRequest},
+		{name: "service error", body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &Auth{AuthServices: &mockAuthService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login/mfa/passkey/options", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.MFAPasskeyOptionsHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuth_PasskeyLoginHandlerHTTP(t *testing.T) {
+	const body = `{"credential":{"id":"abc"}}`
+
+	tests := []struct {
+		err        error
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", body: body, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", body: body, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "invalid passkey", body: body, err: services.ErrInvalidPasskey, wantStatus: http.StatusUnauthorized},
+		{
+			name:       "temporarily locked",
+			body:       body,
+			err:        &domain.RetryAfterError{Err: services.ErrAccountTemporarilyLocked, RetryAfter: time.Minute},
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{name: "hard locked", body: body, err: services.ErrAccountLocked, wantStatus: http.StatusLocked},
+		{name: "inactive user", body: body, err: services.ErrUserInactive, wantStatus: http.StatusForbidden},
+		{name: "service error", body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &Auth{AuthServices: &mockAuthService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/passkey/login", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.PasskeyLoginHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuth_PasskeyLoginOptionsHandlerHTTP(t *testing.T) {
+	// Arrange
+	handler := &Auth{AuthServices: &mockAuthService{}}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/passkey/options", http.NoBody)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.PasskeyLoginOptionsHandlerHTTP(w, req)
+
+	// Assert
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestAuth_RefreshHandlerHTTP(t *testing.T) {
+	const body = `{"refresh_token":"refresh"}`
+
+	tests := []struct {
+		err        error
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", body: body, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `[]`, wantStatus: http.StatusBadRequest},
+		{name: "invalid token", body: body, err: services.ErrInvalidRefreshToken, wantStatus: http.StatusUnauthorized},
+		{name: "reused token", body: body, err: services.ErrRefreshTokenReused, wantStatus: http.StatusUnauthorized},
+		{name: "inactive user", body: body, err: services.ErrUserInactive, wantStatus: http.StatusForbidden},
+		{name: "service error", body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &Auth{AuthServices: &mockAuthService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.RefreshHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuth_LogoutHandlers(t *testing.T) {
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		wantStatus int
+	}{
+		{name: "success", principal: &helpers.Principal{UserID: uuid.New(), SessionID: uuid.New()}, wantStatus: http.StatusOK},
+		{name: "missing principal", wantStatus: http.StatusUnauthorized},
+		{
+			name:       "revoke error",
+			principal:  &helpers.Principal{UserID: uuid.New(), SessionID: uuid.New()},
+			err:        errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		for path, handle := range map[string]func(*Auth, http.ResponseWriter, *http.Request){
+			"/api/v1/auth/logout":     (*Auth).LogoutHandlerHTTP,
+			"/api/v1/auth/logout-all": (*Auth).LogoutAllHandlerHTTP,
+		} {
+			t.Run(tt.name+" "+path, func(t *testing.T) {
+				// Arrange
+				handler := &Auth{AuthServices: &mockAuthService{err: tt.err}}
+				req := httptest.NewRequest(http.MethodPost, path, http.NoBody)
+				if tt.principal != nil {
+					req = req.WithContext(helpers.WithPrincipal(req.Context(), tt.principal))
+				}
+				w := httptest.NewRecorder()
+
+				// Act
+				handle(handler, w, req)
+
+				// Assert
+				if w.Code != tt.wantStatus {
+					t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+				}
+			})
+		}
+	}
+}
+
+func TestAuth_ValidateTokenHandlerHTTP(t *testing.T) {
+	tests := []struct {
+		err        error
+		name       string
+		header     string
+		wantStatus int
+		wantActive bool
+	}{
+		{name: "active token", header: "Bearer valid-token", wantStatus: http.StatusOK, wantActive: true},
+		{name: "inactive token", header: "Bearer revoked-token", wantStatus: http.StatusOK},
+		{name: "missing token", wantStatus: http.StatusBadRequest},
+		{name: "service error", header: "Bearer valid-token", err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &Auth{AuthServices: &mockAuthService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/internal/token/validate", http.NoBody)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ValidateTokenHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Data models.TokenValidationResponse `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Data.Active != tt.wantActive {
+				t.Errorf("Expected active=%v, got %v", tt.wantActive, resp.Data.Active)
+			}
+		})
+	}
+}
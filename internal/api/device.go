@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+type Device struct {
+	DeviceServices interfaces.IDeviceServices
+}
+
+func (api *Device) ListSessionsHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	resp, err := api.DeviceServices.ListSessions(r.Context(), principal.UserID, principal.SessionID)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to list sessions", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, resp, "Sessions retrieved successfully", http.StatusOK)
+}
+
+func (api *Device) RevokeSessionHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid session ID", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.DeviceServices.RevokeSession(r.Context(), principal.UserID, id); err != nil {
+		helpers.SendError(w, r, "Failed to revoke session", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Session revoked successfully", http.StatusOK)
+}
+
+func (api *Device) RevokeDeviceHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid device ID", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.DeviceServices.RevokeDevice(r.Context(), principal.UserID, id); err != nil {
+		helpers.SendError(w, r, "Failed to revoke device", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Device revoked successfully", http.StatusOK)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+// Mock session and device service for testing.
+type mockDeviceService struct {
+	err error
+}
+
+func (m *mockDeviceService) ListSessions(_ context.Context, _, currentSessionID uuid.UUID) (*models.SessionListResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.SessionListResponse{
+		Sessions: []models.SessionResponse{{ID: currentSessionID, Current: true}},
+		Devices:  []*models.UserDevice{},
+	}, nil
+}
+
+func (m *mockDeviceService) RevokeSession(_ context.Context, _, _ uuid.UUID) error {
+	return m.err
+}
+
+func (m *mockDeviceService) RevokeDevice(_ context.Context, _, _ uuid.UUID) error {
+	return m.err
+}
+
+// newDeviceRouter mounts the session and device handlers, behind principal unless it is nil.
+func newDeviceRouter(svc *mockDeviceService, principal *helpers.Principal) http.Handler {
+	handler := &Device{DeviceServices: svc}
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if principal != nil {
+				r = r.WithContext(helpers.WithPrincipal(r.Context(), principal))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Get("/api/v1/users/me/sessions", handler.ListSessionsHandlerHTTP)
+	r.Delete("/api/v1/users/me/sessions/{id}", handler.RevokeSessionHandlerHTTP)
+	r.Delete("/api/v1/users/me/devices/{id}", handler.RevokeDeviceHandlerHTTP)
+	return r
+}
+
+func TestDevice_Handlers(t *testing.T) {
+	principal := &helpers.Principal{UserID: uuid.New(), SessionID: uuid.New()}
+	sessionPath := "/api/v1/users/me/sessions/" + uuid.NewString()
+	devicePath := "/api/v1/users/me/devices/" + uuid.NewString()
+
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "list", principal: principal, method: http.MethodGet, path: "/api/v1/users/me/sessions", wantStatus: http.StatusOK},
+		{name: "list missing principal", method: http.MethodGet, path: "/api/v1/users/me/sessions", wantStatus: http.StatusUnauthorized},
+		{
+			name:       "list error",
+			principal:  principal,
+			method:     http.MethodGet,
+			path:       "/api/v1/users/me/sessions",
+			err:        errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+		{name: "revoke session", principal: principal, method: http.MethodDelete, path: sessionPath, wantStatus: http.StatusOK},
+		{
+			name:       "revoke session invalid id",
+			principal:  principal,
+			method:     http.MethodDelete,
+			path:       "/api/v1/users/me/sessions/123",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "revoke session not found",
+			principal:  principal,
+			method:     http.MethodDelete,
+			path:       sessionPath,
+			err:        services.ErrSessionNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{name: "revoke session missing principal", method: http.MethodDelete, path: sessionPath, wantStatus: http.StatusUnauthorized},
+		{name: "revoke device", principal: principal, method: http.MethodDelete, path: devicePath, wantStatus: http.StatusOK},
+		{
+			name:       "revoke device not found",
+			principal:  principal,
+			method:     http.MethodDelete,
+			path:       devicePath,
+			err:        services.ErrDeviceNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "revoke device error",
+			principal:  principal,
+			method:     http.MethodDelete,
+			path:       devicePath,
+			err:        errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			newDeviceRouter(&mockDeviceService{err: tt.err}, tt.principal).ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
@@ -14,7 +14,7 @@ type Healthcheck struct {
 func (api *Healthcheck) HealthcheckHandlerHTTP(w http.ResponseWriter, r *http.Request) {
 	response, err := api.HealthcheckServices.HealthcheckServices()
 	if err != nil {
-		helpers.SendErrorResponse(w, r, "Health check failed", err, http.StatusInternalServerError)
+		helpers.SendError(w, r, "Health check failed", err)
 		return
 	}
 
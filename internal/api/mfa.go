@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+type MFA struct {
+	MFAServices interfaces.IMFAServices
+}
+
+func (api *MFA) EnrollTOTPHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.EnrollTOTPRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	enrollment, err := api.MFAServices.EnrollTOTP(r.Context(), principal.UserID, &req)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to enroll authenticator", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, enrollment, "Add the secret to your authenticator app and confirm it with a code", http.StatusOK)
+}
+
+func (api *MFA) ConfirmTOTPHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ConfirmTOTPRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	codes, err := api.MFAServices.ConfirmTOTP(r.Context(), principal.UserID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMFACode) {
+			helpers.SendErrorResponse(w, r, "Invalid authentication code", err, http.StatusBadRequest)
+			return
+		}
+		helpers.SendError(w, r, "Failed to confirm authenticator", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, codes, "Two-factor authentication enabled, store the recovery codes safely", http.StatusOK)
+}
+
+func (api *MFA) DisableTOTPHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.MFAServices.DisableTOTP(r.Context(), principal.UserID, &req); err != nil {
+		if errors.Is(err, services.ErrInvalidMFACode) {
+			helpers.SendErrorResponse(w, r, "Invalid authentication code", err, http.StatusBadRequest)
+			return
+		}
+		helpers.SendError(w, r, "Failed to disable authenticator", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Authenticator app removed", http.StatusOK)
+}
+
+func (api *MFA) RegenerateRecoveryCodesHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	codes, err := api.MFAServices.RegenerateRecoveryCodes(r.Context(), principal.UserID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMFACode) {
+			helpers.SendErrorResponse(w, r, "Invalid authentication code", err, http.StatusBadRequest)
+			return
+		}
+		helpers.SendError(w, r, "Failed to regenerate recovery codes", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, codes, "Recovery codes regenerated, store them safely", http.StatusOK)
+}
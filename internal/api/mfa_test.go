@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+// Mock MFA service for testing.
+type mockMFAService struct {
+	err error
+}
+
+func (m *mockMFAService) EnrollTOTP(_ context.Context, _ uuid.UUID, _ *models.EnrollTOTPRequest) (*models.TOTPEnrollmentResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.TOTPEnrollmentResponse{Secret: "SECRET", OTPAuthURI: "otpauth://totp/E-Wallet:john"}, nil
+}
+
+func (m *mockMFAService) ConfirmTOTP(_ context.Context, _ uuid.UUID, _ *models.ConfirmTOTPRequest) (*models.RecoveryCodesResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.RecoveryCodesResponse{RecoveryCodes: []string{"abcde-12345"}}, nil
+}
+
+func (m *mockMFAService) DisableTOTP(_ context.Context, _ uuid.UUID, _ *models.MFACodeRequest) error {
+	return m.err
+}
+
+func (m *mockMFAService) RegenerateRecoveryCodes(
+	_ context.Context, _ uuid.UUID, _ *models.MFACodeRequest,
+) (*models.RecoveryCodesResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.RecoveryCodesResponse{RecoveryCodes: []string{"abcde-12345"}}, nil
+}
+
+func TestMFA_EnrollTOTPHandlerHTTP(t *testing.T) {
+	const body = `{"password":"password123"}`
+	principal := &helpers.Principal{UserID: uuid.New()}
+
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", principal: principal, body: body, wantStatus: http.StatusOK},
+		{name: "missing principal", body: body, wantStatus: http.StatusUnauthorized},
+		{name: "malformed body", principal: principal, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "wrong password", principal: principal, body: body, err: services.ErrIncorrectPassword, wantStatus: http.StatusBadRequest},
+		{name: "already enabled", principal: principal, body: body, err: services.ErrMFAAlreadyEnabled, wantStatus: http.StatusConflict},
+		{name: "service error", principal: principal, body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &MFA{MFAServices: &mockMFAService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/mfa/totp", strings.NewReader(tt.body))
+			if tt.principal != nil {
+				req = req.WithContext(helpers.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.EnrollTOTPHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestMFA_ConfirmTOTPHandlerHTTP(t *testing.T) {
+	const body = `{"code":"123456","password":"password123"}`
+	principal := &helpers.Principal{UserID: uuid.New()}
+
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", principal: principal, body: body, wantStatus: http.StatusOK},
+		{name: "missing principal", body: body, wantStatus: http.StatusUnauthorized},
+		{name: "malformed body", principal: principal, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", principal: principal, body: body, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "invalid code", principal: principal, body: body, err: services.ErrInvalidMFACode, wantStatus: http.StatusBadRequest},
+		{name: "not enrolled", principal: principal, body: body, err: services.ErrMFANotEnrolled, wantStatus: http.StatusBadRequest},
+		{name: "already enabled", principal: principal, body: body, err: services.ErrMFAAlreadyEnabled, wantStatus: http.StatusConflict},
+		{name: "service error", principal: principal, body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &MFA{MFAServices: &mockMFAService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/mfa/totp/confirm", strings.NewReader(tt.body))
+			if tt.principal != nil {
+				req = req.WithContext(helpers.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ConfirmTOTPHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestMFA_AuthenticatorChangeHandlers(t *testing.T) {
+	const (
+		body           = `{"code":"123456"}`
+		disablePath    = "/api/v1/users/me/mfa/totp/disable"
+		regeneratePath = "/api/v1/users/me/mfa/recovery-codes"
+	)
+	principal := &helpers.Principal{UserID: uuid.New()}
+
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "disable", principal: principal, path: disablePath, body: body, wantStatus: http.StatusOK},
+		{name: "disable missing principal", path: disablePath, body: body, wantStatus: http.StatusUnauthorized},
+		{name: "disable malformed body", principal: principal, path: disablePath, body: `{`, wantStatus: http.StatusBadRequest},
+		{
+			name:       "disable invalid code",
+			principal:  principal,
+			path:       disablePath,
+			body:       body,
+			err:        services.ErrInvalidMFACode,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "disable not enabled",
+			principal:  principal,
+			path:       disablePath,
+			body:       body,
+			err:        services.ErrTOTPNotEnabled,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "disable locked",
+			principal:  principal,
+			path:       disablePath,
+			body:       body,
+			err:        services.ErrAccountLocked,
+			wantStatus: http.StatusLocked,
+		},
+		{
+			name:       "disable service error",
+			principal:  principal,
+			path:       disablePath,
+			body:       body,
+			err:        errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+		{name: "regenerate", principal: principal, path: regeneratePath, body: body, wantStatus: http.StatusOK},
+		{name: "regenerate missing principal", path: regeneratePath, body: body, wantStatus: http.StatusUnauthorized},
+		{
+			name:       "regenerate invalid code",
+			principal:  principal,
+			path:       regeneratePath,
+			body:       body,
+			err:        services.ErrInvalidMFACode,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "regenerate service error",
+			principal:  principal,
+			path:       regeneratePath,
+			body:       body,
+			err:        errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &MFA{MFAServices: &mockMFAService{err: tt.err}}
+			serve := handler.DisableTOTPHandlerHTTP
+			if tt.path == regeneratePath {
+				serve = handler.RegenerateRecoveryCodesHandlerHTTP
+			}
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.principal != nil {
+				req = req.WithContext(helpers.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			serve(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
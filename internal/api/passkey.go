@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+type Passkey struct {
+	PasskeyServices interfaces.IPasskeyServices
+}
+
+func (api *Passkey) RegistrationOptionsHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.PasskeyOptionsRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	options, err := api.PasskeyServices.BeginRegistration(r.Context(), principal.UserID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMFACode) {
+			helpers.SendErrorResponse(w, r, "Invalid authentication code", err, http.StatusBadRequest)
+			return
+		}
+		helpers.SendError(w, r, "Failed to start passkey registration", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, options, "Passkey challenge created", http.StatusOK)
+}
+
+func (api *Passkey) RegisterHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.RegisterPasskeyRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	credential, err := api.PasskeyServices.FinishRegistration(r.Context(), principal.UserID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPasskey) {
+			helpers.SendErrorResponse(w, r, "Invalid passkey", err, http.StatusBadRequest)
+			return
+		}
+		helpers.SendError(w, r, "Failed to register passkey", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, credential, "Passkey registered successfully", http.StatusCreated)
+}
+
+func (api *Passkey) ListHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	credentials, err := api.PasskeyServices.ListPasskeys(r.Context(), principal.UserID)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to list passkeys", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, credentials, "Passkeys retrieved successfully", http.StatusOK)
+}
+
+func (api *Passkey) DeleteHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid passkey ID", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.PasskeyServices.DeletePasskey(r.Context(), principal.UserID, id); err != nil {
+		helpers.SendError(w, r, "Failed to delete passkey", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Passkey deleted successfully", http.StatusOK)
+}
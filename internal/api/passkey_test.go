@@ -0,0 +1,219 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+// Mock passkey service for testing.
+type mockPasskeyService struct {
+	err error
+}
+
+func (m *mockPasskeyService) BeginRegistration(
+	_ context.Context, _ uuid.UUID, _ *models.PasskeyOptionsRequest,
+) (*protocol.CredentialCreation, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &protocol.CredentialCreation{}, nil
+}
+
+func (m *mockPasskeyService) FinishRegistration(
+	_ context.Context, _ uuid.UUID, req *models.RegisterPasskeyRequest,
+) (*models.UserCredential, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.UserCredential{ID: uuid.New(), Name: req.Name}, nil
+}
+
+func (m *mockPasskeyService) ListPasskeys(_ context.Context, _ uuid.UUID) ([]models.UserCredential, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []models.UserCredential{{ID: uuid.New(), Name: "Pixel 9"}}, nil
+}
+
+func (m *mockPasskeyService) DeletePasskey(_ context.Context, _, _ uuid.UUID) error {
+	return m.err
+}
+
+// newPasskeyRouter mounts the passkey management handlers, behind principal unless it is nil.
+func newPasskeyRouter(svc *mockPasskeyService, principal *helpers.Principal) http.Handler {
+	handler := &Passkey{PasskeyServices: svc}
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if principal != nil {
+				r = r.WithContext(helpers.WithPrincipal(r.Context(), principal))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Get("/api/v1/users/me/passkeys", handler.ListHandlerHTTP)
+	r.Delete("/api/v1/users/me/passkeys/{id}", handler.DeleteHandlerHTTP)
+	return r
+}
+
+func TestPasskey_RegistrationOptionsHandlerHTTP(t *testing.T) {
+	const body = `{"password":"password123"}`
+	principal := &helpers.Principal{UserID: uuid.New()}
+
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", principal: principal, body: body, wantStatus: http.StatusOK},
+		{name: "missing principal", body: body, wantStatus: http.StatusUnauthorized},
+		{name: "malformed body", principal: principal, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "wrong password", principal: principal, body: body, err: services.ErrIncorrectPassword, wantStatus: http.StatusBadRequest},
+		{name: "invalid code", principal: principal, body: body, err: services.ErrInvalidMFACode, wantStatus: http.StatusBadRequest},
+		{name: "user not found", principal: principal, body: body, err: services.ErrUserNotFound, wantStatus: http.StatusNotFound},
+		{name: "service error", principal: principal, body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &Passkey{PasskeyServices: &mockPasskeyService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/passkeys/options", strings.NewReader(tt.body))
+			if tt.principal != nil {
+				req = req.WithContext(helpers.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.RegistrationOptionsHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestPasskey_RegisterHandlerHTTP(t *testing.T) {
+	const body = `{"name":"Pixel 9","credential":{"id":"abc"}}`
+	principal := &helpers.Principal{UserID: uuid.New()}
+
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", principal: principal, body: body, wantStatus: http.StatusCreated},
+		{name: "missing principal", body: body, wantStatus: http.StatusUnauthorized},
+		{name: "malformed body", principal: principal, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", principal: principal, body: body, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "invalid passkey", principal: principal, body: body, err: services.ErrInvalidPasskey, wantStatus: http.StatusBadRequest},
+		{name: "user not found", principal: principal, body: body, err: services.ErrUserNotFound, wantStatus: http.StatusNotFound},
+		{name: "service error", principal: principal, body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &Passkey{PasskeyServices: &mockPasskeyService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/passkeys", strings.NewReader(tt.body))
+			if tt.principal != nil {
+				req = req.WithContext(helpers.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.RegisterHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestPasskey_ManagementHandlers(t *testing.T) {
+	principal := &helpers.Principal{UserID: uuid.New()}
+	listPath := "/api/v1/users/me/passkeys"
+	passkeyPath := "/api/v1/users/me/passkeys/" + uuid.NewString()
+
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "list", principal: principal, method: http.MethodGet, path: listPath, wantStatus: http.StatusOK},
+		{name: "list missing principal", method: http.MethodGet, path: listPath, wantStatus: http.StatusUnauthorized},
+		{
+			name:       "list error",
+			principal:  principal,
+			method:     http.MethodGet,
+			path:       listPath,
+			err:        errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+		{name: "delete", principal: principal, method: http.MethodDelete, path: passkeyPath, wantStatus: http.StatusOK},
+		{name: "delete missing principal", method: http.MethodDelete, path: passkeyPath, wantStatus: http.StatusUnauthorized},
+		{
+			name:       "delete invalid id",
+			principal:  principal,
+			method:     http.MethodDelete,
+			path:       listPath + "/123",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "delete not found",
+			principal:  principal,
+			method:     http.MethodDelete,
+			path:       passkeyPath,
+			err:        services.ErrPasskeyNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "delete error",
+			principal:  principal,
+			method:     http.MethodDelete,
+			path:       passkeyPath,
+			err:        errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			newPasskeyRouter(&mockPasskeyService{err: tt.err}, tt.principal).ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
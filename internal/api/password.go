@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+type Password struct {
+	PasswordServices interfaces.IPasswordServices
+}
+
+func (api *Password) ForgotPasswordHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.ForgotPasswordRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.PasswordServices.ForgotPassword(r.Context(), &req); err != nil {
+		helpers.SendError(w, r, "Failed to process password reset request", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "If the email is registered, a password reset email has been sent", http.StatusOK)
+}
+
+func (api *Password) ChangePasswordHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.PasswordServices.ChangePassword(r.Context(), principal.UserID, principal.SessionID, &req); err != nil {
+		helpers.SendError(w, r, "Failed to change password", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Password changed successfully", http.StatusOK)
+}
+
+func (api *Password) ResetPasswordHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.ResetPasswordRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.PasswordServices.ResetPassword(r.Context(), &req); err != nil {
+		helpers.SendError(w, r, "Failed to reset password", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Password reset successfully", http.StatusOK)
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+// Mock password service for testing.
+type mockPasswordService struct {
+	err error
+}
+
+func (m *mockPasswordService) ForgotPassword(_ context.Context, _ *models.ForgotPasswordRequest) error {
+	return m.err
+}
+
+func (m *mockPasswordService) ResetPassword(_ context.Context, _ *models.ResetPasswordRequest) error {
+	return m.err
+}
+
+func (m *mockPasswordService) ChangePassword(_ context.Context, _, _ uuid.UUID, _ *models.ChangePasswordRequest) error {
+	return m.err
+}
+
+func TestPassword_ForgotPasswordHandlerHTTP(t *testing.T) {
+	tests := []struct {
+		err        error
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", body: `{"email":"john@example.com"}`, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `{"email":`, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", body: `{"email":"x"}`, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "service error", body: `{"email":"john@example.com"}`, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &Password{PasswordServices: &mockPasswordService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/password/forgot", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ForgotPasswordHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestPassword_ResetPasswordHandlerHTTP(t *testing.T) {
+	const body = `{"token":"abc","password":"new-password"}`
+
+	tests := []struct {
+		err        error
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", body: body, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `{"token":`, wantStatus: http.StatusBadRequest},
+		{name: "invalid token", body: body, err: services.ErrInvalidResetToken, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", body: `{"token":"abc"}`, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "service error", body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &Password{PasswordServices: &mockPasswordService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/password/reset", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ResetPasswordHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestPassword_ChangePasswordHandlerHTTP(t *testing.T) {
+	principal := &helpers.Principal{UserID: uuid.New(), SessionID: uuid.New()}
+	const body = `{"current_password":"password123","new_password":"new-password123"}`
+
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", principal: principal, body: body, wantStatus: http.StatusOK},
+		{name: "missing principal", body: body, wantStatus: http.StatusUnauthorized},
+		{name: "malformed body", principal: principal, body: `{"current_password":`, wantStatus: http.StatusBadRequest},
+		{name: "wrong current password", principal: principal, body: body, err: services.ErrIncorrectPassword, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", principal: principal, body: `{}`, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "service error", principal: principal, body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &Password{PasswordServices: &mockPasswordService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/password", strings.NewReader(tt.body))
+			if tt.principal != nil {
+				req = req.WithContext(helpers.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ChangePasswordHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
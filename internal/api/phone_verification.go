@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+type PhoneVerification struct {
+	PhoneVerificationServices interfaces.IPhoneVerificationServices
+}
+
+func (api *PhoneVerification) RequestOTPHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.RequestPhoneOTPRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.PhoneVerificationServices.RequestOTP(r.Context(), &req); err != nil {
+		helpers.SendError(w, r, "Failed to send verification code", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "If the phone number is registered and unverified, a verification code has been sent", http.StatusOK)
+}
+
+func (api *PhoneVerification) VerifyOTPHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.VerifyPhoneOTPRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.PhoneVerificationServices.VerifyOTP(r.Context(), &req); err != nil {
+		helpers.SendError(w, r, "Failed to verify phone number", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Phone number verified successfully", http.StatusOK)
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+// Mock phone verification service for testing.
+type mockPhoneVerificationService struct {
+	err error
+}
+
+func (m *mockPhoneVerificationService) RequestOTP(_ context.Context, _ *models.RequestPhoneOTPRequest) error {
+	return m.err
+}
+
+func (m *mockPhoneVerificationService) VerifyOTP(_ context.Context, _ *models.VerifyPhoneOTPRequest) error {
+	return m.err
+}
+
+func TestPhoneVerification_RequestOTPHandlerHTTP(t *testing.T) {
+	rateLimited := &domain.RetryAfterError{Err: services.ErrTooManyOTPRequests, RetryAfter: 90 * time.Second}
+
+	tests := []struct {
+		err            error
+		name           string
+		body           string
+		wantRetryAfter string
+		wantStatus     int
+	}{
+		{name: "success", body: `{"phone":"+6281234567890"}`, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `{"phone":`, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", body: `{"phone":"0812"}`, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{
+			name:           "rate limited",
+			body:           `{"phone":"+6281234567890"}`,
+			err:            rateLimited,
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "90",
+		},
+		{name: "service error", body: `{"phone":"+6281234567890"}`, err: errors.New("sms down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &PhoneVerification{PhoneVerificationServices: &mockPhoneVerificationService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/phone/otp", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.RequestOTPHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tt.wantRetryAfter, got)
+			}
+		})
+	}
+}
+
+func TestPhoneVerification_VerifyOTPHandlerHTTP(t *testing.T) {
+	const body = `{"phone":"+6281234567890","code":"123456"}`
+
+	tests := []struct {
+		err        error
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", body: body, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `{"phone":`, wantStatus: http.StatusBadRequest},
+		{name: "invalid code", body: body, err: services.ErrInvalidOTP, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", body: `{"phone":"+6281234567890"}`, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "service error", body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &PhoneVerification{PhoneVerificationServices: &mockPhoneVerificationService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/phone/verify", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.VerifyOTPHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+type PIN struct {
+	PINServices interfaces.IPINServices
+}
+
+func (api *PIN) SetPINHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.SetPINRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.PINServices.SetPIN(r.Context(), principal.UserID, &req); err != nil {
+		helpers.SendError(w, r, "Failed to set transaction PIN", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Transaction PIN set successfully", http.StatusOK)
+}
+
+func (api *PIN) ChangePINHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ChangePINRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.PINServices.ChangePIN(r.Context(), principal.UserID, &req); err != nil {
+		helpers.SendError(w, r, "Failed to change transaction PIN", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Transaction PIN changed successfully", http.StatusOK)
+}
+
+func (api *PIN) RequestPINResetHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	if err := api.PINServices.RequestPINReset(r.Context(), principal.UserID); err != nil {
+		helpers.SendError(w, r, "Failed to send PIN reset code", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "PIN reset code sent", http.StatusOK)
+}
+
+func (api *PIN) ResetPINHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ResetPINRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.PINServices.ResetPIN(r.Context(), principal.UserID, &req); err != nil {
+		helpers.SendError(w, r, "Failed to reset transaction PIN", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Transaction PIN reset successfully", http.StatusOK)
+}
+
+func (api *PIN) VerifyPINHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.VerifyPINRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	result, err := api.PINServices.VerifyPIN(r.Context(), &req)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to verify transaction PIN", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, result, "Transaction PIN checked", http.StatusOK)
+}
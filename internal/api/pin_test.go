@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+// Mock transaction PIN service for testing.
+type mockPINService struct {
+	err error
+}
+
+func (m *mockPINService) SetPIN(_ context.Context, _ uuid.UUID, _ *models.SetPINRequest) error {
+	return m.err
+}
+
+func (m *mockPINService) ChangePIN(_ context.Context, _ uuid.UUID, _ *models.ChangePINRequest) error {
+	return m.err
+}
+
+func (m *mockPINService) RequestPINReset(_ context.Context, _ uuid.UUID) error {
+	return m.err
+}
+
+func (m *mockPINService) ResetPIN(_ context.Context, _ uuid.UUID, _ *models.ResetPINRequest) error {
+	return m.err
+}
+
+func (m *mockPINService) VerifyPIN(_ context.Context, _ *models.VerifyPINRequest) (*models.PINVerificationResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.PINVerificationResponse{Valid: true, AttemptsRemaining: 3}, nil
+}
+
+func TestPIN_SetPINHandlerHTTP(t *testing.T) {
+	principal := &helpers.Principal{UserID: uuid.New(), SessionID: uuid.New()}
+	const body = `{"pin":"582931","password":"password123"}`
+
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", principal: principal, body: body, wantStatus: http.StatusOK},
+		{name: "missing principal", body: body, wantStatus: http.StatusUnauthorized},
+		{name: "malformed body", principal: principal, body: `{"pin":`, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", principal: principal, body: `{}`, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "wrong password", principal: principal, body: body, err: services.ErrIncorrectPassword, wantStatus: http.StatusBadRequest},
+		{name: "already set", principal: principal, body: body, err: services.ErrPINAlreadySet, wantStatus: http.StatusConflict},
+		{name: "service error", principal: principal, body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &PIN{PINServices: &mockPINService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/pin", strings.NewReader(tt.body))
+			if tt.principal != nil {
+				req = req.WithContext(helpers.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.SetPINHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestPIN_ChangePINHandlerHTTP(t *testing.T) {
+	principal := &helpers.Principal{UserID: uuid.New(), SessionID: uuid.New()}
+	const body = `{"current_pin":"582931","new_pin":"740316"}`
+	locked := &domain.RetryAfterError{Err: services.ErrPINLocked, RetryAfter: 30 * time.Minute}
+
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", principal: principal, body: body, wantStatus: http.StatusOK},
+		{name: "missing principal", body: body, wantStatus: http.StatusUnauthorized},
+		{name: "wrong current PIN", principal: principal, body: body, err: services.ErrIncorrectPIN, wantStatus: http.StatusBadRequest},
+		{name: "PIN locked", principal: principal, body: body, err: locked, wantStatus: http.StatusTooManyRequests},
+		{name: "PIN not set", principal: principal, body: body, err: services.ErrPINNotSet, wantStatus: http.StatusConflict},
+		{name: "service error", principal: principal, body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &PIN{PINServices: &mockPINService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/pin/change", strings.NewReader(tt.body))
+			if tt.principal != nil {
+				req = req.WithContext(helpers.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ChangePINHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestPIN_ResetPINHandlerHTTP(t *testing.T) {
+	principal := &helpers.Principal{UserID: uuid.New(), SessionID: uuid.New()}
+	const body = `{"code":"123456","new_pin":"740316"}`
+
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", principal: principal, body: body, wantStatus: http.StatusOK},
+		{name: "missing principal", body: body, wantStatus: http.StatusUnauthorized},
+		{name: "invalid code", principal: principal, body: body, err: services.ErrInvalidOTP, wantStatus: http.StatusBadRequest},
+		{name: "phone not verified", principal: principal, body: body, err: services.ErrPhoneNotVerified, wantStatus: http.StatusForbidden},
+		{name: "service error", principal: principal, body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &PIN{PINServices: &mockPINService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/pin/reset", strings.NewReader(tt.body))
+			if tt.principal != nil {
+				req = req.WithContext(helpers.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ResetPINHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestPIN_VerifyPINHandlerHTTP(t *testing.T) {
+	body := `{"user_id":"` + uuid.NewString() + `","pin":"582931"}`
+
+	tests := []struct {
+		err        error
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", body: body, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `{"user_id":`, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", body: `{}`, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "unknown user", body: body, err: services.ErrUserNotFound, wantStatus: http.StatusNotFound},
+		{name: "inactive user", body: body, err: services.ErrUserInactive, wantStatus: http.StatusForbidden},
+		{name: "PIN not set", body: body, err: services.ErrPINNotSet, wantStatus: http.StatusConflict},
+		{name: "service error", body: body, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &PIN{PINServices: &mockPINService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/internal/pin/verify", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.VerifyPINHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
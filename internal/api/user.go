@@ -0,0 +1,98 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+type User struct {
+	UserServices interfaces.IUserServices
+}
+
+func (api *User) RegisterHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateUserRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := api.UserServices.Register(r.Context(), &req)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to register user", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, user, "User registered successfully", http.StatusCreated)
+}
+
+func (api *User) GetMeHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	user, err := api.UserServices.GetProfile(r.Context(), principal.UserID)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to get profile", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, user, "Profile retrieved successfully", http.StatusOK)
+}
+
+func (api *User) UpdateMeHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := helpers.PrincipalFromContext(r.Context())
+	if !ok {
+		helpers.SendErrorResponse(w, r, "Unauthorized", services.ErrInvalidAccessToken, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.UpdateUserRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := api.UserServices.UpdateProfile(r.Context(), principal.UserID, &req)
+	if err != nil {
+		helpers.SendError(w, r, "Failed to update profile", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, user, "Profile updated successfully", http.StatusOK)
+}
+
+func (api *User) VerifyEmailHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.VerifyEmailRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.UserServices.VerifyEmail(r.Context(), &req); err != nil {
+		helpers.SendError(w, r, "Failed to verify email", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "Email verified successfully", http.StatusOK)
+}
+
+func (api *User) ResendEmailVerificationHandlerHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.ResendEmailVerificationRequest
+	if err := helpers.DecodeJSON(w, r, &req); err != nil {
+		helpers.SendErrorResponse(w, r, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := api.UserServices.ResendEmailVerification(r.Context(), &req); err != nil {
+		helpers.SendError(w, r, "Failed to resend verification email", err)
+		return
+	}
+
+	helpers.SendResponse(w, r, nil, "If the email is registered and unverified, a verification email has been sent", http.StatusOK)
+}
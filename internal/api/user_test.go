@@ -0,0 +1,275 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+	"github.com/ibnuzaman/ewallet-ums/internal/services"
+)
+
+// Mock service for testing.
+type mockUserService struct {
+	err error
+}
+
+func (m *mockUserService) Register(_ context.Context, req *models.CreateUserRequest) (*models.User, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.User{ID: uuid.New(), Email: req.Email, Phone: req.Phone, FullName: req.FullName}, nil
+}
+
+func (m *mockUserService) VerifyEmail(_ context.Context, _ *models.VerifyEmailRequest) error {
+	return m.err
+}
+
+func (m *mockUserService) ResendEmailVerification(_ context.Context, _ *models.ResendEmailVerificationRequest) error {
+	return m.err
+}
+
+func (m *mockUserService) GetProfile(_ context.Context, userID uuid.UUID) (*models.User, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.User{ID: userID, Email: "john@example.com"}, nil
+}
+
+func (m *mockUserService) UpdateProfile(_ context.Context, userID uuid.UUID, req *models.UpdateUserRequest) (*models.User, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	user := &models.User{ID: userID, Email: "john@example.com"}
+	if req.FullName != nil {
+		user.FullName = *req.FullName
+	}
+	return user, nil
+}
+
+const registerBody = `{"email":"john@example.com","phone":"+6281234567890","full_name":"John Doe","password":"password123"}`
+
+func TestUser_RegisterHandlerHTTP(t *testing.T) {
+	tests := []struct {
+		err        error
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", body: registerBody, wantStatus: http.StatusCreated},
+		{name: "malformed body", body: `{"email":`, wantStatus: http.StatusBadRequest},
+		{name: "unknown field", body: `{"username":"john"}`, wantStatus: http.StatusBadRequest},
+		{
+			name: "validation failure",
+			body: registerBody,
+			err: &helpers.ValidationError{Fields: []helpers.FieldError{
+				{Field: "email", Message: "must be a valid email address"},
+			}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{name: "duplicate email", body: registerBody, err: services.ErrEmailAlreadyRegistered, wantStatus: http.StatusConflict},
+		{name: "duplicate phone", body: registerBody, err: services.ErrPhoneAlreadyRegistered, wantStatus: http.StatusConflict},
+		{name: "service error", body: registerBody, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &User{UserServices: &mockUserService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/register", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.RegisterHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestUser_RegisterHandlerHTTP_ValidationDetails(t *testing.T) {
+	// Arrange
+	validationErr := &helpers.ValidationError{Fields: []helpers.FieldError{
+		{Field: "phone", Message: "is required"},
+	}}
+	handler := &User{UserServices: &mockUserService{err: validationErr}}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/register", strings.NewReader(registerBody))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.RegisterHandlerHTTP(w, req)
+
+	// Assert
+	var resp helpers.ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Field != "phone" {
+		t.Errorf("Expected field-level error for phone, got %+v", resp.Errors)
+	}
+}
+
+func TestUser_GetMeHandlerHTTP(t *testing.T) {
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		wantStatus int
+	}{
+		{name: "success", principal: &helpers.Principal{UserID: uuid.New()}, wantStatus: http.StatusOK},
+		{name: "missing principal", wantStatus: http.StatusUnauthorized},
+		{
+			name:       "service error",
+			principal:  &helpers.Principal{UserID: uuid.New()},
+			err:        errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &User{UserServices: &mockUserService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", http.NoBody)
+			if tt.principal != nil {
+				req = req.WithContext(helpers.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.GetMeHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+			if strings.Contains(w.Body.String(), "password") {
+				t.Error("Expected response to hide the password hash")
+			}
+		})
+	}
+}
+
+func TestUser_UpdateMeHandlerHTTP(t *testing.T) {
+	principal := &helpers.Principal{UserID: uuid.New()}
+
+	tests := []struct {
+		err        error
+		principal  *helpers.Principal
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", principal: principal, body: `{"full_name":"Jane Doe"}`, wantStatus: http.StatusOK},
+		{name: "missing principal", body: `{"full_name":"Jane Doe"}`, wantStatus: http.StatusUnauthorized},
+		{name: "unknown field", principal: principal, body: `{"email":"jane@example.com"}`, wantStatus: http.StatusBadRequest},
+		{
+			name:       "validation failure",
+			principal:  principal,
+			body:       `{"phone":"0812"}`,
+			err:        &helpers.ValidationError{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "duplicate phone",
+			principal:  principal,
+			body:       `{"phone":"+6281234567890"}`,
+			err:        services.ErrPhoneAlreadyRegistered,
+			wantStatus: http.StatusConflict,
+		},
+		{name: "service error", principal: principal, body: `{}`, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &User{UserServices: &mockUserService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/users/me", strings.NewReader(tt.body))
+			if tt.principal != nil {
+				req = req.WithContext(helpers.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.UpdateMeHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestUser_VerifyEmailHandlerHTTP(t *testing.T) {
+	tests := []struct {
+		err        error
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", body: `{"token":"abc"}`, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `{"token":`, wantStatus: http.StatusBadRequest},
+		{name: "invalid token", body: `{"token":"abc"}`, err: services.ErrInvalidVerificationToken, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", body: `{}`, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "service error", body: `{"token":"abc"}`, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &User{UserServices: &mockUserService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/verify-email", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.VerifyEmailHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestUser_ResendEmailVerificationHandlerHTTP(t *testing.T) {
+	tests := []struct {
+		err        error
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "success", body: `{"email":"john@example.com"}`, wantStatus: http.StatusOK},
+		{name: "unknown field", body: `{"phone":"+6281234567890"}`, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", body: `{"email":"x"}`, err: &helpers.ValidationError{}, wantStatus: http.StatusBadRequest},
+		{name: "service error", body: `{"email":"john@example.com"}`, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := &User{UserServices: &mockUserService{err: tt.err}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/verify-email/resend", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ResendEmailVerificationHandlerHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
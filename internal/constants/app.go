@@ -16,4 +16,115 @@ const (
 	DefaultConnMaxLifetime = 5 * time.Minute
 	DefaultConnMaxIdleTime = 5 * time.Minute
 	DefaultPingTimeout     = 5 * time.Second
+	MaxRequestBodySize     = 1 << 20
+	DefaultPageSize        = 20
+	MaxPageSize            = 100
+
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+	DefaultTokenIssuer     = "ewallet-ums"
+	TokenTypeBearer        = "Bearer"
+	JWKSCacheMaxAge        = 5 * time.Minute
+
+	SecurityEventRefreshTokenReuse    = "refresh_token_reuse"
+	SecurityEventOTPAttemptsExhausted = "otp_attempts_exhausted"
+	SecurityEventLoginFailed          = "login_failed"
+	SecurityEventAccountLocked        = "account_locked"
+	SecurityEventAccountHardLocked    = "account_hard_locked"
+	SecurityEventAccountUnlocked      = "account_unlocked"
+	SecurityEventLoginIPBlocked       = "login_ip_blocked"
+	SecurityEventMFAEnabled           = "mfa_enabled"
+	SecurityEventMFADisabled          = "mfa_disabled"
+	SecurityEventRecoveryCodesRenewed = "mfa_recovery_codes_renewed"
+	SecurityEventRecoveryCodeUsed     = "mfa_recovery_code_used"
+	SecurityEventPasskeyRegistered    = "passkey_registered"
+	SecurityEventPasskeyDeleted       = "passkey_deleted"
+	SecurityEventPasskeyCloneWarning  = "passkey_clone_warning"
+	SecurityEventPINChanged           = "pin_changed"
+	SecurityEventPINFailed            = "pin_failed"
+	SecurityEventPINLocked            = "pin_locked"
+	SecurityEventNewDeviceLogin       = "new_device_login"
+	SecurityEventDeviceTrusted        = "device_trusted"
+	SecurityEventDeviceRevoked        = "device_revoked"
+	SecurityEventSuspiciousLogin      = "suspicious_login"
+
+	TokenPurposeEmailVerification          = "email_verification"
+	DefaultEmailVerificationTTL            = 24 * time.Hour
+	DefaultEmailVerificationResendCooldown = time.Minute
+
+	DefaultPasswordMinLength = 8
+
+	DefaultPasswordHashMemory      = 64 * 1024 // KiB
+	DefaultPasswordHashTime        = 3
+	DefaultPasswordHashParallelism = 2
+
+	TokenPurposePasswordReset    = "password_reset"
+	DefaultPasswordResetTTL      = time.Hour
+	DefaultPasswordResetCooldown = time.Minute
+
+	DefaultLoginMaxFailedAttempts  = 5
+	DefaultLoginLockoutDuration    = time.Minute
+	DefaultLoginLockoutMaxDuration = time.Hour
+	DefaultLoginMaxLockouts        = 3
+	DefaultLoginIPMaxFailures      = 20
+	LoginIPFailureWindow           = 15 * time.Minute
+
+	LoginRiskHistorySize           = 50
+	LoginRiskFlagNewDevice         = "new_device"
+	LoginRiskFlagImpossibleTravel  = "impossible_travel"
+	LoginRiskFlagDenylistedIP      = "denylisted_ip"
+	LoginRiskScoreNewDevice        = 30
+	LoginRiskScoreImpossibleTravel = 60
+	LoginRiskScoreDenylistedIP     = 80
+	LoginRiskMaxScore              = 100
+	DefaultLoginMaxTravelSpeed     = 1000 // km/h
+	LoginTravelMinDistance         = 500  // km
+
+	TokenPurposeMFALogin   = "mfa_login"
+	DefaultMFAChallengeTTL = 5 * time.Minute
+	DefaultTOTPIssuer      = "E-Wallet"
+	MFARecoveryCodeCount   = 10
+	MFAMethodTOTP          = "totp"
+	MFAMethodRecoveryCode  = "recovery_code"
+	MFAMethodPasskey       = "passkey"
+	MFAMethodSMS           = "sms"
+	MFAReasonMFAEnabled    = "mfa_enabled"
+	MFAReasonNewDevice     = "new_device"
+
+	WebAuthnPurposeRegistration = "registration"
+	WebAuthnPurposeLogin        = "login"
+	WebAuthnPurposeMFA          = "mfa"
+	DefaultPasskeyName          = "Passkey"
+
+	PhoneOTPLength              = 6
+	DefaultPhoneOTPTTL          = 5 * time.Minute
+	DefaultPhoneOTPMaxAttempts  = 5
+	DefaultOTPPhoneRateLimit    = 5
+	DefaultOTPIPRateLimit       = 20
+	OTPRateLimitWindow          = time.Hour
+	OTPPurposePhoneVerification = "phone_verification"
+	OTPPurposePINReset          = "pin_reset"
+	OTPPurposeLoginVerification = "login_verification"
+
+	PINLength                   = 6
+	DefaultPINMaxFailedAttempts = 3
+	DefaultPINLockoutDuration   = 30 * time.Minute
+)
+
+// Roles seeded by the RBAC migration.
+const (
+	RoleCustomer = "customer"
+	RoleMerchant = "merchant"
+	RoleSupport  = "support"
+	RoleAdmin    = "admin"
+	RoleService  = "service"
+)
+
+// Permissions granted through roles and carried in access tokens.
+const (
+	PermissionUsersRead       = "users:read"
+	PermissionUsersWrite      = "users:write"
+	PermissionUsersDeactivate = "users:deactivate"
+	PermissionUsersDelete     = "users:delete"
+	PermissionSessionsRevoke  = "sessions:revoke"
 )
@@ -0,0 +1,67 @@
+// Package domain holds the error kinds shared by the repository, service and API layers.
+// Repositories and services return errors of these kinds, and handlers turn them into
+// HTTP responses with helpers.SendError instead of comparing error messages.
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrNotFound is the kind of errors for records that do not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is the kind of errors for changes that clash with existing data, such as a
+	// unique email address that is already taken.
+	ErrConflict = errors.New("conflict")
+
+	// ErrValidation is the kind of errors for requests that are malformed or carry wrong values.
+	ErrValidation = errors.New("validation failed")
+
+	// ErrUnauthorized is the kind of errors for missing, wrong or expired credentials.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrForbidden is the kind of errors for callers whose credentials are valid but who may
+	// not perform the action.
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrLocked is the kind of errors for accounts locked until the user takes action.
+	ErrLocked = errors.New("locked")
+)
+
+// Error is an error of one of the kinds above. Its message is meant to be shown to clients,
+// so it must not contain internal details.
+type Error struct {
+	kind    error
+	message string
+}
+
+// NewError returns an error of the given kind with a message for clients.
+func NewError(kind error, message string) error {
+	return &Error{kind: kind, message: message}
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+// Unwrap returns the kind, so that errors.Is(err, ErrNotFound) matches every not found error.
+func (e *Error) Unwrap() error {
+	return e.kind
+}
+
+// RetryAfterError wraps an error for a rate limited request with the time until it may be retried.
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v, retry after %s", e.Err, e.RetryAfter)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
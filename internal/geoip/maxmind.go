@@ -0,0 +1,71 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// cityRecord holds the fields read from a GeoLite2 or GeoIP2 City database record.
+type cityRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Location struct {
+		Latitude       *float64 `maxminddb:"latitude"`
+		Longitude      *float64 `maxminddb:"longitude"`
+		AccuracyRadius int      `maxminddb:"accuracy_radius"`
+	} `maxminddb:"location"`
+}
+
+// MaxMind is an IGeoLocator backed by a local MaxMind City database file, so lookups never
+// leave the server.
+type MaxMind struct {
+	reader *maxminddb.Reader
+}
+
+// OpenMaxMind opens the MaxMind City database at path.
+func OpenMaxMind(path string) (*MaxMind, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	return &MaxMind{reader: reader}, nil
+}
+
+// Locate returns the location of an IP address, or nil when the database has no
+// coordinates for it.
+func (m *MaxMind) Locate(ip string) (*models.GeoLocation, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return nil, nil
+	}
+
+	var record cityRecord
+	_, ok, err := m.reader.LookupNetwork(addr, &record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up IP location: %w", err)
+	}
+	if !ok || record.Location.Latitude == nil || record.Location.Longitude == nil {
+		return nil, nil
+	}
+
+	return &models.GeoLocation{
+		Country:        record.Country.ISOCode,
+		City:           record.City.Names["en"],
+		Latitude:       *record.Location.Latitude,
+		Longitude:      *record.Location.Longitude,
+		AccuracyRadius: record.Location.AccuracyRadius,
+	}, nil
+}
+
+// Close releases the database file.
+func (m *MaxMind) Close() error {
+	return m.reader.Close()
+}
@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IAdminUserServices defines the interface for user management by support staff.
+type IAdminUserServices interface {
+	ListUsers(ctx context.Context, filter models.UserFilter) (*models.UserListResponse, error)
+	GetUser(ctx context.Context, id uuid.UUID) (*models.User, error)
+	UpdateUser(ctx context.Context, id uuid.UUID, req *models.AdminUpdateUserRequest) (*models.User, error)
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+	RevokeSessions(ctx context.Context, id uuid.UUID) error
+	UnlockUser(ctx context.Context, id uuid.UUID) (*models.User, error)
+}
+
+// IAdminUserAPI defines the interface for admin user API handler.
+type IAdminUserAPI interface {
+	ListUsersHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	GetUserHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	UpdateUserHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	DeleteUserHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	RevokeSessionsHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	UnlockUserHandlerHTTP(w http.ResponseWriter, r *http.Request)
+}
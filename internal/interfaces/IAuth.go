@@ -0,0 +1,39 @@
+package interfaces
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IAuthServices defines the interface for authentication service.
+type IAuthServices interface {
+	Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error)
+	CompleteMFALogin(ctx context.Context, req *models.LoginMFARequest) (*models.TokenResponse, error)
+	BeginMFAPasskey(ctx context.Context, req *models.MFAPasskeyOptionsRequest) (*protocol.CredentialAssertion, error)
+	BeginPasskeyLogin(ctx context.Context) (*protocol.CredentialAssertion, error)
+	FinishPasskeyLogin(ctx context.Context, req *models.PasskeyLoginRequest) (*models.TokenResponse, error)
+	Refresh(ctx context.Context, req *models.RefreshTokenRequest) (*models.TokenResponse, error)
+	Authenticate(ctx context.Context, accessToken string) (*models.UserSession, error)
+	Logout(ctx context.Context, sessionID uuid.UUID) error
+	LogoutAll(ctx context.Context, userID uuid.UUID) error
+	ValidateToken(ctx context.Context, accessToken string) (*models.TokenValidationResponse, error)
+}
+
+// IAuthAPI defines the interface for authentication API handler.
+type IAuthAPI interface {
+	LoginHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	LoginMFAHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	MFAPasskeyOptionsHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	PasskeyLoginOptionsHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	PasskeyLoginHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	RefreshHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	LogoutHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	LogoutAllHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	ValidateTokenHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	JWKSHandlerHTTP(w http.ResponseWriter, r *http.Request)
+}
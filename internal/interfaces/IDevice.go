@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IDeviceServices defines the interface for session and device management service.
+type IDeviceServices interface {
+	ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) (*models.SessionListResponse, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeDevice(ctx context.Context, userID, deviceID uuid.UUID) error
+}
+
+// IDeviceAPI defines the interface for session and device management API handler.
+type IDeviceAPI interface {
+	ListSessionsHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	RevokeSessionHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	RevokeDeviceHandlerHTTP(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IDeviceRepository defines the interface for the known devices of users.
+type IDeviceRepository interface {
+	// Upsert stores a device or records another login from it
+	Upsert(ctx context.Context, device *models.UserDevice) error
+
+	// GetByDeviceIDHash retrieves a device of a user by the hash of its device ID
+	GetByDeviceIDHash(ctx context.Context, userID uuid.UUID, deviceIDHash string) (*models.UserDevice, error)
+
+	// CountByUserID counts the devices of a user
+	CountByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+
+	// ListByUserID retrieves the devices of a user, most recently seen first
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UserDevice, error)
+
+	// Delete deletes a device of a user
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+}
@@ -0,0 +1,9 @@
+package interfaces
+
+import "github.com/ibnuzaman/ewallet-ums/internal/models"
+
+// IGeoLocator looks up the approximate location of IP addresses.
+type IGeoLocator interface {
+	// Locate returns the location of an IP address, or nil when it is unknown
+	Locate(ip string) (*models.GeoLocation, error)
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// ILoginRiskAssessor checks new login sessions for signs of account takeover.
+type ILoginRiskAssessor interface {
+	// AssessLogin compares a session that was just created with the earlier sessions of its
+	// user, and alerts the user when the login looks suspicious
+	AssessLogin(ctx context.Context, user *models.User, session *models.UserSession) (*models.LoginRiskAssessment, error)
+}
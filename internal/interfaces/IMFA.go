@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IMFAServices defines the interface for multi-factor authentication service.
+type IMFAServices interface {
+	EnrollTOTP(ctx context.Context, userID uuid.UUID, req *models.EnrollTOTPRequest) (*models.TOTPEnrollmentResponse, error)
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, req *models.ConfirmTOTPRequest) (*models.RecoveryCodesResponse, error)
+	DisableTOTP(ctx context.Context, userID uuid.UUID, req *models.MFACodeRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, req *models.MFACodeRequest) (*models.RecoveryCodesResponse, error)
+}
+
+// IMFAAPI defines the interface for multi-factor authentication API handler.
+type IMFAAPI interface {
+	EnrollTOTPHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	ConfirmTOTPHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	DisableTOTPHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	RegenerateRecoveryCodesHandlerHTTP(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,35 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IMFARepository defines the interface for TOTP authenticator and recovery code operations.
+type IMFARepository interface {
+	// SavePendingTOTP stores a new unconfirmed TOTP secret, replacing an earlier unconfirmed one
+	SavePendingTOTP(ctx context.Context, userID uuid.UUID, secretEncrypted string) error
+
+	// GetTOTP retrieves the TOTP authenticator of a user
+	GetTOTP(ctx context.Context, userID uuid.UUID) (*models.UserTOTP, error)
+
+	// ConfirmTOTP confirms a pending TOTP authenticator, enables MFA for the user and
+	// replaces their recovery codes
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error
+
+	// UseTOTPStep records a used TOTP time step, failing for steps at or before the last one
+	UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error
+
+	// UseRecoveryCode marks an unused recovery code of a user as used
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error
+
+	// ReplaceRecoveryCodes replaces the recovery codes of a user with a confirmed TOTP authenticator
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error
+
+	// DeleteTOTP removes the TOTP authenticator and recovery codes of a user, keeping MFA
+	// enabled only while they have a passkey
+	DeleteTOTP(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// INotifier delivers account messages to users, e.g. by email.
+// Implementations receive the raw single-use token and are responsible for
+// building the link or message around it.
+type INotifier interface {
+	SendEmailVerification(ctx context.Context, user *models.User, token string) error
+	SendPasswordReset(ctx context.Context, user *models.User, token string) error
+	// SendLoginAlert warns the user about a suspicious login to their account
+	SendLoginAlert(ctx context.Context, user *models.User, assessment *models.LoginRiskAssessment) error
+}
@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IPINServices defines the interface for transaction PIN service.
+type IPINServices interface {
+	SetPIN(ctx context.Context, userID uuid.UUID, req *models.SetPINRequest) error
+	ChangePIN(ctx context.Context, userID uuid.UUID, req *models.ChangePINRequest) error
+	RequestPINReset(ctx context.Context, userID uuid.UUID) error
+	ResetPIN(ctx context.Context, userID uuid.UUID, req *models.ResetPINRequest) error
+	VerifyPIN(ctx context.Context, req *models.VerifyPINRequest) (*models.PINVerificationResponse, error)
+}
+
+// IPINAPI defines the interface for transaction PIN API handler.
+type IPINAPI interface {
+	SetPINHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	ChangePINHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	RequestPINResetHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	ResetPINHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	VerifyPINHandlerHTTP(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IPasskeyServices defines the interface for passkey registration and management service.
+type IPasskeyServices interface {
+	BeginRegistration(ctx context.Context, userID uuid.UUID, req *models.PasskeyOptionsRequest) (*protocol.CredentialCreation, error)
+	FinishRegistration(ctx context.Context, userID uuid.UUID, req *models.RegisterPasskeyRequest) (*models.UserCredential, error)
+	ListPasskeys(ctx context.Context, userID uuid.UUID) ([]models.UserCredential, error)
+	DeletePasskey(ctx context.Context, userID, id uuid.UUID) error
+}
+
+// IPasskeyAPI defines the interface for passkey registration and management API handler.
+type IPasskeyAPI interface {
+	RegistrationOptionsHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	RegisterHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	ListHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	DeleteHandlerHTTP(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IPasswordServices defines the interface for password service.
+type IPasswordServices interface {
+	ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error
+	ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, req *models.ChangePasswordRequest) error
+}
+
+// IPasswordAPI defines the interface for password API handler.
+type IPasswordAPI interface {
+	ForgotPasswordHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	ResetPasswordHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	ChangePasswordHandlerHTTP(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IPhoneOTPRepository defines the interface for phone OTP repository operations.
+type IPhoneOTPRepository interface {
+	// Create creates a new OTP
+	Create(ctx context.Context, otp *models.PhoneOTP) error
+
+	// GetActiveByPhone retrieves the most recent unconsumed, unexpired OTP with the given purpose for a phone number
+	GetActiveByPhone(ctx context.Context, purpose, phone string) (*models.PhoneOTP, error)
+
+	// RecordAttempt counts a verification attempt against an active OTP with attempts left
+	RecordAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) error
+
+	// Consume marks an active OTP as consumed
+	Consume(ctx context.Context, id uuid.UUID) error
+
+	// InvalidateByPhone marks every unconsumed OTP with the given purpose for a phone number as consumed
+	InvalidateByPhone(ctx context.Context, purpose, phone string) error
+}
@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IPhoneVerificationServices defines the interface for phone verification service.
+type IPhoneVerificationServices interface {
+	RequestOTP(ctx context.Context, req *models.RequestPhoneOTPRequest) error
+	VerifyOTP(ctx context.Context, req *models.VerifyPhoneOTPRequest) error
+}
+
+// IPhoneVerificationAPI defines the interface for phone verification API handler.
+type IPhoneVerificationAPI interface {
+	RequestOTPHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	VerifyOTPHandlerHTTP(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// IRoleRepository defines the interface for role and permission repository operations.
+type IRoleRepository interface {
+	// GetRolesByUserID retrieves the names of the roles granted to a user
+	GetRolesByUserID(ctx context.Context, userID uuid.UUID) ([]string, error)
+
+	// GetPermissionsByUserID retrieves the distinct permission names granted through the user's roles
+	GetPermissionsByUserID(ctx context.Context, userID uuid.UUID) ([]string, error)
+
+	// AssignRole grants a role to a user, doing nothing if it is already granted
+	AssignRole(ctx context.Context, userID uuid.UUID, roleName string) error
+
+	// RemoveRole revokes a role from a user
+	RemoveRole(ctx context.Context, userID uuid.UUID, roleName string) error
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "context"
+
+// ISMSProvider sends text messages to phone numbers in E.164 format.
+type ISMSProvider interface {
+	SendSMS(ctx context.Context, phone, message string) error
+}
@@ -0,0 +1,50 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// ISessionRepository defines the interface for user session repository operations.
+type ISessionRepository interface {
+	// Create creates a new session
+	Create(ctx context.Context, session *models.UserSession) error
+
+	// GetByAccessToken retrieves a session by access token hash, including revoked sessions
+	GetByAccessToken(ctx context.Context, accessTokenHash string) (*models.UserSession, error)
+
+	// GetByRefreshToken retrieves a session by refresh token hash, including revoked sessions
+	GetByRefreshToken(ctx context.Context, refreshTokenHash string) (*models.UserSession, error)
+
+	// GetByRotatedRefreshToken retrieves the session that previously used an already rotated refresh token hash
+	GetByRotatedRefreshToken(ctx context.Context, refreshTokenHash string) (*models.UserSession, error)
+
+	// Rotate replaces the tokens of a session and records the previous refresh token hash as rotated
+	Rotate(ctx context.Context, session *models.UserSession, previousRefreshTokenHash string) error
+
+	// Revoke revokes a single session
+	Revoke(ctx context.Context, id uuid.UUID) error
+
+	// RevokeAllByUserID revokes every active session of a user
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error
+
+	// RevokeOthersByUserID revokes every active session of a user except the given one
+	RevokeOthersByUserID(ctx context.Context, userID, keepSessionID uuid.UUID) error
+
+	// RevokeByDeviceID revokes every active session of a user created on the given device
+	RevokeByDeviceID(ctx context.Context, userID, deviceID uuid.UUID) error
+
+	// ListActiveByUserID retrieves unrevoked, unexpired sessions of a user
+	ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UserSession, error)
+
+	// ListRecentByUserID retrieves the latest sessions of a user, revoked and expired ones included,
+	// most recently used first
+	ListRecentByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*models.UserSession, error)
+
+	// DeleteExpired deletes sessions whose refresh token expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IUserServices defines the interface for user service.
+type IUserServices interface {
+	Register(ctx context.Context, req *models.CreateUserRequest) (*models.User, error)
+	GetProfile(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	UpdateProfile(ctx context.Context, userID uuid.UUID, req *models.UpdateUserRequest) (*models.User, error)
+	VerifyEmail(ctx context.Context, req *models.VerifyEmailRequest) error
+	ResendEmailVerification(ctx context.Context, req *models.ResendEmailVerificationRequest) error
+}
+
+// IUserAPI defines the interface for user API handler.
+type IUserAPI interface {
+	RegisterHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	GetMeHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	UpdateMeHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	VerifyEmailHandlerHTTP(w http.ResponseWriter, r *http.Request)
+	ResendEmailVerificationHandlerHTTP(w http.ResponseWriter, r *http.Request)
+}
@@ -2,6 +2,9 @@ package interfaces
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/ibnuzaman/ewallet-ums/internal/models"
 )
@@ -11,8 +14,11 @@ type IUserRepository interface {
 	// Create creates a new user
 	Create(ctx context.Context, user *models.User) error
 
+	// CreateWithRole creates a new user and grants it a role in one transaction
+	CreateWithRole(ctx context.Context, user *models.User, roleName string) error
+
 	// GetByID retrieves a user by ID
-	GetByID(ctx context.Context, id int64) (*models.User, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 
 	// GetByEmail retrieves a user by email
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
@@ -23,8 +29,35 @@ type IUserRepository interface {
 	// Update updates a user
 	Update(ctx context.Context, user *models.User) error
 
+	// UpdatePassword replaces the password hash of a user
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+
+	// RecordFailedLogin increments the failed login counter of a user and returns the new count
+	RecordFailedLogin(ctx context.Context, id uuid.UUID) (int, error)
+
+	// Lock locks a user out until the given time and resets the failed login counter
+	Lock(ctx context.Context, id uuid.UUID, lockoutCount int, until time.Time) error
+
+	// HardLock locks a user out until Unlock is called
+	HardLock(ctx context.Context, id uuid.UUID) error
+
+	// Unlock clears the failed login counters and any lock of a user
+	Unlock(ctx context.Context, id uuid.UUID) error
+
+	// UpdatePIN replaces the transaction PIN hash of a user and clears the PIN lockout
+	UpdatePIN(ctx context.Context, id uuid.UUID, pinHash string) error
+
+	// RecordFailedPIN increments the failed PIN counter of a user and returns the new count
+	RecordFailedPIN(ctx context.Context, id uuid.UUID) (int, error)
+
+	// LockPIN stops PIN verification of a user until the given time and resets the failed PIN counter
+	LockPIN(ctx context.Context, id uuid.UUID, until time.Time) error
+
+	// ClearPINFailures resets the failed PIN counter and any PIN lock of a user
+	ClearPINFailures(ctx context.Context, id uuid.UUID) error
+
 	// Delete soft deletes a user
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id uuid.UUID) error
 
 	// List retrieves users based on filters
 	List(ctx context.Context, filter models.UserFilter) ([]*models.User, error)
@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IUserTokenRepository defines the interface for single-use user token repository operations.
+type IUserTokenRepository interface {
+	// Create creates a new token
+	Create(ctx context.Context, token *models.UserToken) error
+
+	// GetActive retrieves an unused, unexpired token without consuming it
+	GetActive(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error)
+
+	// Consume marks an unused, unexpired token as used and returns it
+	Consume(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error)
+
+	// GetLatestByUserID retrieves the most recently created token of a user for a purpose
+	GetLatestByUserID(ctx context.Context, userID uuid.UUID, purpose string) (*models.UserToken, error)
+
+	// InvalidateByUserID marks every unused token of a user for a purpose as used
+	InvalidateByUserID(ctx context.Context, userID uuid.UUID, purpose string) error
+}
@@ -0,0 +1,33 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// IWebAuthnRepository defines the interface for WebAuthn credential and ceremony operations.
+type IWebAuthnRepository interface {
+	// CreateCredential stores a new credential and enables MFA for its user
+	CreateCredential(ctx context.Context, credential *models.UserCredential) error
+
+	// ListCredentialsByUserID retrieves every credential of a user, oldest first
+	ListCredentialsByUserID(ctx context.Context, userID uuid.UUID) ([]models.UserCredential, error)
+
+	// UpdateCredentialUsage records a successful assertion of a credential
+	UpdateCredentialUsage(ctx context.Context, credentialID []byte, signCount uint32, backupState bool) error
+
+	// DeleteCredential deletes a credential of a user, disabling MFA if it was their last second factor
+	DeleteCredential(ctx context.Context, userID, id uuid.UUID) error
+
+	// DeleteCredentialsByUserID deletes every credential of a user and returns how many were deleted
+	DeleteCredentialsByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// CreateSession stores a pending ceremony
+	CreateSession(ctx context.Context, session *models.WebAuthnSession) error
+
+	// ConsumeSession deletes an unexpired ceremony by its challenge and returns it
+	ConsumeSession(ctx context.Context, purpose, challenge string) (*models.WebAuthnSession, error)
+}
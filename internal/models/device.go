@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserDevice is a device a user completed a verified login from, stored in user_devices.
+// Only the SHA-256 hash of the device ID generated by the client is kept.
+type UserDevice struct {
+	LastSeenAt    time.Time `db:"last_seen_at" json:"last_seen_at"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	Name          *string   `db:"name" json:"name,omitempty"`
+	Platform      *string   `db:"platform" json:"platform,omitempty"`
+	LastIPAddress *string   `db:"last_ip_address" json:"last_ip_address,omitempty"`
+	DeviceIDHash  string    `db:"device_id_hash" json:"-"`
+	ID            uuid.UUID `db:"id" json:"id"`
+	UserID        uuid.UUID `db:"user_id" json:"-"`
+}
+
+// DeviceInfo identifies the client device a login comes from. DeviceID is generated randomly
+// by the app once per install and must be kept as secret as a refresh token, since logins
+// from a known device skip the step-up verification.
+type DeviceInfo struct {
+	DeviceID   string `json:"device_id,omitempty" validate:"omitempty,min=16,max=255"`
+	DeviceName string `json:"device_name,omitempty" validate:"max=100"`
+	Platform   string `json:"platform,omitempty" validate:"omitempty,oneof=ios android web"`
+}
+
+// SessionResponse describes an active session of the user.
+type SessionResponse struct {
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	DeviceID   *uuid.UUID `json:"device_id,omitempty"`
+	IPAddress  string     `json:"ip_address,omitempty"`
+	UserAgent  string     `json:"user_agent,omitempty"`
+	DeviceName string     `json:"device_name,omitempty"`
+	Platform   string     `json:"platform,omitempty"`
+	ID         uuid.UUID  `json:"id"`
+	Current    bool       `json:"current"`
+}
+
+// SessionListResponse lists the active sessions and the known devices of the user.
+type SessionListResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+	Devices  []*UserDevice     `json:"devices"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// GeoLocation is the approximate location of an IP address. AccuracyRadius is in
+// kilometers and zero when unknown.
+type GeoLocation struct {
+	Country        string  `json:"country,omitempty"`
+	City           string  `json:"city,omitempty"`
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	AccuracyRadius int     `json:"-"`
+}
+
+// LoginRiskAssessment is the result of checking a new session against the earlier sessions
+// of its user. Flags name the risks found and Score sums their weights, from 0 to 100.
+type LoginRiskAssessment struct {
+	Location   *GeoLocation `json:"location,omitempty"`
+	IPAddress  string       `json:"ip_address,omitempty"`
+	UserAgent  string       `json:"user_agent,omitempty"`
+	DeviceName string       `json:"device_name,omitempty"`
+	Flags      []string     `json:"flags"`
+	Score      int          `json:"score"`
+	SessionID  uuid.UUID    `json:"session_id"`
+}
+
+// Suspicious reports whether any risk was found.
+func (a *LoginRiskAssessment) Suspicious() bool {
+	return len(a.Flags) > 0
+}
+
+// HasFlag reports whether the given risk was found.
+func (a *LoginRiskAssessment) HasFlag(flag string) bool {
+	return slices.Contains(a.Flags, flag)
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserTOTP is a TOTP authenticator of a user, stored in user_totp. The secret is encrypted
+// with the user ID as associated data and only accepted for login once confirmed.
+type UserTOTP struct {
+	CreatedAt       time.Time    `db:"created_at"`
+	UpdatedAt       time.Time    `db:"updated_at"`
+	ConfirmedAt     sql.NullTime `db:"confirmed_at"`
+	SecretEncrypted string       `db:"secret_encrypted"`
+	LastUsedStep    int64        `db:"last_used_step"`
+	UserID          uuid.UUID    `db:"user_id"`
+}
+
+// TOTPEnrollmentResponse holds a new TOTP secret to add to an authenticator app.
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// EnrollTOTPRequest confirms the current password before a new authenticator is enrolled.
+type EnrollTOTPRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// ConfirmTOTPRequest represents the first code of a newly enrolled authenticator and the
+// current password.
+type ConfirmTOTPRequest struct {
+	Code     string `json:"code" validate:"required,numeric,len=6"`
+	Password string `json:"password" validate:"required"`
+}
+
+// MFACodeRequest represents a current TOTP code or an unused recovery code confirming a
+// change to the authenticator app.
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+// RecoveryCodesResponse holds one-time recovery codes. They are only shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallenge is returned by login when the user must present a second factor. Reason
+// tells whether the user enabled MFA or logs in from a new device, which is confirmed with
+// a code sent by SMS.
+type MFAChallenge struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	Token     string    `json:"mfa_token"`
+	Reason    string    `json:"reason"`
+	Methods   []string  `json:"methods"`
+}
+
+// LoginMFARequest completes a login with a TOTP code, a recovery code, an SMS code or the
+// response of navigator.credentials.get() to a passkey challenge.
+type LoginMFARequest struct {
+	DeviceInfo
+	MFAToken  string          `json:"mfa_token" validate:"required"`
+	Code      string          `json:"code" validate:"required_without=Passkey,max=32"`
+	IPAddress string          `json:"-"`
+	UserAgent string          `json:"-"`
+	Passkey   json.RawMessage `json:"passkey"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// ForgotPasswordRequest represents the request to email a password reset token.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ChangePasswordRequest represents the request of an authenticated user to change their password.
+// Other sessions are revoked; RevokeAllSessions also revokes the current one.
+type ChangePasswordRequest struct {
+	CurrentPassword   string `json:"current_password" validate:"required"`
+	NewPassword       string `json:"new_password" validate:"required,max=72,nefield=CurrentPassword"`
+	RevokeAllSessions bool   `json:"revoke_all_sessions"`
+}
+
+// ResetPasswordRequest represents the request to set a new password with a reset token.
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,max=72"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PhoneOTP is a one-time password sent by SMS, stored in phone_otps. Purpose records what the
+// code was sent for, such as verifying the phone number or resetting the transaction PIN.
+// Only the SHA-256 hash of the code is stored.
+type PhoneOTP struct {
+	ExpiresAt  time.Time    `db:"expires_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+	ConsumedAt sql.NullTime `db:"consumed_at"`
+	Phone      string       `db:"phone_number"`
+	Purpose    string       `db:"purpose"`
+	CodeHash   string       `db:"code_hash"`
+	Attempts   int          `db:"attempts"`
+	ID         uuid.UUID    `db:"id"`
+	UserID     uuid.UUID    `db:"user_id"`
+}
+
+// RequestPhoneOTPRequest represents the request to send a verification code to a phone number.
+type RequestPhoneOTPRequest struct {
+	Phone string `json:"phone" validate:"required,e164"`
+}
+
+// VerifyPhoneOTPRequest represents the request to confirm a phone number with a verification code.
+type VerifyPhoneOTPRequest struct {
+	Phone string `json:"phone" validate:"required,e164"`
+	Code  string `json:"code" validate:"required,numeric,len=6"`
+}
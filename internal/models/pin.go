@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SetPINRequest represents the request to set the first transaction PIN of a user.
+// The PIN rules beyond its format come from helpers.ValidatePIN.
+type SetPINRequest struct {
+	PIN      string `json:"pin" validate:"required,number,len=6"`
+	Password string `json:"password" validate:"required,max=72"`
+}
+
+// ChangePINRequest represents the request to replace the transaction PIN.
+type ChangePINRequest struct {
+	CurrentPIN string `json:"current_pin" validate:"required,number,len=6"`
+	NewPIN     string `json:"new_pin" validate:"required,number,len=6,nefield=CurrentPIN"`
+}
+
+// ResetPINRequest represents the request to replace a forgotten transaction PIN with the
+// code sent by SMS to the user's phone number.
+type ResetPINRequest struct {
+	Code   string `json:"code" validate:"required,number,len=6"`
+	NewPIN string `json:"new_pin" validate:"required,number,len=6"`
+}
+
+// VerifyPINRequest is sent by internal services to confirm a transaction with the user's PIN.
+type VerifyPINRequest struct {
+	PIN    string    `json:"pin" validate:"required,number,len=6"`
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+// PINVerificationResponse is the result of a transaction PIN check. LockedUntil is set while
+// PIN verification is locked after too many wrong PINs.
+type PINVerificationResponse struct {
+	LockedUntil       *time.Time `json:"locked_until,omitempty"`
+	AttemptsRemaining int        `json:"attempts_remaining"`
+	Valid             bool       `json:"valid"`
+}
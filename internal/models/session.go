@@ -0,0 +1,74 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSession represents an authenticated session in user_sessions.
+// Only SHA-256 hashes of the access and refresh tokens are stored.
+type UserSession struct {
+	AccessTokenExpiresAt  time.Time      `db:"access_token_expires_at" json:"access_token_expires_at"`
+	RefreshTokenExpiresAt time.Time      `db:"refresh_token_expires_at" json:"refresh_token_expires_at"`
+	CreatedAt             time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt             time.Time      `db:"updated_at" json:"updated_at"`
+	AccessToken           string         `db:"access_token" json:"-"`
+	RefreshToken          string         `db:"refresh_token" json:"-"`
+	IPAddress             sql.NullString `db:"ip_address" json:"ip_address"`
+	UserAgent             sql.NullString `db:"user_agent" json:"user_agent"`
+	DeviceName            sql.NullString `db:"device_name" json:"device_name"`
+	Platform              sql.NullString `db:"platform" json:"platform"`
+	DeviceID              uuid.NullUUID  `db:"device_id" json:"device_id"`
+	ID                    uuid.UUID      `db:"id" json:"id"`
+	UserID                uuid.UUID      `db:"user_id" json:"user_id"`
+	IsRevoked             bool           `db:"is_revoked" json:"is_revoked"`
+}
+
+// LoginRequest represents the request to log in with email or phone.
+type LoginRequest struct {
+	DeviceInfo
+	Email     string `json:"email,omitempty" validate:"required_without=Phone,omitempty,email"`
+	Phone     string `json:"phone,omitempty" validate:"required_without=Email,omitempty,e164"`
+	Password  string `json:"password" validate:"required"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// RefreshTokenRequest represents the request to exchange a refresh token.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	IPAddress    string `json:"-"`
+	UserAgent    string `json:"-"`
+}
+
+// TokenValidationResponse describes an access token to internal services.
+// Only Active is set when the token is not valid.
+type TokenValidationResponse struct {
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	UserID      *uuid.UUID `json:"user_id,omitempty"`
+	SessionID   *uuid.UUID `json:"session_id,omitempty"`
+	Permissions []string   `json:"permissions,omitempty"`
+	Active      bool       `json:"active"`
+	IsVerified  bool       `json:"is_verified"`
+}
+
+// LoginResponse is the result of a password login: the session tokens, or an MFA challenge
+// to complete with POST /api/v1/auth/login/mfa when the user has enabled MFA or logs in
+// from a new device.
+type LoginResponse struct {
+	*TokenResponse
+	MFA         *MFAChallenge `json:"mfa,omitempty"`
+	MFARequired bool          `json:"mfa_required"`
+}
+
+// TokenResponse represents the tokens issued for a session.
+type TokenResponse struct {
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+	AccessToken           string    `json:"access_token"`
+	RefreshToken          string    `json:"refresh_token"`
+	TokenType             string    `json:"token_type"`
+	ExpiresIn             int64     `json:"expires_in"`
+}
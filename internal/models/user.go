@@ -3,34 +3,73 @@ package models
 import (
 	"database/sql"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // User represents a user in the system.
 type User struct {
-	PasswordHash string       `db:"password_hash" json:"-"`
-	Email        string       `db:"email" json:"email"`
-	Phone        string       `db:"phone" json:"phone"`
-	FullName     string       `db:"full_name" json:"full_name"`
-	CreatedAt    time.Time    `db:"created_at" json:"created_at"`
-	UpdatedAt    time.Time    `db:"updated_at" json:"updated_at"`
-	DeletedAt    sql.NullTime `db:"deleted_at" json:"deleted_at,omitempty"`
-	ID           int64        `db:"id" json:"id"`
-	IsActive     bool         `db:"is_active" json:"is_active"`
-	IsVerified   bool         `db:"is_verified" json:"is_verified"`
+	PasswordHash        string         `db:"password_hash" json:"-"`
+	Email               string         `db:"email" json:"email"`
+	Phone               string         `db:"phone_number" json:"phone"`
+	FullName            string         `db:"full_name" json:"full_name"`
+	CreatedAt           time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time      `db:"updated_at" json:"updated_at"`
+	LockedUntil         *time.Time     `db:"locked_until" json:"locked_until,omitempty"`
+	HardLockedAt        *time.Time     `db:"hard_locked_at" json:"hard_locked_at,omitempty"`
+	PINUpdatedAt        *time.Time     `db:"pin_updated_at" json:"pin_updated_at,omitempty"`
+	PINLockedUntil      *time.Time     `db:"pin_locked_until" json:"pin_locked_until,omitempty"`
+	DeactivatedAt       *time.Time     `db:"deactivated_at" json:"deactivated_at,omitempty"`
+	DeletedAt           sql.NullTime   `db:"deleted_at" json:"deleted_at,omitempty"`
+	PINHash             sql.NullString `db:"pin_hash" json:"-"`
+	FailedLoginAttempts int            `db:"failed_login_attempts" json:"failed_login_attempts"`
+	LockoutCount        int            `db:"lockout_count" json:"lockout_count"`
+	PINFailedAttempts   int            `db:"pin_failed_attempts" json:"pin_failed_attempts"`
+	ID                  uuid.UUID      `db:"id" json:"id"`
+	IsActive            bool           `db:"is_active" json:"is_active"`
+	IsVerified          bool           `db:"is_verified" json:"is_verified"`
+	IsPhoneVerified     bool           `db:"is_phone_verified" json:"is_phone_verified"`
+	IsMFAEnabled        bool           `db:"is_mfa_enabled" json:"is_mfa_enabled"`
+}
+
+// AccountVerified reports whether the user confirmed their email address or phone number.
+// Users sign up by either, so one verified contact makes the account verified.
+func (u *User) AccountVerified() bool {
+	return u.IsVerified || u.IsPhoneVerified
 }
 
 // CreateUserRequest represents the request to create a user.
 type CreateUserRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Phone    string `json:"phone" validate:"required"`
-	FullName string `json:"full_name" validate:"required"`
-	Password string `json:"password" validate:"required,min=8"`
+	Email    string `json:"email" validate:"required,email,max=100"`
+	Phone    string `json:"phone" validate:"required,e164"`
+	FullName string `json:"full_name" validate:"required,max=255"`
+	// The cap bounds hashing work; length and content rules come from helpers.ValidatePassword
+	Password string `json:"password" validate:"required,max=72"`
 }
 
 // UpdateUserRequest represents the request to update a user.
+// Only the fields that are set are applied.
 type UpdateUserRequest struct {
-	FullName *string `json:"full_name,omitempty"`
-	Phone    *string `json:"phone,omitempty"`
+	FullName *string `json:"full_name,omitempty" validate:"omitnil,min=1,max=255"`
+	Phone    *string `json:"phone,omitempty" validate:"omitnil,e164"`
+}
+
+// AdminUpdateUserRequest represents an update of a user by support staff.
+// Only the fields that are set are applied.
+type AdminUpdateUserRequest struct {
+	Email      *string `json:"email,omitempty" validate:"omitnil,email,max=100"`
+	FullName   *string `json:"full_name,omitempty" validate:"omitnil,min=1,max=255"`
+	Phone      *string `json:"phone,omitempty" validate:"omitnil,e164"`
+	IsActive   *bool   `json:"is_active,omitempty"`
+	IsVerified *bool   `json:"is_verified,omitempty"`
+}
+
+// UserListResponse is a page of users with pagination metadata.
+type UserListResponse struct {
+	Users    []*User `json:"users"`
+	Total    int64   `json:"total"`
+	Page     int     `json:"page"`
+	PageSize int     `json:"page_size"`
 }
 
 // UserFilter represents filters for querying users.
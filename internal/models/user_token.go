@@ -0,0 +1,30 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserToken is a single-use token sent to a user out of band, stored in user_tokens.
+// Only the SHA-256 hash of the token is stored.
+type UserToken struct {
+	ExpiresAt time.Time    `db:"expires_at"`
+	CreatedAt time.Time    `db:"created_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	Purpose   string       `db:"purpose"`
+	TokenHash string       `db:"token_hash"`
+	ID        uuid.UUID    `db:"id"`
+	UserID    uuid.UUID    `db:"user_id"`
+}
+
+// VerifyEmailRequest represents the request to confirm an email address.
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ResendEmailVerificationRequest represents the request to send a new verification email.
+type ResendEmailVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
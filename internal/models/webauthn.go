@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// UserCredential is a WebAuthn credential of a user, stored in user_credentials.
+type UserCredential struct {
+	CreatedAt       time.Time      `db:"created_at" json:"created_at"`
+	LastUsedAt      *time.Time     `db:"last_used_at" json:"last_used_at,omitempty"`
+	Name            string         `db:"name" json:"name"`
+	AttestationType string         `db:"attestation_type" json:"-"`
+	CredentialID    []byte         `db:"credential_id" json:"-"`
+	PublicKey       []byte         `db:"public_key" json:"-"`
+	AAGUID          []byte         `db:"aaguid" json:"-"`
+	Transports      pq.StringArray `db:"transports" json:"-"`
+	SignCount       int64          `db:"sign_count" json:"-"`
+	ID              uuid.UUID      `db:"id" json:"id"`
+	UserID          uuid.UUID      `db:"user_id" json:"-"`
+	BackupEligible  bool           `db:"backup_eligible" json:"-"`
+	BackupState     bool           `db:"backup_state" json:"synced"`
+}
+
+// WebAuthnSession is a pending WebAuthn ceremony, stored in webauthn_sessions until the
+// client returns the signed challenge. SessionData holds the library's session as JSON.
+type WebAuthnSession struct {
+	ExpiresAt   time.Time     `db:"expires_at"`
+	CreatedAt   time.Time     `db:"created_at"`
+	Purpose     string        `db:"purpose"`
+	Challenge   string        `db:"challenge"`
+	SessionData []byte        `db:"session_data"`
+	UserID      uuid.NullUUID `db:"user_id"`
+	ID          uuid.UUID     `db:"id"`
+}
+
+// PasskeyOptionsRequest confirms the current password, or a TOTP or recovery code, before a
+// passkey registration starts.
+type PasskeyOptionsRequest struct {
+	Password string `json:"password" validate:"required_without=Code"`
+	Code     string `json:"code" validate:"required_without=Password,max=32"`
+}
+
+// RegisterPasskeyRequest completes a passkey registration with the response of
+// navigator.credentials.create().
+type RegisterPasskeyRequest struct {
+	Name       string          `json:"name" validate:"omitempty,max=100"`
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
+
+// PasskeyLoginRequest completes a passwordless login with the response of
+// navigator.credentials.get().
+type PasskeyLoginRequest struct {
+	DeviceInfo
+	IPAddress  string          `json:"-"`
+	UserAgent  string          `json:"-"`
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
+
+// MFAPasskeyOptionsRequest requests a passkey challenge for a pending MFA login.
+type MFAPasskeyOptionsRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
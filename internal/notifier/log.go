@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// Log is an INotifier that writes messages to the application log instead of
+// delivering them. It is meant for local development only, since the log then
+// contains usable tokens.
+type Log struct{}
+
+// SendEmailVerification logs the verification token for the user.
+func (n *Log) SendEmailVerification(_ context.Context, user *models.User, token string) error {
+	helpers.Logger.WithFields(logrus.Fields{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"token":   token,
+	}).Info("Email verification token issued")
+	return nil
+}
+
+// SendPasswordReset logs the password reset token for the user.
+func (n *Log) SendPasswordReset(_ context.Context, user *models.User, token string) error {
+	helpers.Logger.WithFields(logrus.Fields{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"token":   token,
+	}).Info("Password reset token issued")
+	return nil
+}
+
+// SendLoginAlert logs the suspicious login alert for the user.
+func (n *Log) SendLoginAlert(_ context.Context, user *models.User, assessment *models.LoginRiskAssessment) error {
+	helpers.Logger.WithFields(logrus.Fields{
+		"user_id":    user.ID,
+		"email":      user.Email,
+		"session_id": assessment.SessionID,
+		"ip_address": assessment.IPAddress,
+		"flags":      assessment.Flags,
+	}).Info("Suspicious login alert issued")
+	return nil
+}
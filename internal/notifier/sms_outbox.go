@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SMSOutbox is an ISMSProvider stand-in for local development. Instead of sending
+// messages it appends them to the file at Path, or writes them to stdout when Path
+// is empty. Like Log, it must not be used in production.
+type SMSOutbox struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// SendSMS writes the message for the phone number to the outbox.
+func (o *SMSOutbox) SendSMS(_ context.Context, phone, message string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var w io.Writer = os.Stdout
+	if o.Path != "" {
+		f, err := os.OpenFile(o.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open SMS outbox: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if _, err := fmt.Fprintf(w, "%s SMS to %s: %s\n", time.Now().Format(time.RFC3339), phone, message); err != nil {
+		return fmt.Errorf("failed to write SMS outbox: %w", err)
+	}
+	return nil
+}
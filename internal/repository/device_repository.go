@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+const userDeviceColumns = `id, user_id, device_id_hash, name, platform, last_ip_address, last_seen_at, created_at`
+
+// DeviceRepository implements IDeviceRepository.
+type DeviceRepository struct {
+	db *sqlx.DB
+}
+
+// NewDeviceRepository creates a new device repository.
+func NewDeviceRepository(db *sqlx.DB) *DeviceRepository {
+	return &DeviceRepository{
+		db: db,
+	}
+}
+
+// Upsert stores a device, or records another login from a device the user already has by
+// updating its last IP address and last seen time. The name and platform are only replaced
+// when the client sent them.
+func (r *DeviceRepository) Upsert(ctx context.Context, device *models.UserDevice) error {
+	query := `
+		INSERT INTO user_devices (user_id, device_id_hash, name, platform, last_ip_address)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, device_id_hash) DO UPDATE
+		SET name = COALESCE(EXCLUDED.name, user_devices.name),
+		    platform = COALESCE(EXCLUDED.platform, user_devices.platform),
+		    last_ip_address = EXCLUDED.last_ip_address,
+		    last_seen_at = NOW()
+		RETURNING ` + userDeviceColumns
+
+	err := r.db.QueryRowxContext(ctx, query,
+		device.UserID,
+		device.DeviceIDHash,
+		device.Name,
+		device.Platform,
+		device.LastIPAddress,
+	).StructScan(device)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to store device of user %s: %v", device.UserID, err)
+		return fmt.Errorf("failed to store device: %w", err)
+	}
+
+	return nil
+}
+
+// GetByDeviceIDHash retrieves a device of a user by the hash of its device ID.
+func (r *DeviceRepository) GetByDeviceIDHash(ctx context.Context, userID uuid.UUID, deviceIDHash string) (*models.UserDevice, error) {
+	query := `SELECT ` + userDeviceColumns + ` FROM user_devices WHERE user_id = $1 AND device_id_hash = $2`
+
+	var device models.UserDevice
+	err := r.db.GetContext(ctx, &device, query, userID, deviceIDHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewError(domain.ErrNotFound, "device not found")
+		}
+		helpers.Logger.Errorf("Failed to get device of user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to get device: %w", err)
+	}
+
+	return &device, nil
+}
+
+// CountByUserID counts the devices of a user.
+func (r *DeviceRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM user_devices WHERE user_id = $1", userID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to count devices of user %s: %v", userID, err)
+		return 0, fmt.Errorf("failed to count devices: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListByUserID retrieves the devices of a user, most recently seen first.
+func (r *DeviceRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UserDevice, error) {
+	query := `SELECT ` + userDeviceColumns + ` FROM user_devices WHERE user_id = $1 ORDER BY last_seen_at DESC`
+
+	devices := []*models.UserDevice{}
+	if err := r.db.SelectContext(ctx, &devices, query, userID); err != nil {
+		helpers.Logger.Errorf("Failed to list devices of user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// Delete deletes a device of a user. Sessions created on it keep existing without a device.
+func (r *DeviceRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM user_devices WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to delete device %s: %v", id, err)
+		return fmt.Errorf("failed to delete device: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "device not found")
+	}
+
+	helpers.Logger.Infof("Device %s of user %s deleted", id, userID)
+	return nil
+}
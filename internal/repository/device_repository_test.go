@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+func TestDeviceRepository_Lifecycle(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewDeviceRepository(db)
+	ctx := context.Background()
+
+	name, platform, ip := "Pixel 9", "android", "203.0.113.7"
+	device := &models.UserDevice{
+		UserID:        user.ID,
+		DeviceIDHash:  uuid.NewString(),
+		Name:          &name,
+		Platform:      &platform,
+		LastIPAddress: &ip,
+	}
+	if err := repo.Upsert(ctx, device); err != nil {
+		t.Fatalf("Upsert returned error: %v", err)
+	}
+	if device.ID == uuid.Nil || device.CreatedAt.IsZero() {
+		t.Fatalf("Expected the stored device to be returned, got %+v", device)
+	}
+
+	// A later login without a name keeps the stored one
+	newIP := "198.51.100.1"
+	again := &models.UserDevice{UserID: user.ID, DeviceIDHash: device.DeviceIDHash, LastIPAddress: &newIP}
+	if err := repo.Upsert(ctx, again); err != nil {
+		t.Fatalf("Upsert returned error: %v", err)
+	}
+	if again.ID != device.ID || again.Name == nil || *again.Name != name || *again.LastIPAddress != newIP {
+		t.Errorf("Expected the existing device to be updated, got %+v", again)
+	}
+
+	found, err := repo.GetByDeviceIDHash(ctx, user.ID, device.DeviceIDHash)
+	if err != nil || found.ID != device.ID {
+		t.Fatalf("GetByDeviceIDHash returned %+v, %v", found, err)
+	}
+	if _, err := repo.GetByDeviceIDHash(ctx, uuid.New(), device.DeviceIDHash); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected a device of another user not to match, got %v", err)
+	}
+
+	count, err := repo.CountByUserID(ctx, user.ID)
+	if err != nil || count != 1 {
+		t.Errorf("CountByUserID returned %d, %v, want 1", count, err)
+	}
+
+	devices, err := repo.ListByUserID(ctx, user.ID)
+	if err != nil || len(devices) != 1 {
+		t.Fatalf("ListByUserID returned %d devices, %v", len(devices), err)
+	}
+
+	if err := repo.Delete(ctx, uuid.New(), device.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected deleting a device of another user to fail, got %v", err)
+	}
+	if err := repo.Delete(ctx, user.ID, device.ID); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, err := repo.GetByDeviceIDHash(ctx, user.ID, device.DeviceIDHash); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected the device to be deleted, got %v", err)
+	}
+}
+
+func TestSessionRepository_RevokeByDeviceID(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	devices := NewDeviceRepository(db)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	device := &models.UserDevice{UserID: user.ID, DeviceIDHash: uuid.NewString()}
+	if err := devices.Upsert(ctx, device); err != nil {
+		t.Fatalf("Upsert returned error: %v", err)
+	}
+
+	onDevice := newTestSession(t, repo, user.ID)
+	if _, err := db.Exec("UPDATE user_sessions SET device_id = $1 WHERE id = $2", device.ID, onDevice.ID); err != nil {
+		t.Fatalf("Failed to link session to device: %v", err)
+	}
+	other := newTestSession(t, repo, user.ID)
+
+	if err := repo.RevokeByDeviceID(ctx, user.ID, device.ID); err != nil {
+		t.Fatalf("RevokeByDeviceID returned error: %v", err)
+	}
+
+	active, err := repo.ListActiveByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListActiveByUserID returned error: %v", err)
+	}
+	if len(active) != 1 || active[0].ID != other.ID {
+		t.Errorf("Expected only session %s to stay active, got %d sessions", other.ID, len(active))
+	}
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+)
+
+// pqUniqueViolation is the PostgreSQL error code of unique constraint violations.
+const pqUniqueViolation = "23505"
+
+// mapUniqueViolation turns a unique constraint violation into a domain.ErrConflict error with
+// the message given for the violated constraint, or "already exists" when it has none. Other
+// errors are returned unchanged.
+func mapUniqueViolation(err error, messages map[string]string) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != pqUniqueViolation {
+		return err
+	}
+
+	if message, ok := messages[pqErr.Constraint]; ok {
+		return domain.NewError(domain.ErrConflict, message)
+	}
+	return domain.NewError(domain.ErrConflict, "already exists")
+}
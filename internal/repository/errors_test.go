@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+)
+
+func TestMapUniqueViolation(t *testing.T) {
+	messages := map[string]string{"idx_users_email": "email already registered"}
+
+	tests := []struct {
+		err          error
+		name         string
+		wantMessage  string
+		wantConflict bool
+	}{
+		{
+			name:         "known constraint",
+			err:          fmt.Errorf("insert failed: %w", &pq.Error{Code: pqUniqueViolation, Constraint: "idx_users_email"}),
+			wantConflict: true,
+			wantMessage:  "email already registered",
+		},
+		{
+			name:         "other constraint",
+			err:          &pq.Error{Code: pqUniqueViolation, Constraint: "idx_other"},
+			wantConflict: true,
+			wantMessage:  "already exists",
+		},
+		{
+			name: "other database error",
+			err:  &pq.Error{Code: "23503", Constraint: "idx_users_email"},
+		},
+		{
+			name: "not a database error",
+			err:  errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mapUniqueViolation(tt.err, messages)
+
+			if errors.Is(err, domain.ErrConflict) != tt.wantConflict {
+				t.Fatalf("Expected conflict %t, got %v", tt.wantConflict, err)
+			}
+			if tt.wantConflict && err.Error() != tt.wantMessage {
+				t.Errorf("Expected message %q, got %q", tt.wantMessage, err.Error())
+			}
+			if !tt.wantConflict && !errors.Is(err, tt.err) {
+				t.Errorf("Expected the error unchanged, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// MFARepository implements IMFARepository.
+// Operations on a missing, already confirmed or already used record return an error
+// of kind domain.ErrNotFound.
+type MFARepository struct {
+	db *sqlx.DB
+}
+
+// NewMFARepository creates a new MFA repository.
+func NewMFARepository(db *sqlx.DB) *MFARepository {
+	return &MFARepository{
+		db: db,
+	}
+}
+
+// SavePendingTOTP stores a new unconfirmed TOTP secret. An earlier unconfirmed secret is
+// replaced, a confirmed one is left untouched.
+func (r *MFARepository) SavePendingTOTP(ctx context.Context, userID uuid.UUID, secretEncrypted string) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret_encrypted)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret_encrypted = EXCLUDED.secret_encrypted, last_used_step = 0, created_at = NOW(), updated_at = NOW()
+		WHERE user_totp.confirmed_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, secretEncrypted)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to save TOTP secret for user %s: %v", userID, err)
+		return fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "unconfirmed TOTP not found")
+	}
+
+	return nil
+}
+
+// GetTOTP retrieves the TOTP authenticator of a user.
+func (r *MFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*models.UserTOTP, error) {
+	query := `
+		SELECT user_id, secret_encrypted, last_used_step, confirmed_at, created_at, updated_at
+		FROM user_totp
+		WHERE user_id = $1
+	`
+
+	var totp models.UserTOTP
+	err := r.db.GetContext(ctx, &totp, query, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewError(domain.ErrNotFound, "TOTP not found")
+		}
+		helpers.Logger.Errorf("Failed to get TOTP of user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to get TOTP: %w", err)
+	}
+
+	return &totp, nil
+}
+
+// ConfirmTOTP confirms a pending TOTP authenticator at the time step of its first code,
+// enables MFA for the user and replaces their recovery codes in one transaction.
+func (r *MFARepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE user_totp
+		SET confirmed_at = NOW(), last_used_step = $1, updated_at = NOW()
+		WHERE user_id = $2 AND confirmed_at IS NULL
+	`, step, userID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to confirm TOTP of user %s: %v", userID, err)
+		return fmt.Errorf("failed to confirm TOTP: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "unconfirmed TOTP not found")
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE users SET is_mfa_enabled = TRUE, updated_at = NOW() WHERE id = $1", userID); err != nil {
+		helpers.Logger.Errorf("Failed to enable MFA for user %s: %v", userID, err)
+		return fmt.Errorf("failed to confirm TOTP: %w", err)
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return fmt.Errorf("failed to confirm TOTP: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	helpers.Logger.Infof("TOTP of user %s confirmed", userID)
+	return nil
+}
+
+// UseTOTPStep records the time step of an accepted TOTP code. The comparison and update
+// are a single statement, so a code cannot be used twice, even concurrently.
+func (r *MFARepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	query := `
+		UPDATE user_totp
+		SET last_used_step = $1, updated_at = NOW()
+		WHERE user_id = $2 AND confirmed_at IS NOT NULL AND last_used_step < $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to record TOTP step of user %s: %v", userID, err)
+		return fmt.Errorf("failed to record TOTP step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "TOTP step already used")
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code of a user as used.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to use recovery code of user %s: %v", userID, err)
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "recovery code not found")
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes replaces the recovery codes of a user in one transaction, failing
+// unless the user has a confirmed TOTP authenticator.
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Locks the authenticator so it cannot be deleted before the new codes are stored
+	var confirmed bool
+	err = tx.GetContext(ctx, &confirmed, `
+		SELECT confirmed_at IS NOT NULL
+		FROM user_totp
+		WHERE user_id = $1
+		FOR UPDATE
+	`, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		helpers.Logger.Errorf("Failed to get TOTP of user %s: %v", userID, err)
+		return fmt.Errorf("failed to replace recovery codes: %w", err)
+	}
+	if !confirmed {
+		return domain.NewError(domain.ErrNotFound, "confirmed TOTP not found")
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return fmt.Errorf("failed to replace recovery codes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	helpers.Logger.Infof("Recovery codes of user %s replaced", userID)
+	return nil
+}
+
+// DeleteTOTP removes the TOTP authenticator and recovery codes of a user. MFA stays enabled
+// while the user has a passkey.
+func (r *MFARepository) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM user_totp WHERE user_id = $1 AND confirmed_at IS NOT NULL", userID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to delete TOTP of user %s: %v", userID, err)
+		return fmt.Errorf("failed to delete TOTP: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "confirmed TOTP not found")
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID); err != nil {
+		helpers.Logger.Errorf("Failed to delete recovery codes of user %s: %v", userID, err)
+		return fmt.Errorf("failed to delete TOTP: %w", err)
+	}
+
+	if err := syncMFAEnabled(ctx, tx, userID); err != nil {
+		return fmt.Errorf("failed to delete TOTP: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	helpers.Logger.Infof("TOTP of user %s deleted", userID)
+	return nil
+}
+
+// replaceRecoveryCodes deletes the recovery codes of a user and stores new ones within tx.
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userID uuid.UUID, recoveryCodeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID); err != nil {
+		helpers.Logger.Errorf("Failed to delete recovery codes of user %s: %v", userID, err)
+		return err
+	}
+	for _, codeHash := range recoveryCodeHashes {
+		_, err := tx.ExecContext(ctx, "INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, codeHash)
+		if err != nil {
+			helpers.Logger.Errorf("Failed to store recovery code of user %s: %v", userID, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// syncMFAEnabled sets users.is_mfa_enabled within tx to whether the user still has a
+// confirmed TOTP authenticator or a passkey.
+func syncMFAEnabled(ctx context.Context, tx *sqlx.Tx, userID uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET is_mfa_enabled = EXISTS (SELECT 1 FROM user_totp WHERE user_id = $1 AND confirmed_at IS NOT NULL)
+			OR EXISTS (SELECT 1 FROM user_credentials WHERE user_id = $1),
+			updated_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		helpers.Logger.Errorf("Failed to update MFA of user %s: %v", userID, err)
+		return err
+	}
+	return nil
+}
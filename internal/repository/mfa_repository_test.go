@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+)
+
+func TestMFARepository_TOTPLifecycle(t *testing.T) {
+	db := requireDB(t)
+	userRepo := NewUserRepository(db)
+	user := newTestUser(t, userRepo)
+	repo := NewMFARepository(db)
+	ctx := context.Background()
+
+	if err := repo.SavePendingTOTP(ctx, user.ID, "first"); err != nil {
+		t.Fatalf("SavePendingTOTP returned error: %v", err)
+	}
+	if err := repo.SavePendingTOTP(ctx, user.ID, "second"); err != nil {
+		t.Fatalf("SavePendingTOTP returned error replacing a pending secret: %v", err)
+	}
+	if err := repo.UseTOTPStep(ctx, user.ID, 10); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected unconfirmed TOTP to be unusable, got %v", err)
+	}
+
+	if err := repo.ConfirmTOTP(ctx, user.ID, 10, []string{"code-1", "code-2"}); err != nil {
+		t.Fatalf("ConfirmTOTP returned error: %v", err)
+	}
+
+	totp, err := repo.GetTOTP(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetTOTP returned error: %v", err)
+	}
+	if totp.SecretEncrypted != "second" || !totp.ConfirmedAt.Valid || totp.LastUsedStep != 10 {
+		t.Errorf("Expected confirmed TOTP with the latest secret, got %+v", totp)
+	}
+
+	stored, err := userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if !stored.IsMFAEnabled {
+		t.Error("Expected MFA to be enabled for the user")
+	}
+
+	if err := repo.SavePendingTOTP(ctx, user.ID, "third"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected confirmed TOTP not to be replaced, got %v", err)
+	}
+	if err := repo.ConfirmTOTP(ctx, user.ID, 11, nil); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected confirming twice to fail, got %v", err)
+	}
+
+	if err := repo.UseTOTPStep(ctx, user.ID, 10); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected replayed step to be rejected, got %v", err)
+	}
+	if err := repo.UseTOTPStep(ctx, user.ID, 11); err != nil {
+		t.Errorf("UseTOTPStep returned error: %v", err)
+	}
+}
+
+func TestMFARepository_UseRecoveryCode(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewMFARepository(db)
+	ctx := context.Background()
+
+	if err := repo.SavePendingTOTP(ctx, user.ID, "secret"); err != nil {
+		t.Fatalf("SavePendingTOTP returned error: %v", err)
+	}
+	if err := repo.ConfirmTOTP(ctx, user.ID, 1, []string{"code-1"}); err != nil {
+		t.Fatalf("ConfirmTOTP returned error: %v", err)
+	}
+
+	if err := repo.UseRecoveryCode(ctx, user.ID, "code-1"); err != nil {
+		t.Fatalf("UseRecoveryCode returned error: %v", err)
+	}
+	if err := repo.UseRecoveryCode(ctx, user.ID, "code-1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected used recovery code to be rejected, got %v", err)
+	}
+	if err := repo.UseRecoveryCode(ctx, user.ID, "unknown"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected unknown recovery code to be rejected, got %v", err)
+	}
+}
+
+func TestMFARepository_ReplaceRecoveryCodes(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewMFARepository(db)
+	ctx := context.Background()
+
+	if err := repo.ReplaceRecoveryCodes(ctx, user.ID, []string{"code-1"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected replacing without a confirmed TOTP to fail, got %v", err)
+	}
+
+	if err := repo.SavePendingTOTP(ctx, user.ID, "secret"); err != nil {
+		t.Fatalf("SavePendingTOTP returned error: %v", err)
+	}
+	if err := repo.ConfirmTOTP(ctx, user.ID, 1, []string{"code-1"}); err != nil {
+		t.Fatalf("ConfirmTOTP returned error: %v", err)
+	}
+
+	if err := repo.ReplaceRecoveryCodes(ctx, user.ID, []string{"code-2"}); err != nil {
+		t.Fatalf("ReplaceRecoveryCodes returned error: %v", err)
+	}
+	if err := repo.UseRecoveryCode(ctx, user.ID, "code-1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected replaced recovery code to be rejected, got %v", err)
+	}
+	if err := repo.UseRecoveryCode(ctx, user.ID, "code-2"); err != nil {
+		t.Errorf("UseRecoveryCode returned error: %v", err)
+	}
+}
+
+func TestMFARepository_DeleteTOTP(t *testing.T) {
+	db := requireDB(t)
+	userRepo := NewUserRepository(db)
+	user := newTestUser(t, userRepo)
+	repo := NewMFARepository(db)
+	ctx := context.Background()
+
+	if err := repo.SavePendingTOTP(ctx, user.ID, "secret"); err != nil {
+		t.Fatalf("SavePendingTOTP returned error: %v", err)
+	}
+	if err := repo.DeleteTOTP(ctx, user.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected deleting an unconfirmed TOTP to fail, got %v", err)
+	}
+	if err := repo.ConfirmTOTP(ctx, user.ID, 1, []string{"code-1"}); err != nil {
+		t.Fatalf("ConfirmTOTP returned error: %v", err)
+	}
+
+	if err := repo.DeleteTOTP(ctx, user.ID); err != nil {
+		t.Fatalf("DeleteTOTP returned error: %v", err)
+	}
+
+	if _, err := repo.GetTOTP(ctx, user.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected the TOTP to be deleted, got %v", err)
+	}
+	if err := repo.UseRecoveryCode(ctx, user.ID, "code-1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected the recovery codes to be deleted, got %v", err)
+	}
+	stored, err := userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if stored.IsMFAEnabled {
+		t.Error("Expected MFA to be disabled for a user without passkeys")
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+const phoneOTPColumns = `id, user_id, phone_number, purpose, code_hash, attempts, expires_at, consumed_at, created_at`
+
+// PhoneOTPRepository implements IPhoneOTPRepository.
+// Operations on a missing, consumed or expired OTP return an error of kind domain.ErrNotFound.
+type PhoneOTPRepository struct {
+	db *sqlx.DB
+}
+
+// NewPhoneOTPRepository creates a new phone OTP repository.
+func NewPhoneOTPRepository(db *sqlx.DB) *PhoneOTPRepository {
+	return &PhoneOTPRepository{
+		db: db,
+	}
+}
+
+// Create creates a new OTP.
+func (r *PhoneOTPRepository) Create(ctx context.Context, otp *models.PhoneOTP) error {
+	query := `
+		INSERT INTO phone_otps (user_id, phone_number, purpose, code_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, attempts, created_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, otp.UserID, otp.Phone, otp.Purpose, otp.CodeHash, otp.ExpiresAt).
+		Scan(&otp.ID, &otp.Attempts, &otp.CreatedAt)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to create OTP for user %s: %v", otp.UserID, err)
+		return fmt.Errorf("failed to create OTP: %w", err)
+	}
+
+	return nil
+}
+
+// GetActiveByPhone retrieves the most recent unconsumed, unexpired OTP with the given
+// purpose for a phone number.
+func (r *PhoneOTPRepository) GetActiveByPhone(ctx context.Context, purpose, phone string) (*models.PhoneOTP, error) {
+	query := `
+		SELECT ` + phoneOTPColumns + `
+		FROM phone_otps
+		WHERE phone_number = $1 AND purpose = $2 AND consumed_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var otp models.PhoneOTP
+	err := r.db.GetContext(ctx, &otp, query, phone, purpose)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewError(domain.ErrNotFound, "OTP not found")
+		}
+		helpers.Logger.Errorf("Failed to get active OTP for phone %s: %v", phone, err)
+		return nil, fmt.Errorf("failed to get OTP: %w", err)
+	}
+
+	return &otp, nil
+}
+
+// RecordAttempt counts a verification attempt against an active OTP. The check and
+// increment are a single statement, so concurrent guesses cannot exceed maxAttempts.
+func (r *PhoneOTPRepository) RecordAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) error {
+	query := `
+		UPDATE phone_otps
+		SET attempts = attempts + 1
+		WHERE id = $1 AND attempts < $2 AND consumed_at IS NULL AND expires_at > NOW()
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, maxAttempts)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to record attempt for OTP %s: %v", id, err)
+		return fmt.Errorf("failed to record OTP attempt: %w", err)
+	}
+
+	return otpRowsAffected(result)
+}
+
+// Consume marks an active OTP as consumed.
+func (r *PhoneOTPRepository) Consume(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE phone_otps
+		SET consumed_at = NOW()
+		WHERE id = $1 AND consumed_at IS NULL AND expires_at > NOW()
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to consume OTP %s: %v", id, err)
+		return fmt.Errorf("failed to consume OTP: %w", err)
+	}
+
+	return otpRowsAffected(result)
+}
+
+// InvalidateByPhone marks every unconsumed OTP with the given purpose for a phone number as consumed.
+func (r *PhoneOTPRepository) InvalidateByPhone(ctx context.Context, purpose, phone string) error {
+	query := `
+		UPDATE phone_otps
+		SET consumed_at = NOW()
+		WHERE phone_number = $1 AND purpose = $2 AND consumed_at IS NULL
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, phone, purpose); err != nil {
+		helpers.Logger.Errorf("Failed to invalidate OTPs for phone %s: %v", phone, err)
+		return fmt.Errorf("failed to invalidate OTPs: %w", err)
+	}
+
+	return nil
+}
+
+func otpRowsAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "OTP not found")
+	}
+	return nil
+}
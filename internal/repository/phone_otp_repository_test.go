@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+const testOTPPurpose = constants.OTPPurposePhoneVerification
+
+// newTestPhoneOTP creates an OTP for user sent for purpose and expiring after ttl.
+func newTestPhoneOTP(t *testing.T, repo *PhoneOTPRepository, user *models.User, purpose string, ttl time.Duration) *models.PhoneOTP {
+	t.Helper()
+
+	otp := &models.PhoneOTP{
+		UserID:    user.ID,
+		Phone:     user.Phone,
+		Purpose:   purpose,
+		CodeHash:  "hash",
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := repo.Create(context.Background(), otp); err != nil {
+		t.Fatalf("Failed to create test OTP: %v", err)
+	}
+	return otp
+}
+
+func TestPhoneOTPRepository_GetActiveByPhone(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewPhoneOTPRepository(db)
+	ctx := context.Background()
+
+	newTestPhoneOTP(t, repo, user, testOTPPurpose, -time.Minute)
+	if _, err := repo.GetActiveByPhone(ctx, testOTPPurpose, user.Phone); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected expired OTP to be ignored, got %v", err)
+	}
+
+	newTestPhoneOTP(t, repo, user, testOTPPurpose, time.Hour)
+	latest := newTestPhoneOTP(t, repo, user, testOTPPurpose, time.Hour)
+	pinReset := newTestPhoneOTP(t, repo, user, constants.OTPPurposePINReset, time.Hour)
+
+	active, err := repo.GetActiveByPhone(ctx, testOTPPurpose, user.Phone)
+	if err != nil {
+		t.Fatalf("GetActiveByPhone returned error: %v", err)
+	}
+	if active.ID != latest.ID {
+		t.Errorf("Expected latest OTP %s, got %s", latest.ID, active.ID)
+	}
+
+	if err := repo.InvalidateByPhone(ctx, testOTPPurpose, user.Phone); err != nil {
+		t.Fatalf("InvalidateByPhone returned error: %v", err)
+	}
+	if _, err := repo.GetActiveByPhone(ctx, testOTPPurpose, user.Phone); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected no active OTP after invalidation, got %v", err)
+	}
+
+	// Codes sent for another purpose are neither returned nor invalidated
+	active, err = repo.GetActiveByPhone(ctx, constants.OTPPurposePINReset, user.Phone)
+	if err != nil {
+		t.Fatalf("GetActiveByPhone returned error for PIN reset code: %v", err)
+	}
+	if active.ID != pinReset.ID || active.Purpose != constants.OTPPurposePINReset {
+		t.Errorf("Expected PIN reset OTP %s, got %s with purpose %q", pinReset.ID, active.ID, active.Purpose)
+	}
+}
+
+func TestPhoneOTPRepository_RecordAttemptAndConsume(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewPhoneOTPRepository(db)
+	ctx := context.Background()
+
+	otp := newTestPhoneOTP(t, repo, user, testOTPPurpose, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if err := repo.RecordAttempt(ctx, otp.ID, 2); err != nil {
+			t.Fatalf("RecordAttempt %d returned error: %v", i+1, err)
+		}
+	}
+	if err := repo.RecordAttempt(ctx, otp.ID, 2); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected attempts to be exhausted, got %v", err)
+	}
+
+	if err := repo.Consume(ctx, otp.ID); err != nil {
+		t.Fatalf("Consume returned error: %v", err)
+	}
+	if err := repo.Consume(ctx, otp.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected second Consume to fail with domain.ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq" // PostgreSQL driver
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+)
+
+// testDB is the migrated database shared by the repository integration tests.
+// It stays nil when TEST_DATABASE_URL is not set.
+var testDB *sqlx.DB
+
+const migrationDir = "file://../../database/migrations"
+
+func TestMain(m *testing.M) {
+	helpers.SetupLogger()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn != "" {
+		db, err := openTestDB(dsn)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to prepare test database: %v\n", err)
+			os.Exit(1)
+		}
+		testDB = db
+	}
+
+	code := m.Run()
+
+	if testDB != nil {
+		_ = testDB.Close()
+	}
+	os.Exit(code)
+}
+
+// openTestDB applies every migration and opens a connection, so the tests
+// always run against the schema the service migrates itself.
+func openTestDB(dsn string) (*sqlx.DB, error) {
+	mig, err := migrate.New(migrationDir, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration instance: %w", err)
+	}
+	defer mig.Close()
+
+	if err := mig.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	db, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
+
+// requireDB skips the test when no integration database is configured.
+func requireDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	if testDB == nil {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	return testDB
+}
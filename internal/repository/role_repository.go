@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+)
+
+// RoleRepository implements IRoleRepository.
+type RoleRepository struct {
+	db *sqlx.DB
+}
+
+// NewRoleRepository creates a new role repository.
+func NewRoleRepository(db *sqlx.DB) *RoleRepository {
+	return &RoleRepository{
+		db: db,
+	}
+}
+
+// GetRolesByUserID retrieves the names of the roles granted to a user.
+func (r *RoleRepository) GetRolesByUserID(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT r.name
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = $1
+		ORDER BY r.name
+	`
+
+	roles := []string{}
+	err := r.db.SelectContext(ctx, &roles, query, userID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to get roles for user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// GetPermissionsByUserID retrieves the distinct permission names granted through the user's roles.
+func (r *RoleRepository) GetPermissionsByUserID(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT DISTINCT p.name
+		FROM user_roles ur
+		JOIN role_permissions rp ON rp.role_id = ur.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE ur.user_id = $1
+		ORDER BY p.name
+	`
+
+	permissions := []string{}
+	err := r.db.SelectContext(ctx, &permissions, query, userID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to get permissions for user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// AssignRole grants a role to a user. Granting a role the user already has is a no-op.
+func (r *RoleRepository) AssignRole(ctx context.Context, userID uuid.UUID, roleName string) error {
+	return assignRole(ctx, r.db, userID, roleName)
+}
+
+func assignRole(ctx context.Context, db sqlx.ExtContext, userID uuid.UUID, roleName string) error {
+	query := `
+		INSERT INTO user_roles (user_id, role_id)
+		SELECT $1, id FROM roles WHERE name = $2
+		ON CONFLICT (user_id, role_id) DO NOTHING
+	`
+
+	result, err := db.ExecContext(ctx, query, userID, roleName)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to assign role %s to user %s: %v", roleName, userID, err)
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// Either the role is unknown or it was already granted
+		var exists bool
+		if err := sqlx.GetContext(ctx, db, &exists, "SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)", roleName); err != nil {
+			return fmt.Errorf("failed to check role: %w", err)
+		}
+		if !exists {
+			return domain.NewError(domain.ErrNotFound, fmt.Sprintf("role %s not found", roleName))
+		}
+	}
+
+	return nil
+}
+
+// RemoveRole revokes a role from a user.
+func (r *RoleRepository) RemoveRole(ctx context.Context, userID uuid.UUID, roleName string) error {
+	query := `
+		DELETE FROM user_roles
+		WHERE user_id = $1 AND role_id = (SELECT id FROM roles WHERE name = $2)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, roleName)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to remove role %s from user %s: %v", roleName, userID, err)
+		return fmt.Errorf("failed to remove role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "user role not found")
+	}
+
+	return nil
+}
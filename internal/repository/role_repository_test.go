@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+)
+
+func TestRoleRepository_AssignAndPermissions(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewRoleRepository(db)
+	ctx := context.Background()
+
+	if err := repo.AssignRole(ctx, user.ID, "support"); err != nil {
+		t.Fatalf("AssignRole returned error: %v", err)
+	}
+	if err := repo.AssignRole(ctx, user.ID, "support"); err != nil {
+		t.Errorf("Expected assigning a granted role to be a no-op, got %v", err)
+	}
+
+	roles, err := repo.GetRolesByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetRolesByUserID returned error: %v", err)
+	}
+	if !slices.Equal(roles, []string{"support"}) {
+		t.Errorf("Expected roles [support], got %v", roles)
+	}
+
+	permissions, err := repo.GetPermissionsByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetPermissionsByUserID returned error: %v", err)
+	}
+	if !slices.Contains(permissions, "users:read") || slices.Contains(permissions, "users:delete") {
+		t.Errorf("Unexpected support permissions: %v", permissions)
+	}
+}
+
+func TestRoleRepository_UnknownRole(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewRoleRepository(db)
+
+	if err := repo.AssignRole(context.Background(), user.ID, "superuser"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected domain.ErrNotFound for unknown role, got %v", err)
+	}
+}
+
+func TestRoleRepository_RemoveRole(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewRoleRepository(db)
+	ctx := context.Background()
+
+	if err := repo.AssignRole(ctx, user.ID, "admin"); err != nil {
+		t.Fatalf("AssignRole returned error: %v", err)
+	}
+	if err := repo.RemoveRole(ctx, user.ID, "admin"); err != nil {
+		t.Fatalf("RemoveRole returned error: %v", err)
+	}
+
+	permissions, err := repo.GetPermissionsByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetPermissionsByUserID returned error: %v", err)
+	}
+	if len(permissions) != 0 {
+		t.Errorf("Expected no permissions after removing role, got %v", permissions)
+	}
+
+	if err := repo.RemoveRole(ctx, user.ID, "admin"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected domain.ErrNotFound removing a role twice, got %v", err)
+	}
+}
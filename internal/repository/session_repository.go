@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+const sessionColumns = `
+	id, user_id, access_token, refresh_token, access_token_expires_at, refresh_token_expires_at,
+	ip_address, user_agent, device_id, device_name, platform, is_revoked, created_at, updated_at
+`
+
+// SessionRepository implements ISessionRepository.
+type SessionRepository struct {
+	db *sqlx.DB
+}
+
+// NewSessionRepository creates a new session repository.
+func NewSessionRepository(db *sqlx.DB) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+// Create creates a new session.
+func (r *SessionRepository) Create(ctx context.Context, session *models.UserSession) error {
+	query := `
+		INSERT INTO user_sessions (id, user_id, access_token, refresh_token,
+		                           access_token_expires_at, refresh_token_expires_at,
+		                           ip_address, user_agent, device_id, device_name, platform, is_revoked)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowxContext(
+		ctx,
+		query,
+		session.ID,
+		session.UserID,
+		session.AccessToken,
+		session.RefreshToken,
+		session.AccessTokenExpiresAt,
+		session.RefreshTokenExpiresAt,
+		session.IPAddress,
+		session.UserAgent,
+		session.DeviceID,
+		session.DeviceName,
+		session.Platform,
+		session.IsRevoked,
+	).Scan(&session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to create session for user %s: %v", session.UserID, err)
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	helpers.Logger.Infof("Session %s created for user %s", session.ID, session.UserID)
+	return nil
+}
+
+// GetByAccessToken retrieves a session by access token hash.
+func (r *SessionRepository) GetByAccessToken(ctx context.Context, accessTokenHash string) (*models.UserSession, error) {
+	query := `SELECT ` + sessionColumns + ` FROM user_sessions WHERE access_token = $1`
+
+	var session models.UserSession
+	err := r.db.GetContext(ctx, &session, query, accessTokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewError(domain.ErrNotFound, "session not found")
+		}
+		helpers.Logger.Errorf("Failed to get session by access token: %v", err)
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// GetByRefreshToken retrieves a session by refresh token hash.
+func (r *SessionRepository) GetByRefreshToken(ctx context.Context, refreshTokenHash string) (*models.UserSession, error) {
+	query := `SELECT ` + sessionColumns + ` FROM user_sessions WHERE refresh_token = $1`
+
+	var session models.UserSession
+	err := r.db.GetContext(ctx, &session, query, refreshTokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewError(domain.ErrNotFound, "session not found")
+		}
+		helpers.Logger.Errorf("Failed to get session by refresh token: %v", err)
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// GetByRotatedRefreshToken retrieves the session that issued an already rotated refresh token hash.
+func (r *SessionRepository) GetByRotatedRefreshToken(ctx context.Context, refreshTokenHash string) (*models.UserSession, error) {
+	query := `
+		SELECT s.id, s.user_id, s.access_token, s.refresh_token, s.access_token_expires_at,
+		       s.refresh_token_expires_at, s.ip_address, s.user_agent, s.device_id, s.device_name,
+		       s.platform, s.is_revoked, s.created_at, s.updated_at
+		FROM user_session_rotated_tokens rt
+		JOIN user_sessions s ON s.id = rt.session_id
+		WHERE rt.refresh_token = $1
+	`
+
+	var session models.UserSession
+	err := r.db.GetContext(ctx, &session, query, refreshTokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewError(domain.ErrNotFound, "session not found")
+		}
+		helpers.Logger.Errorf("Failed to get session by rotated refresh token: %v", err)
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// Rotate replaces the tokens of a session and records the previous refresh token hash.
+// It fails when the session was revoked or already rotated away from previousRefreshTokenHash.
+func (r *SessionRepository) Rotate(ctx context.Context, session *models.UserSession, previousRefreshTokenHash string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		UPDATE user_sessions
+		SET access_token = $1, refresh_token = $2, access_token_expires_at = $3,
+		    refresh_token_expires_at = $4, ip_address = $5, user_agent = $6, updated_at = $7
+		WHERE id = $8 AND refresh_token = $9 AND is_revoked = FALSE
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowxContext(
+		ctx,
+		query,
+		session.AccessToken,
+		session.RefreshToken,
+		session.AccessTokenExpiresAt,
+		session.RefreshTokenExpiresAt,
+		session.IPAddress,
+		session.UserAgent,
+		time.Now(),
+		session.ID,
+		previousRefreshTokenHash,
+	).Scan(&session.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.NewError(domain.ErrNotFound, "session not found")
+		}
+		helpers.Logger.Errorf("Failed to rotate session %s: %v", session.ID, err)
+		return fmt.Errorf("failed to rotate session: %w", err)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO user_session_rotated_tokens (refresh_token, session_id) VALUES ($1, $2)",
+		previousRefreshTokenHash,
+		session.ID,
+	)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to record rotated token of session %s: %v", session.ID, err)
+		return fmt.Errorf("failed to rotate session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	helpers.Logger.Infof("Session %s rotated", session.ID)
+	return nil
+}
+
+// Revoke revokes a single session.
+func (r *SessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE user_sessions
+		SET is_revoked = TRUE, updated_at = $1
+		WHERE id = $2 AND is_revoked = FALSE
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to revoke session %s: %v", id, err)
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "session not found")
+	}
+
+	helpers.Logger.Infof("Session %s revoked", id)
+	return nil
+}
+
+// RevokeAllByUserID revokes every active session of a user.
+func (r *SessionRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE user_sessions
+		SET is_revoked = TRUE, updated_at = $1
+		WHERE user_id = $2 AND is_revoked = FALSE
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), userID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to revoke sessions of user %s: %v", userID, err)
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	helpers.Logger.Infof("Revoked %d sessions of user %s", rowsAffected, userID)
+	return nil
+}
+
+// RevokeOthersByUserID revokes every active session of a user except the given one.
+func (r *SessionRepository) RevokeOthersByUserID(ctx context.Context, userID, keepSessionID uuid.UUID) error {
+	query := `
+		UPDATE user_sessions
+		SET is_revoked = TRUE, updated_at = $1
+		WHERE user_id = $2 AND id <> $3 AND is_revoked = FALSE
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), userID, keepSessionID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to revoke other sessions of user %s: %v", userID, err)
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	helpers.Logger.Infof("Revoked %d other sessions of user %s", rowsAffected, userID)
+	return nil
+}
+
+// RevokeByDeviceID revokes every active session of a user created on the given device.
+func (r *SessionRepository) RevokeByDeviceID(ctx context.Context, userID, deviceID uuid.UUID) error {
+	query := `
+		UPDATE user_sessions
+		SET is_revoked = TRUE, updated_at = $1
+		WHERE user_id = $2 AND device_id = $3 AND is_revoked = FALSE
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), userID, deviceID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to revoke sessions of device %s: %v", deviceID, err)
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	helpers.Logger.Infof("Revoked %d sessions of device %s", rowsAffected, deviceID)
+	return nil
+}
+
+// ListActiveByUserID retrieves unrevoked, unexpired sessions of a user.
+func (r *SessionRepository) ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UserSession, error) {
+	query := `SELECT ` + sessionColumns + `
+		FROM user_sessions
+		WHERE user_id = $1 AND is_revoked = FALSE AND refresh_token_expires_at > $2
+		ORDER BY created_at DESC
+	`
+
+	var sessions []*models.UserSession
+	err := r.db.SelectContext(ctx, &sessions, query, userID, time.Now())
+	if err != nil {
+		helpers.Logger.Errorf("Failed to list sessions of user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// ListRecentByUserID retrieves the latest sessions of a user, revoked and expired ones included,
+// most recently used first.
+func (r *SessionRepository) ListRecentByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*models.UserSession, error) {
+	query := `SELECT ` + sessionColumns + `
+		FROM user_sessions
+		WHERE user_id = $1
+		ORDER BY updated_at DESC
+		LIMIT $2
+	`
+
+	var sessions []*models.UserSession
+	err := r.db.SelectContext(ctx, &sessions, query, userID, limit)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to list session history of user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to list session history: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// DeleteExpired deletes sessions whose refresh token expired before the given time.
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	query := "DELETE FROM user_sessions WHERE refresh_token_expires_at < $1"
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to delete expired sessions: %v", err)
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	helpers.Logger.Infof("Deleted %d expired sessions", rowsAffected)
+	return rowsAffected, nil
+}
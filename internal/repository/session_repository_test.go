@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// newTestSession creates a session for user with unique token hashes.
+func newTestSession(t *testing.T, repo *SessionRepository, userID uuid.UUID) *models.UserSession {
+	t.Helper()
+
+	now := time.Now()
+	session := &models.UserSession{
+		ID:                    uuid.New(),
+		UserID:                userID,
+		AccessToken:           uuid.NewString(),
+		RefreshToken:          uuid.NewString(),
+		AccessTokenExpiresAt:  now.Add(15 * time.Minute),
+		RefreshTokenExpiresAt: now.Add(24 * time.Hour),
+		IPAddress:             sql.NullString{String: "203.0.113.7", Valid: true},
+		UserAgent:             sql.NullString{String: "test-agent", Valid: true},
+	}
+
+	if err := repo.Create(context.Background(), session); err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+	return session
+}
+
+func TestSessionRepository_Create(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewSessionRepository(db)
+
+	session := newTestSession(t, repo, user.ID)
+
+	if session.CreatedAt.IsZero() || session.UpdatedAt.IsZero() {
+		t.Error("Expected created_at and updated_at to be populated")
+	}
+
+	var stored models.UserSession
+	err := db.Get(&stored, "SELECT "+sessionColumns+" FROM user_sessions WHERE id = $1", session.ID)
+	if err != nil {
+		t.Fatalf("Failed to read session: %v", err)
+	}
+	if stored.AccessToken != session.AccessToken || stored.IPAddress.String != "203.0.113.7" || stored.IsRevoked {
+		t.Errorf("Stored session %+v does not match %+v", stored, session)
+	}
+}
+
+func TestSessionRepository_GetByTokenHash(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	session := newTestSession(t, repo, user.ID)
+
+	byAccess, err := repo.GetByAccessToken(ctx, session.AccessToken)
+	if err != nil {
+		t.Fatalf("GetByAccessToken returned error: %v", err)
+	}
+	if byAccess.ID != session.ID || byAccess.UserID != user.ID {
+		t.Errorf("GetByAccessToken returned %+v, want session %s", byAccess, session.ID)
+	}
+
+	byRefresh, err := repo.GetByRefreshToken(ctx, session.RefreshToken)
+	if err != nil {
+		t.Fatalf("GetByRefreshToken returned error: %v", err)
+	}
+	if byRefresh.ID != session.ID {
+		t.Errorf("GetByRefreshToken returned session %s, want %s", byRefresh.ID, session.ID)
+	}
+
+	if _, err := repo.GetByAccessToken(ctx, "unknown"); err == nil {
+		t.Error("Expected error for unknown access token, got nil")
+	}
+}
+
+func TestSessionRepository_Revoke(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	session := newTestSession(t, repo, user.ID)
+
+	if err := repo.Revoke(ctx, session.ID); err != nil {
+		t.Fatalf("Revoke returned error: %v", err)
+	}
+
+	got, err := repo.GetByAccessToken(ctx, session.AccessToken)
+	if err != nil {
+		t.Fatalf("GetByAccessToken returned error: %v", err)
+	}
+	if !got.IsRevoked {
+		t.Error("Expected session to be revoked")
+	}
+
+	if err := repo.Revoke(ctx, session.ID); err == nil {
+		t.Error("Expected error revoking an already revoked session, got nil")
+	}
+}
+
+func TestSessionRepository_RevokeAllAndListActive(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	newTestSession(t, repo, user.ID)
+	newTestSession(t, repo, user.ID)
+
+	active, err := repo.ListActiveByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListActiveByUserID returned error: %v", err)
+	}
+	if len(active) != 2 {
+		t.Fatalf("Expected 2 active sessions, got %d", len(active))
+	}
+
+	if err := repo.RevokeAllByUserID(ctx, user.ID); err != nil {
+		t.Fatalf("RevokeAllByUserID returned error: %v", err)
+	}
+
+	active, err = repo.ListActiveByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListActiveByUserID returned error: %v", err)
+	}
+	if len(active) != 0 {
+		t.Errorf("Expected no active sessions after revoking all, got %d", len(active))
+	}
+}
+
+func TestSessionRepository_RevokeOthersByUserID(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	current := newTestSession(t, repo, user.ID)
+	newTestSession(t, repo, user.ID)
+
+	if err := repo.RevokeOthersByUserID(ctx, user.ID, current.ID); err != nil {
+		t.Fatalf("RevokeOthersByUserID returned error: %v", err)
+	}
+
+	active, err := repo.ListActiveByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListActiveByUserID returned error: %v", err)
+	}
+	if len(active) != 1 || active[0].ID != current.ID {
+		t.Errorf("Expected only the current session to stay active, got %d sessions", len(active))
+	}
+}
+
+func TestSessionRepository_ListRecentByUserID(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	revoked := newTestSession(t, repo, user.ID)
+	if err := repo.Revoke(ctx, revoked.ID); err != nil {
+		t.Fatalf("Revoke returned error: %v", err)
+	}
+	newTestSession(t, repo, user.ID)
+	newTestSession(t, repo, user.ID)
+
+	recent, err := repo.ListRecentByUserID(ctx, user.ID, 2)
+	if err != nil {
+		t.Fatalf("ListRecentByUserID returned error: %v", err)
+	}
+	if len(recent) != 2 {
+		t.Fatalf("Expected the limit of 2 sessions, got %d", len(recent))
+	}
+
+	recent, err = repo.ListRecentByUserID(ctx, user.ID, 10)
+	if err != nil {
+		t.Fatalf("ListRecentByUserID returned error: %v", err)
+	}
+	if len(recent) != 3 || recent[0].UpdatedAt.Before(recent[2].UpdatedAt) {
+		t.Errorf("Expected 3 sessions including the revoked one, most recent first, got %d", len(recent))
+	}
+}
+
+func TestSessionRepository_DeleteExpired(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	session := newTestSession(t, repo, user.ID)
+
+	deleted, err := repo.DeleteExpired(ctx, session.RefreshTokenExpiresAt.Add(time.Second))
+	if err != nil {
+		t.Fatalf("DeleteExpired returned error: %v", err)
+	}
+	if deleted < 1 {
+		t.Errorf("Expected at least 1 deleted session, got %d", deleted)
+	}
+
+	if _, err := repo.GetByAccessToken(ctx, session.AccessToken); err == nil {
+		t.Error("Expected expired session to be deleted")
+	}
+}
+
+func TestSessionRepository_Rotate(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	session := newTestSession(t, repo, user.ID)
+	previousRefreshToken := session.RefreshToken
+	session.AccessToken = uuid.NewString()
+	session.RefreshToken = uuid.NewString()
+
+	if err := repo.Rotate(ctx, session, previousRefreshToken); err != nil {
+		t.Fatalf("Rotate returned error: %v", err)
+	}
+
+	if _, err := repo.GetByRefreshToken(ctx, previousRefreshToken); err == nil {
+		t.Error("Expected previous refresh token to be replaced")
+	}
+
+	rotated, err := repo.GetByRotatedRefreshToken(ctx, previousRefreshToken)
+	if err != nil {
+		t.Fatalf("GetByRotatedRefreshToken returned error: %v", err)
+	}
+	if rotated.ID != session.ID {
+		t.Errorf("GetByRotatedRefreshToken returned session %s, want %s", rotated.ID, session.ID)
+	}
+
+	// Rotating from a stale refresh token must fail
+	if err := repo.Rotate(ctx, session, previousRefreshToken); err == nil {
+		t.Error("Expected error rotating from an already rotated refresh token, got nil")
+	}
+}
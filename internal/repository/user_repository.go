@@ -8,13 +8,27 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
 	"github.com/ibnuzaman/ewallet-ums/internal/models"
 )
 
+const userColumns = `id, email, phone_number, full_name, password_hash, is_active, is_verified, is_phone_verified,
+		is_mfa_enabled, failed_login_attempts, lockout_count, locked_until, hard_locked_at, pin_hash, pin_failed_attempts,
+		pin_locked_until, pin_updated_at, deactivated_at, created_at, updated_at, deleted_at`
+
+// userConflicts are the messages for the unique constraints of users.
+var userConflicts = map[string]string{
+	"idx_users_email":        "email already registered",
+	"idx_users_phone_number": "phone number already registered",
+}
+
 // UserRepository implements IUserRepository.
+// Operations on a missing or deleted user return an error of kind domain.ErrNotFound, and
+// taken email addresses or phone numbers one of kind domain.ErrConflict.
 type UserRepository struct {
 	db *sqlx.DB
 }
@@ -28,13 +42,48 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 
 // Create creates a new user.
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	if err := insertUser(ctx, r.db, user); err != nil {
+		return err
+	}
+
+	helpers.Logger.Infof("User created successfully with ID: %s", user.ID)
+	return nil
+}
+
+// CreateWithRole creates a new user and grants it a role in one transaction, so a user
+// never exists without its role.
+func (r *UserRepository) CreateWithRole(ctx context.Context, user *models.User, roleName string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := insertUser(ctx, tx, user); err != nil {
+		return err
+	}
+	if err := assignRole(ctx, tx, user.ID, roleName); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	helpers.Logger.Infof("User created successfully with ID: %s and role %s", user.ID, roleName)
+	return nil
+}
+
+func insertUser(ctx context.Context, q sqlx.QueryerContext, user *models.User) error {
 	query := `
-		INSERT INTO users (email, phone, full_name, password_hash, is_active, is_verified)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO users (email, phone_number, full_name, password_hash, is_active, is_verified, is_phone_verified)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRowxContext(
+	err := q.QueryRowxContext(
 		ctx,
 		query,
 		user.Email,
@@ -43,21 +92,20 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 		user.PasswordHash,
 		user.IsActive,
 		user.IsVerified,
+		user.IsPhoneVerified,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		helpers.Logger.Errorf("Failed to create user: %v", err)
-		return fmt.Errorf("failed to create user: %w", err)
+		return fmt.Errorf("failed to create user: %w", mapUniqueViolation(err, userConflicts))
 	}
 
-	helpers.Logger.Infof("User created successfully with ID: %d", user.ID)
 	return nil
 }
 
 // GetByID retrieves a user by ID.
-func (r *UserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	query := `
-		SELECT id, email, phone, full_name, password_hash, is_active, is_verified,
-		       created_at, updated_at, deleted_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -66,9 +114,9 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (*models.User, e
 	err := r.db.GetContext(ctx, &user, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
+			return nil, domain.NewError(domain.ErrNotFound, "user not found")
 		}
-		helpers.Logger.Errorf("Failed to get user by ID %d: %v", id, err)
+		helpers.Logger.Errorf("Failed to get user by ID %s: %v", id, err)
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
@@ -78,8 +126,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (*models.User, e
 // GetByEmail retrieves a user by email.
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, email, phone, full_name, password_hash, is_active, is_verified,
-		       created_at, updated_at, deleted_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE email = $1 AND deleted_at IS NULL
 	`
@@ -88,7 +135,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 	err := r.db.GetContext(ctx, &user, query, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
+			return nil, domain.NewError(domain.ErrNotFound, "user not found")
 		}
 		helpers.Logger.Errorf("Failed to get user by email %s: %v", email, err)
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -100,17 +147,16 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 // GetByPhone retrieves a user by phone.
 func (r *UserRepository) GetByPhone(ctx context.Context, phone string) (*models.User, error) {
 	query := `
-		SELECT id, email, phone, full_name, password_hash, is_active, is_verified,
-		       created_at, updated_at, deleted_at
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE phone = $1 AND deleted_at IS NULL
+		WHERE phone_number = $1 AND deleted_at IS NULL
 	`
 
 	var user models.User
 	err := r.db.GetContext(ctx, &user, query, phone)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
+			return nil, domain.NewError(domain.ErrNotFound, "user not found")
 		}
 		helpers.Logger.Errorf("Failed to get user by phone %s: %v", phone, err)
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -123,10 +169,12 @@ func (r *UserRepository) GetByPhone(ctx context.Context, phone string) (*models.
 func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users
-		SET email = $1, phone = $2, full_name = $3, is_active = $4, is_verified = $5, updated_at = $6
-		WHERE id = $7 AND deleted_at IS NULL
+		SET email = $1, phone_number = $2, full_name = $3, is_active = $4, is_verified = $5,
+		    is_phone_verified = $6, deactivated_at = $7, updated_at = $8
+		WHERE id = $9 AND deleted_at IS NULL
 	`
 
+	now := time.Now()
 	result, err := r.db.ExecContext(
 		ctx,
 		query,
@@ -135,12 +183,149 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 		user.FullName,
 		user.IsActive,
 		user.IsVerified,
-		time.Now(),
+		user.IsPhoneVerified,
+		user.DeactivatedAt,
+		now,
 		user.ID,
 	)
 	if err != nil {
-		helpers.Logger.Errorf("Failed to update user %d: %v", user.ID, err)
-		return fmt.Errorf("failed to update user: %w", err)
+		helpers.Logger.Errorf("Failed to update user %s: %v", user.ID, err)
+		return fmt.Errorf("failed to update user: %w", mapUniqueViolation(err, userConflicts))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "user not found")
+	}
+
+	user.UpdatedAt = now
+
+	helpers.Logger.Infof("User %s updated successfully", user.ID)
+	return nil
+}
+
+// UpdatePassword replaces the password hash of a user. Update leaves password_hash
+// untouched so that profile changes cannot overwrite it by accident.
+func (r *UserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	query := `
+		UPDATE users
+		SET password_hash = $1, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, passwordHash, time.Now(), id)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to update password of user %s: %v", id, err)
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "user not found")
+	}
+
+	helpers.Logger.Infof("Password of user %s updated successfully", id)
+	return nil
+}
+
+// RecordFailedLogin increments the failed login counter of a user and returns the new count.
+// The increment is atomic, so concurrent failures are all counted.
+func (r *UserRepository) RecordFailedLogin(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `
+		UPDATE users
+		SET failed_login_attempts = failed_login_attempts + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING failed_login_attempts
+	`
+
+	var attempts int
+	err := r.db.GetContext(ctx, &attempts, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, domain.NewError(domain.ErrNotFound, "user not found")
+		}
+		helpers.Logger.Errorf("Failed to record failed login of user %s: %v", id, err)
+		return 0, fmt.Errorf("failed to record failed login: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// Lock locks a user out until the given time, recording the number of lockouts so far
+// and starting a new count of failed logins.
+func (r *UserRepository) Lock(ctx context.Context, id uuid.UUID, lockoutCount int, until time.Time) error {
+	query := `
+		UPDATE users
+		SET failed_login_attempts = 0, lockout_count = $1, locked_until = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	return r.execLockout(ctx, "lock", query, id, lockoutCount, until, id)
+}
+
+// HardLock locks a user out until Unlock is called.
+func (r *UserRepository) HardLock(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET failed_login_attempts = 0, locked_until = NULL, hard_locked_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	return r.execLockout(ctx, "hard lock", query, id, time.Now(), id)
+}
+
+// Unlock clears the failed login counters and any lock of a user.
+func (r *UserRepository) Unlock(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET failed_login_attempts = 0, lockout_count = 0, locked_until = NULL, hard_locked_at = NULL
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	return r.execLockout(ctx, "unlock", query, id, id)
+}
+
+// execLockout runs a lockout update of the user id and reports a missing user.
+func (r *UserRepository) execLockout(ctx context.Context, action, query string, id uuid.UUID, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to %s user %s: %v", action, id, err)
+		return fmt.Errorf("failed to %s user: %w", action, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "user not found")
+	}
+
+	return nil
+}
+
+// UpdatePIN replaces the transaction PIN hash of a user and clears the PIN lockout.
+// Like password_hash, pin_hash is never written by Update.
+func (r *UserRepository) UpdatePIN(ctx context.Context, id uuid.UUID, pinHash string) error {
+	query := `
+		UPDATE users
+		SET pin_hash = $1, pin_updated_at = $2, pin_failed_attempts = 0, pin_locked_until = NULL, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, pinHash, time.Now(), id)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to update PIN of user %s: %v", id, err)
+		return fmt.Errorf("failed to update PIN: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -149,15 +334,61 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return domain.NewError(domain.ErrNotFound, "user not found")
 	}
 
-	helpers.Logger.Infof("User %d updated successfully", user.ID)
+	helpers.Logger.Infof("PIN of user %s updated successfully", id)
 	return nil
 }
 
+// RecordFailedPIN increments the failed PIN counter of a user and returns the new count.
+// The increment is atomic, so concurrent wrong PINs are all counted.
+func (r *UserRepository) RecordFailedPIN(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `
+		UPDATE users
+		SET pin_failed_attempts = pin_failed_attempts + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING pin_failed_attempts
+	`
+
+	var attempts int
+	err := r.db.GetContext(ctx, &attempts, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, domain.NewError(domain.ErrNotFound, "user not found")
+		}
+		helpers.Logger.Errorf("Failed to record failed PIN of user %s: %v", id, err)
+		return 0, fmt.Errorf("failed to record failed PIN: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// LockPIN stops PIN verification of a user until the given time and starts a new count
+// of failed PINs.
+func (r *UserRepository) LockPIN(ctx context.Context, id uuid.UUID, until time.Time) error {
+	query := `
+		UPDATE users
+		SET pin_failed_attempts = 0, pin_locked_until = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	return r.execLockout(ctx, "lock PIN of", query, id, until, id)
+}
+
+// ClearPINFailures resets the failed PIN counter and any PIN lock of a user.
+func (r *UserRepository) ClearPINFailures(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET pin_failed_attempts = 0, pin_locked_until = NULL
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	return r.execLockout(ctx, "clear PIN failures of", query, id, id)
+}
+
 // Delete soft deletes a user.
-func (r *UserRepository) Delete(ctx context.Context, id int64) error {
+func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
 		UPDATE users
 		SET deleted_at = $1
@@ -166,7 +397,7 @@ func (r *UserRepository) Delete(ctx context.Context, id int64) error {
 
 	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
-		helpers.Logger.Errorf("Failed to delete user %d: %v", id, err)
+		helpers.Logger.Errorf("Failed to delete user %s: %v", id, err)
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
@@ -176,18 +407,17 @@ func (r *UserRepository) Delete(ctx context.Context, id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return domain.NewError(domain.ErrNotFound, "user not found")
 	}
 
-	helpers.Logger.Infof("User %d deleted successfully", id)
+	helpers.Logger.Infof("User %s deleted successfully", id)
 	return nil
 }
 
 // List retrieves users based on filters.
 func (r *UserRepository) List(ctx context.Context, filter models.UserFilter) ([]*models.User, error) {
 	query := `
-		SELECT id, email, phone, full_name, password_hash, is_active, is_verified,
-		       created_at, updated_at, deleted_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE deleted_at IS NULL
 	`
@@ -203,7 +433,7 @@ func (r *UserRepository) List(ctx context.Context, filter models.UserFilter) ([]
 	}
 
 	if filter.Phone != "" {
-		conditions = append(conditions, fmt.Sprintf("phone = $%d", argIndex))
+		conditions = append(conditions, fmt.Sprintf("phone_number = $%d", argIndex))
 		args = append(args, filter.Phone)
 		argIndex++
 	}
@@ -262,7 +492,7 @@ func (r *UserRepository) Count(ctx context.Context, filter models.UserFilter) (i
 	}
 
 	if filter.Phone != "" {
-		conditions = append(conditions, fmt.Sprintf("phone = $%d", argIndex))
+		conditions = append(conditions, fmt.Sprintf("phone_number = $%d", argIndex))
 		args = append(args, filter.Phone)
 		argIndex++
 	}
@@ -0,0 +1,393 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// newTestUser creates a user with a unique email and phone and removes it
+// when the test finishes.
+func newTestUser(t *testing.T, repo *UserRepository) *models.User {
+	t.Helper()
+
+	user := &models.User{
+		Email:        fmt.Sprintf("test-%s@example.com", uuid.NewString()),
+		Phone:        fmt.Sprintf("+628%010d", uuid.New().ID()),
+		FullName:     "Test User",
+		PasswordHash: "hash",
+		IsActive:     true,
+	}
+
+	if err := repo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_, _ = repo.db.Exec("DELETE FROM users WHERE id = $1", user.ID)
+	})
+
+	return user
+}
+
+func TestUserRepository_CreateAndGet(t *testing.T) {
+	repo := NewUserRepository(requireDB(t))
+	ctx := context.Background()
+
+	user := newTestUser(t, repo)
+
+	if user.ID == uuid.Nil {
+		t.Fatal("Expected generated UUID, got nil UUID")
+	}
+	if user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+		t.Error("Expected created_at and updated_at to be populated")
+	}
+
+	byID, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if byID.Email != user.Email || byID.Phone != user.Phone || byID.PasswordHash != user.PasswordHash {
+		t.Errorf("GetByID returned %+v, want %+v", byID, user)
+	}
+	if !byID.IsActive || byID.IsVerified || byID.DeletedAt.Valid {
+		t.Errorf("Unexpected lifecycle columns: active=%v verified=%v deleted=%v",
+			byID.IsActive, byID.IsVerified, byID.DeletedAt.Valid)
+	}
+
+	byEmail, err := repo.GetByEmail(ctx, user.Email)
+	if err != nil {
+		t.Fatalf("GetByEmail returned error: %v", err)
+	}
+	if byEmail.ID != user.ID {
+		t.Errorf("GetByEmail returned ID %s, want %s", byEmail.ID, user.ID)
+	}
+
+	byPhone, err := repo.GetByPhone(ctx, user.Phone)
+	if err != nil {
+		t.Fatalf("GetByPhone returned error: %v", err)
+	}
+	if byPhone.ID != user.ID {
+		t.Errorf("GetByPhone returned ID %s, want %s", byPhone.ID, user.ID)
+	}
+}
+
+func TestUserRepository_GetByID_NotFound(t *testing.T) {
+	repo := NewUserRepository(requireDB(t))
+
+	if _, err := repo.GetByID(context.Background(), uuid.New()); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected domain.ErrNotFound for unknown ID, got %v", err)
+	}
+}
+
+func TestUserRepository_CreateWithRole(t *testing.T) {
+	db := requireDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{
+		Email:        fmt.Sprintf("test-%s@example.com", uuid.NewString()),
+		Phone:        fmt.Sprintf("+628%010d", uuid.New().ID()),
+		FullName:     "Test User",
+		PasswordHash: "hash",
+	}
+	if err := repo.CreateWithRole(ctx, user, "customer"); err != nil {
+		t.Fatalf("CreateWithRole returned error: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = db.Exec("DELETE FROM users WHERE id = $1", user.ID)
+	})
+
+	roles, err := NewRoleRepository(db).GetRolesByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetRolesByUserID returned error: %v", err)
+	}
+	if !slices.Equal(roles, []string{"customer"}) {
+		t.Errorf("Expected roles [customer], got %v", roles)
+	}
+}
+
+func TestUserRepository_CreateWithRole_UnknownRole(t *testing.T) {
+	db := requireDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{
+		Email:        fmt.Sprintf("test-%s@example.com", uuid.NewString()),
+		Phone:        fmt.Sprintf("+628%010d", uuid.New().ID()),
+		FullName:     "Test User",
+		PasswordHash: "hash",
+	}
+	err := repo.CreateWithRole(ctx, user, "superuser")
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected domain.ErrNotFound for unknown role, got %v", err)
+	}
+
+	if _, err := repo.GetByEmail(ctx, user.Email); !errors.Is(err, domain.ErrNotFound) {
+		_, _ = db.Exec("DELETE FROM users WHERE email = $1", user.Email)
+		t.Errorf("Expected the user to be rolled back, got %v", err)
+	}
+}
+
+func TestUserRepository_Create_DuplicatePhone(t *testing.T) {
+	repo := NewUserRepository(requireDB(t))
+
+	user := newTestUser(t, repo)
+	duplicate := &models.User{
+		Email:        fmt.Sprintf("test-%s@example.com", uuid.NewString()),
+		Phone:        user.Phone,
+		FullName:     "Duplicate",
+		PasswordHash: "hash",
+	}
+
+	err := repo.Create(context.Background(), duplicate)
+	if err == nil {
+		_, _ = repo.db.Exec("DELETE FROM users WHERE id = $1", duplicate.ID)
+	}
+	if !errors.Is(err, domain.ErrConflict) || !strings.Contains(err.Error(), "phone number already registered") {
+		t.Errorf("Expected a phone number conflict, got %v", err)
+	}
+}
+
+func TestUserRepository_Create_EmailOfDeletedUser(t *testing.T) {
+	repo := NewUserRepository(requireDB(t))
+	ctx := context.Background()
+
+	deleted := newTestUser(t, repo)
+	if err := repo.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	user := &models.User{
+		Email:        deleted.Email,
+		Phone:        fmt.Sprintf("+628%010d", uuid.New().ID()),
+		FullName:     "New Owner",
+		PasswordHash: "hash",
+	}
+
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("Expected the email of a deleted user to be available, got %v", err)
+	}
+	_, _ = repo.db.Exec("DELETE FROM users WHERE id = $1", user.ID)
+
+	other := newTestUser(t, repo)
+	duplicate := &models.User{
+		Email:        other.Email,
+		Phone:        fmt.Sprintf("+628%010d", uuid.New().ID()),
+		FullName:     "Duplicate",
+		PasswordHash: "hash",
+	}
+	err := repo.Create(ctx, duplicate)
+	if err == nil {
+		_, _ = repo.db.Exec("DELETE FROM users WHERE id = $1", duplicate.ID)
+	}
+	if !errors.Is(err, domain.ErrConflict) || !strings.Contains(err.Error(), "email already registered") {
+		t.Errorf("Expected an email conflict, got %v", err)
+	}
+}
+
+func TestUserRepository_Update(t *testing.T) {
+	repo := NewUserRepository(requireDB(t))
+	ctx := context.Background()
+
+	user := newTestUser(t, repo)
+	createdUpdatedAt := user.UpdatedAt
+	user.FullName = "Updated Name"
+	user.IsVerified = true
+	deactivatedAt := time.Now()
+	user.DeactivatedAt = &deactivatedAt
+
+	if err := repo.Update(ctx, user); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if !user.UpdatedAt.After(createdUpdatedAt) {
+		t.Error("Expected Update to refresh updated_at on the model")
+	}
+
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if got.FullName != "Updated Name" || !got.IsVerified || got.DeactivatedAt == nil {
+		t.Errorf("Update not persisted: %+v", got)
+	}
+}
+
+func TestUserRepository_UpdatePassword(t *testing.T) {
+	repo := NewUserRepository(requireDB(t))
+	ctx := context.Background()
+
+	user := newTestUser(t, repo)
+
+	if err := repo.UpdatePassword(ctx, user.ID, "new-hash"); err != nil {
+		t.Fatalf("UpdatePassword returned error: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if got.PasswordHash != "new-hash" {
+		t.Errorf("Expected password hash to be updated, got %q", got.PasswordHash)
+	}
+
+	if err := repo.UpdatePassword(ctx, uuid.New(), "hash"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected domain.ErrNotFound for unknown user, got %v", err)
+	}
+}
+
+func TestUserRepository_Lockout(t *testing.T) {
+	repo := NewUserRepository(requireDB(t))
+	ctx := context.Background()
+
+	user := newTestUser(t, repo)
+
+	for want := 1; want <= 2; want++ {
+		attempts, err := repo.RecordFailedLogin(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("RecordFailedLogin returned error: %v", err)
+		}
+		if attempts != want {
+			t.Errorf("Expected %d failed attempts, got %d", want, attempts)
+		}
+	}
+
+	until := time.Now().Add(time.Minute)
+	if err := repo.Lock(ctx, user.ID, 1, until); err != nil {
+		t.Fatalf("Lock returned error: %v", err)
+	}
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if got.FailedLoginAttempts != 0 || got.LockoutCount != 1 ||
+		got.LockedUntil == nil || !got.LockedUntil.Equal(until.Truncate(time.Microsecond)) {
+		t.Errorf("Expected a temporary lock, got %+v", got)
+	}
+
+	if err := repo.HardLock(ctx, user.ID); err != nil {
+		t.Fatalf("HardLock returned error: %v", err)
+	}
+	if got, _ = repo.GetByID(ctx, user.ID); got.HardLockedAt == nil || got.LockedUntil != nil {
+		t.Errorf("Expected a hard lock, got %+v", got)
+	}
+
+	if err := repo.Unlock(ctx, user.ID); err != nil {
+		t.Fatalf("Unlock returned error: %v", err)
+	}
+	if got, _ = repo.GetByID(ctx, user.ID); got.HardLockedAt != nil || got.LockoutCount != 0 || got.FailedLoginAttempts != 0 {
+		t.Errorf("Expected the lockout to be cleared, got %+v", got)
+	}
+
+	if _, err := repo.RecordFailedLogin(ctx, uuid.New()); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected domain.ErrNotFound for unknown user, got %v", err)
+	}
+	if err := repo.Unlock(ctx, uuid.New()); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected domain.ErrNotFound for unknown user, got %v", err)
+	}
+}
+
+func TestUserRepository_PIN(t *testing.T) {
+	repo := NewUserRepository(requireDB(t))
+	ctx := context.Background()
+
+	user := newTestUser(t, repo)
+
+	if err := repo.UpdatePIN(ctx, user.ID, "pin-hash"); err != nil {
+		t.Fatalf("UpdatePIN returned error: %v", err)
+	}
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if got.PINHash.String != "pin-hash" || got.PINUpdatedAt == nil {
+		t.Errorf("Expected the PIN to be set, got %+v", got)
+	}
+
+	for want := 1; want <= 2; want++ {
+		attempts, err := repo.RecordFailedPIN(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("RecordFailedPIN returned error: %v", err)
+		}
+		if attempts != want {
+			t.Errorf("Expected %d failed PINs, got %d", want, attempts)
+		}
+	}
+
+	until := time.Now().Add(time.Minute)
+	if err := repo.LockPIN(ctx, user.ID, until); err != nil {
+		t.Fatalf("LockPIN returned error: %v", err)
+	}
+	if got, _ = repo.GetByID(ctx, user.ID); got.PINFailedAttempts != 0 || got.PINLockedUntil == nil {
+		t.Errorf("Expected the PIN to be locked, got %+v", got)
+	}
+	if got.FailedLoginAttempts != 0 || got.LockedUntil != nil {
+		t.Errorf("Expected the login lockout to be untouched, got %+v", got)
+	}
+
+	if err := repo.ClearPINFailures(ctx, user.ID); err != nil {
+		t.Fatalf("ClearPINFailures returned error: %v", err)
+	}
+	if got, _ = repo.GetByID(ctx, user.ID); got.PINLockedUntil != nil || got.PINHash.String != "pin-hash" {
+		t.Errorf("Expected the PIN lock to be cleared and the PIN kept, got %+v", got)
+	}
+
+	if err := repo.UpdatePIN(ctx, uuid.New(), "pin-hash"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected domain.ErrNotFound for unknown user, got %v", err)
+	}
+	if _, err := repo.RecordFailedPIN(ctx, uuid.New()); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected domain.ErrNotFound for unknown user, got %v", err)
+	}
+}
+
+func TestUserRepository_Delete(t *testing.T) {
+	repo := NewUserRepository(requireDB(t))
+	ctx := context.Background()
+
+	user := newTestUser(t, repo)
+
+	if err := repo.Delete(ctx, user.ID); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+
+	if _, err := repo.GetByID(ctx, user.ID); err == nil {
+		t.Error("Expected soft deleted user to be hidden, got nil error")
+	}
+
+	if err := repo.Delete(ctx, user.ID); err == nil {
+		t.Error("Expected error deleting an already deleted user, got nil")
+	}
+}
+
+func TestUserRepository_ListAndCount(t *testing.T) {
+	repo := NewUserRepository(requireDB(t))
+	ctx := context.Background()
+
+	user := newTestUser(t, repo)
+	active := true
+	filter := models.UserFilter{Email: user.Email, IsActive: &active, Limit: 10}
+
+	users, err := repo.List(ctx, filter)
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(users) != 1 || users[0].ID != user.ID {
+		t.Errorf("List returned %d users, want exactly the test user", len(users))
+	}
+
+	count, err := repo.Count(ctx, models.UserFilter{Phone: user.Phone})
+	if err != nil {
+		t.Fatalf("Count returned error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Count returned %d, want 1", count)
+	}
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+const userTokenColumns = `id, user_id, purpose, token_hash, expires_at, used_at, created_at`
+
+// UserTokenRepository implements IUserTokenRepository.
+type UserTokenRepository struct {
+	db *sqlx.DB
+}
+
+// NewUserTokenRepository creates a new user token repository.
+func NewUserTokenRepository(db *sqlx.DB) *UserTokenRepository {
+	return &UserTokenRepository{
+		db: db,
+	}
+}
+
+// Create creates a new token.
+func (r *UserTokenRepository) Create(ctx context.Context, token *models.UserToken) error {
+	query := `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to create %s token for user %s: %v", token.Purpose, token.UserID, err)
+		return fmt.Errorf("failed to create token: %w", err)
+	}
+
+	return nil
+}
+
+// GetActive retrieves an unused, unexpired token without consuming it.
+func (r *UserTokenRepository) GetActive(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	query := `
+		SELECT ` + userTokenColumns + `
+		FROM user_tokens
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+	`
+
+	var token models.UserToken
+	err := r.db.GetContext(ctx, &token, query, tokenHash, purpose)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewError(domain.ErrNotFound, "token not found")
+		}
+		helpers.Logger.Errorf("Failed to get %s token: %v", purpose, err)
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// Consume marks an unused, unexpired token as used and returns it. The update is
+// atomic, so concurrent requests cannot both consume the same token.
+func (r *UserTokenRepository) Consume(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	query := `
+		UPDATE user_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING ` + userTokenColumns
+
+	var token models.UserToken
+	err := r.db.GetContext(ctx, &token, query, tokenHash, purpose)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewError(domain.ErrNotFound, "token not found")
+		}
+		helpers.Logger.Errorf("Failed to consume %s token: %v", purpose, err)
+		return nil, fmt.Errorf("failed to consume token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// GetLatestByUserID retrieves the most recently created token of a user for a purpose.
+func (r *UserTokenRepository) GetLatestByUserID(ctx context.Context, userID uuid.UUID, purpose string) (*models.UserToken, error) {
+	query := `
+		SELECT ` + userTokenColumns + `
+		FROM user_tokens
+		WHERE user_id = $1 AND purpose = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var token models.UserToken
+	err := r.db.GetContext(ctx, &token, query, userID, purpose)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewError(domain.ErrNotFound, "token not found")
+		}
+		helpers.Logger.Errorf("Failed to get latest %s token for user %s: %v", purpose, userID, err)
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// InvalidateByUserID marks every unused token of a user for a purpose as used.
+func (r *UserTokenRepository) InvalidateByUserID(ctx context.Context, userID uuid.UUID, purpose string) error {
+	query := `
+		UPDATE user_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, purpose); err != nil {
+		helpers.Logger.Errorf("Failed to invalidate %s tokens for user %s: %v", purpose, userID, err)
+		return fmt.Errorf("failed to invalidate tokens: %w", err)
+	}
+
+	return nil
+}
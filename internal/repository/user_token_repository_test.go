@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+const testTokenPurpose = "email_verification"
+
+// newTestUserToken creates a token for user expiring after ttl.
+func newTestUserToken(t *testing.T, repo *UserTokenRepository, userID uuid.UUID, ttl time.Duration) *models.UserToken {
+	t.Helper()
+
+	token := &models.UserToken{
+		UserID:    userID,
+		Purpose:   testTokenPurpose,
+		TokenHash: uuid.NewString(),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := repo.Create(context.Background(), token); err != nil {
+		t.Fatalf("Failed to create test token: %v", err)
+	}
+	return token
+}
+
+func TestUserTokenRepository_Consume(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewUserTokenRepository(db)
+	ctx := context.Background()
+
+	token := newTestUserToken(t, repo, user.ID, time.Hour)
+
+	active, err := repo.GetActive(ctx, testTokenPurpose, token.TokenHash)
+	if err != nil || active.ID != token.ID {
+		t.Fatalf("Expected GetActive to return the token, got %v, %v", active, err)
+	}
+
+	consumed, err := repo.Consume(ctx, testTokenPurpose, token.TokenHash)
+	if err != nil {
+		t.Fatalf("Consume returned error: %v", err)
+	}
+	if consumed.UserID != user.ID || !consumed.UsedAt.Valid {
+		t.Errorf("Unexpected consumed token: %+v", consumed)
+	}
+
+	if _, err := repo.Consume(ctx, testTokenPurpose, token.TokenHash); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected second Consume to fail with domain.ErrNotFound, got %v", err)
+	}
+	if _, err := repo.GetActive(ctx, testTokenPurpose, token.TokenHash); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected consumed token not to be active, got %v", err)
+	}
+}
+
+func TestUserTokenRepository_Consume_ExpiredOrWrongPurpose(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewUserTokenRepository(db)
+	ctx := context.Background()
+
+	expired := newTestUserToken(t, repo, user.ID, -time.Minute)
+	if _, err := repo.Consume(ctx, testTokenPurpose, expired.TokenHash); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected expired token to be rejected, got %v", err)
+	}
+
+	valid := newTestUserToken(t, repo, user.ID, time.Hour)
+	if _, err := repo.Consume(ctx, "password_reset", valid.TokenHash); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected token of another purpose to be rejected, got %v", err)
+	}
+}
+
+func TestUserTokenRepository_LatestAndInvalidate(t *testing.T) {
+	db := requireDB(t)
+	user := newTestUser(t, NewUserRepository(db))
+	repo := NewUserTokenRepository(db)
+	ctx := context.Background()
+
+	first := newTestUserToken(t, repo, user.ID, time.Hour)
+	second := newTestUserToken(t, repo, user.ID, time.Hour)
+
+	latest, err := repo.GetLatestByUserID(ctx, user.ID, testTokenPurpose)
+	if err != nil {
+		t.Fatalf("GetLatestByUserID returned error: %v", err)
+	}
+	if latest.ID != second.ID {
+		t.Errorf("Expected latest token %s, got %s", second.ID, latest.ID)
+	}
+
+	if err := repo.InvalidateByUserID(ctx, user.ID, testTokenPurpose); err != nil {
+		t.Fatalf("InvalidateByUserID returned error: %v", err)
+	}
+	for _, token := range []*models.UserToken{first, second} {
+		if _, err := repo.Consume(ctx, testTokenPurpose, token.TokenHash); err == nil {
+			t.Errorf("Expected invalidated token %s to be rejected", token.ID)
+		}
+	}
+}
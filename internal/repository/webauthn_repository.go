@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+const userCredentialColumns = `id, user_id, credential_id, public_key, attestation_type, aaguid, transports,
+	sign_count, backup_eligible, backup_state, name, last_used_at, created_at`
+
+// WebAuthnRepository implements IWebAuthnRepository.
+type WebAuthnRepository struct {
+	db *sqlx.DB
+}
+
+// NewWebAuthnRepository creates a new WebAuthn repository.
+func NewWebAuthnRepository(db *sqlx.DB) *WebAuthnRepository {
+	return &WebAuthnRepository{
+		db: db,
+	}
+}
+
+// CreateCredential stores a new credential and enables MFA for its user in one transaction,
+// so password logins ask for the passkey from then on.
+func (r *WebAuthnRepository) CreateCredential(ctx context.Context, credential *models.UserCredential) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		INSERT INTO user_credentials (
+			user_id, credential_id, public_key, attestation_type, aaguid, transports,
+			sign_count, backup_eligible, backup_state, name
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRowxContext(ctx, query,
+		credential.UserID,
+		credential.CredentialID,
+		credential.PublicKey,
+		credential.AttestationType,
+		credential.AAGUID,
+		credential.Transports,
+		credential.SignCount,
+		credential.BackupEligible,
+		credential.BackupState,
+		credential.Name,
+	).Scan(&credential.ID, &credential.CreatedAt)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to create credential for user %s: %v", credential.UserID, err)
+		return fmt.Errorf("failed to create credential: %w",
+			mapUniqueViolation(err, map[string]string{"user_credentials_credential_id_key": "passkey already registered"}))
+	}
+
+	enableMFA := "UPDATE users SET is_mfa_enabled = TRUE, updated_at = NOW() WHERE id = $1"
+	if _, err := tx.ExecContext(ctx, enableMFA, credential.UserID); err != nil {
+		helpers.Logger.Errorf("Failed to enable MFA for user %s: %v", credential.UserID, err)
+		return fmt.Errorf("failed to create credential: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	helpers.Logger.Infof("Credential %s registered for user %s", credential.ID, credential.UserID)
+	return nil
+}
+
+// ListCredentialsByUserID retrieves every credential of a user, oldest first.
+func (r *WebAuthnRepository) ListCredentialsByUserID(ctx context.Context, userID uuid.UUID) ([]models.UserCredential, error) {
+	query := `SELECT ` + userCredentialColumns + ` FROM user_credentials WHERE user_id = $1 ORDER BY created_at`
+
+	credentials := []models.UserCredential{}
+	if err := r.db.SelectContext(ctx, &credentials, query, userID); err != nil {
+		helpers.Logger.Errorf("Failed to list credentials of user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// UpdateCredentialUsage records the signature counter and backup state of a successful
+// assertion and when it happened.
+func (r *WebAuthnRepository) UpdateCredentialUsage(ctx context.Context, credentialID []byte, signCount uint32, backupState bool) error {
+	query := `
+		UPDATE user_credentials
+		SET sign_count = $1, backup_state = $2, last_used_at = NOW()
+		WHERE credential_id = $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, int64(signCount), backupState, credentialID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to update credential usage: %v", err)
+		return fmt.Errorf("failed to update credential usage: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "credential not found")
+	}
+
+	return nil
+}
+
+// DeleteCredential deletes a credential of a user and disables MFA in the same transaction
+// when it was their last second factor.
+func (r *WebAuthnRepository) DeleteCredential(ctx context.Context, userID, id uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM user_credentials WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to delete credential %s of user %s: %v", id, userID, err)
+		return fmt.Errorf("failed to delete credential: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "credential not found")
+	}
+
+	if err := syncMFAEnabled(ctx, tx, userID); err != nil {
+		return fmt.Errorf("failed to delete credential: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	helpers.Logger.Infof("Credential %s of user %s deleted", id, userID)
+	return nil
+}
+
+// DeleteCredentialsByUserID deletes every credential of a user and disables MFA in the same
+// transaction unless they have a confirmed TOTP authenticator. It returns how many were deleted.
+func (r *WebAuthnRepository) DeleteCredentialsByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM user_credentials WHERE user_id = $1", userID)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to delete credentials of user %s: %v", userID, err)
+		return 0, fmt.Errorf("failed to delete credentials: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := syncMFAEnabled(ctx, tx, userID); err != nil {
+		return 0, fmt.Errorf("failed to delete credentials: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// CreateSession stores a pending ceremony.
+func (r *WebAuthnRepository) CreateSession(ctx context.Context, session *models.WebAuthnSession) error {
+	query := `
+		INSERT INTO webauthn_sessions (user_id, purpose, challenge, session_data, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, session.UserID, session.Purpose, session.Challenge, session.SessionData, session.ExpiresAt).
+		Scan(&session.ID, &session.CreatedAt)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to create %s WebAuthn session: %v", session.Purpose, err)
+		return fmt.Errorf("failed to create WebAuthn session: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeSession deletes an unexpired ceremony by its challenge and returns it. Deleting
+// and returning in one statement makes every challenge usable only once.
+func (r *WebAuthnRepository) ConsumeSession(ctx context.Context, purpose, challenge string) (*models.WebAuthnSession, error) {
+	query := `
+		DELETE FROM webauthn_sessions
+		WHERE purpose = $1 AND challenge = $2 AND expires_at > NOW()
+		RETURNING id, user_id, purpose, challenge, session_data, expires_at, created_at
+	`
+
+	var session models.WebAuthnSession
+	err := r.db.GetContext(ctx, &session, query, purpose, challenge)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewError(domain.ErrNotFound, "WebAuthn session not found")
+		}
+		helpers.Logger.Errorf("Failed to consume %s WebAuthn session: %v", purpose, err)
+		return nil, fmt.Errorf("failed to consume WebAuthn session: %w", err)
+	}
+
+	return &session, nil
+}
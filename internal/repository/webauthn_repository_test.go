@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+func TestWebAuthnRepository_CredentialLifecycle(t *testing.T) {
+	db := requireDB(t)
+	userRepo := NewUserRepository(db)
+	user := newTestUser(t, userRepo)
+	repo := NewWebAuthnRepository(db)
+	ctx := context.Background()
+
+	credential := &models.UserCredential{
+		UserID:          user.ID,
+		Name:            "Pixel 9",
+		CredentialID:    []byte(uuid.NewString()),
+		PublicKey:       []byte("public-key"),
+		AttestationType: "none",
+		AAGUID:          make([]byte, 16),
+		Transports:      []string{"internal", "hybrid"},
+		BackupEligible:  true,
+	}
+	if err := repo.CreateCredential(ctx, credential); err != nil {
+		t.Fatalf("CreateCredential returned error: %v", err)
+	}
+	if err := repo.CreateCredential(ctx, credential); err == nil {
+		t.Error("Expected a duplicate credential ID to be rejected")
+	}
+
+	stored, err := userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if !stored.IsMFAEnabled {
+		t.Error("Expected MFA to be enabled for the user")
+	}
+
+	if err := repo.UpdateCredentialUsage(ctx, credential.CredentialID, 7, true); err != nil {
+		t.Fatalf("UpdateCredentialUsage returned error: %v", err)
+	}
+	if err := repo.UpdateCredentialUsage(ctx, []byte("unknown"), 1, false); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected domain.ErrNotFound for an unknown credential, got %v", err)
+	}
+
+	credentials, err := repo.ListCredentialsByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListCredentialsByUserID returned error: %v", err)
+	}
+	if len(credentials) != 1 {
+		t.Fatalf("Expected one credential, got %d", len(credentials))
+	}
+	if got := credentials[0]; got.SignCount != 7 || !got.BackupState || got.LastUsedAt == nil || len(got.Transports) != 2 {
+		t.Errorf("Expected the recorded usage, got %+v", got)
+	}
+
+	if err := repo.DeleteCredential(ctx, uuid.New(), credential.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected domain.ErrNotFound deleting the credential of another user, got %v", err)
+	}
+	if err := repo.DeleteCredential(ctx, user.ID, credential.ID); err != nil {
+		t.Fatalf("DeleteCredential returned error: %v", err)
+	}
+	stored, err = userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if stored.IsMFAEnabled {
+		t.Error("Expected MFA to be disabled with the last passkey deleted")
+	}
+}
+
+func TestWebAuthnRepository_DeleteCredentialsByUserID(t *testing.T) {
+	db := requireDB(t)
+	userRepo := NewUserRepository(db)
+	user := newTestUser(t, userRepo)
+	repo := NewWebAuthnRepository(db)
+	ctx := context.Background()
+
+	for range 2 {
+		credential := &models.UserCredential{
+			UserID:          user.ID,
+			Name:            "Pixel 9",
+			CredentialID:    []byte(uuid.NewString()),
+			PublicKey:       []byte("public-key"),
+			AttestationType: "none",
+			AAGUID:          make([]byte, 16),
+		}
+		if err := repo.CreateCredential(ctx, credential); err != nil {
+			t.Fatalf("CreateCredential returned error: %v", err)
+		}
+	}
+
+	deleted, err := repo.DeleteCredentialsByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("DeleteCredentialsByUserID returned error: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected two deleted credentials, got %d", deleted)
+	}
+
+	stored, err := userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if stored.IsMFAEnabled {
+		t.Error("Expected MFA to be disabled without passkeys")
+	}
+}
+
+func TestWebAuthnRepository_ConsumeSession(t *testing.T) {
+	db := requireDB(t)
+	repo := NewWebAuthnRepository(db)
+	ctx := context.Background()
+
+	session := &models.WebAuthnSession{
+		Purpose:     "login",
+		Challenge:   uuid.NewString(),
+		SessionData: []byte(`{"challenge":"abc"}`),
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+	if err := repo.CreateSession(ctx, session); err != nil {
+		t.Fatalf("CreateSession returned error: %v", err)
+	}
+
+	if _, err := repo.ConsumeSession(ctx, "mfa", session.Challenge); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected a session of another purpose not to match, got %v", err)
+	}
+
+	consumed, err := repo.ConsumeSession(ctx, "login", session.Challenge)
+	if err != nil {
+		t.Fatalf("ConsumeSession returned error: %v", err)
+	}
+	if consumed.ID != session.ID || consumed.UserID.Valid {
+		t.Errorf("Expected the stored session without a user, got %+v", consumed)
+	}
+
+	if _, err := repo.ConsumeSession(ctx, "login", session.Challenge); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected a consumed session to be gone, got %v", err)
+	}
+
+	expired := &models.WebAuthnSession{
+		Purpose:     "login",
+		Challenge:   uuid.NewString(),
+		SessionData: []byte(`{}`),
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	}
+	if err := repo.CreateSession(ctx, expired); err != nil {
+		t.Fatalf("CreateSession returned error: %v", err)
+	}
+	if _, err := repo.ConsumeSession(ctx, "login", expired.Challenge); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected an expired session to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// AdminUser service implementation for support staff.
+type AdminUser struct {
+	UserRepository      interfaces.IUserRepository
+	SessionRepository   interfaces.ISessionRepository
+	UserTokenRepository interfaces.IUserTokenRepository
+}
+
+// ListUsers returns a page of users matching the filter together with the total match count.
+func (s *AdminUser) ListUsers(ctx context.Context, filter models.UserFilter) (*models.UserListResponse, error) {
+	if filter.Limit <= 0 || filter.Limit > constants.MaxPageSize {
+		filter.Limit = constants.DefaultPageSize
+	}
+
+	users, err := s.UserRepository.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []*models.User{}
+	}
+
+	total, err := s.UserRepository.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserListResponse{
+		Users:    users,
+		Total:    total,
+		Page:     filter.Offset/filter.Limit + 1,
+		PageSize: filter.Limit,
+	}, nil
+}
+
+// GetUser returns the user with the given ID.
+func (s *AdminUser) GetUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, err := s.UserRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapUserNotFound(err)
+	}
+	return user, nil
+}
+
+// UpdateUser applies the fields set in the request. A new email address or phone number has to be
+// verified again, and links emailed to the old address stop working. Deactivating a user revokes
+// all of their sessions.
+func (s *AdminUser) UpdateUser(ctx context.Context, id uuid.UUID, req *models.AdminUpdateUserRequest) (*models.User, error) {
+	if req.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*req.Email))
+		req.Email = &email
+	}
+	if req.FullName != nil {
+		fullName := strings.TrimSpace(*req.FullName)
+		req.FullName = &fullName
+	}
+	if req.Phone != nil {
+		phone := strings.TrimSpace(*req.Phone)
+		req.Phone = &phone
+	}
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapUserNotFound(err)
+	}
+
+	var emailChanged bool
+	if req.Email != nil && *req.Email != user.Email {
+		if err := s.ensureAvailable(ctx, models.UserFilter{Email: *req.Email}, ErrEmailAlreadyRegistered); err != nil {
+			return nil, err
+		}
+		user.Email = *req.Email
+		user.IsVerified = false
+		emailChanged = true
+	}
+	if req.Phone != nil && *req.Phone != user.Phone {
+		if err := s.ensureAvailable(ctx, models.UserFilter{Phone: *req.Phone}, ErrPhoneAlreadyRegistered); err != nil {
+			return nil, err
+		}
+		user.Phone = *req.Phone
+		user.IsPhoneVerified = false
+	}
+	if req.FullName != nil {
+		user.FullName = *req.FullName
+	}
+	if req.IsVerified != nil {
+		user.IsVerified = *req.IsVerified
+	}
+
+	deactivated := req.IsActive != nil && !*req.IsActive && user.IsActive
+	if req.IsActive != nil {
+		user.IsActive = *req.IsActive
+		// Keeps a verification from activating the account again
+		if !user.IsActive && user.DeactivatedAt == nil {
+			now := time.Now()
+			user.DeactivatedAt = &now
+		} else if user.IsActive {
+			user.DeactivatedAt = nil
+		}
+	}
+
+	if err := s.UserRepository.Update(ctx, user); err != nil {
+		return nil, mapUserNotFound(err)
+	}
+
+	if deactivated {
+		if err := s.SessionRepository.RevokeAllByUserID(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	// Links emailed to the old address must not verify or reset the account any more
+	if emailChanged {
+		for _, purpose := range []string{constants.TokenPurposeEmailVerification, constants.TokenPurposePasswordReset} {
+			if err := s.UserTokenRepository.InvalidateByUserID(ctx, user.ID, purpose); err != nil {
+				return nil, fmt.Errorf("failed to invalidate tokens: %w", err)
+			}
+		}
+	}
+
+	return user, nil
+}
+
+// DeleteUser soft deletes the user and revokes all of their sessions.
+func (s *AdminUser) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	if err := s.UserRepository.Delete(ctx, id); err != nil {
+		return mapUserNotFound(err)
+	}
+
+	if err := s.SessionRepository.RevokeAllByUserID(ctx, id); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// RevokeSessions revokes every session of the user, signing them out everywhere.
+func (s *AdminUser) RevokeSessions(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.UserRepository.GetByID(ctx, id); err != nil {
+		return mapUserNotFound(err)
+	}
+	return s.SessionRepository.RevokeAllByUserID(ctx, id)
+}
+
+// UnlockUser lifts a login lockout of the user, including a hard lock, and resets the
+// failed login counters.
+func (s *AdminUser) UnlockUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	if err := s.UserRepository.Unlock(ctx, id); err != nil {
+		return nil, mapUserNotFound(err)
+	}
+	helpers.LogSecurityEvent(constants.SecurityEventAccountUnlocked, logrus.Fields{
+		"user_id": id,
+		"reason":  "admin",
+	})
+
+	user, err := s.UserRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapUserNotFound(err)
+	}
+	return user, nil
+}
+
+// ensureAvailable returns conflict when another user matches the filter.
+func (s *AdminUser) ensureAvailable(ctx context.Context, filter models.UserFilter, conflict error) error {
+	count, err := s.UserRepository.Count(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to check uniqueness: %w", err)
+	}
+	if count > 0 {
+		return conflict
+	}
+	return nil
+}
+
+// mapUserNotFound converts a repository not-found error into ErrUserNotFound.
+func mapUserNotFound(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// newActiveSessionRepository returns a session repository holding one active session for userID.
+func newActiveSessionRepository(userID uuid.UUID) *fakeSessionRepository {
+	repo := newFakeSessionRepository()
+	_ = repo.Create(context.Background(), &models.UserSession{
+		ID:                    uuid.New(),
+		UserID:                userID,
+		RefreshTokenExpiresAt: time.Now().Add(time.Hour),
+	})
+	return repo
+}
+
+func TestAdminUser_ListUsers(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	repo := newFakeUserRepository(
+		&models.User{Email: "a@example.com", Phone: "+6281000000001", IsActive: true},
+		&models.User{Email: "b@example.com", Phone: "+6281000000002", IsActive: true},
+		&models.User{Email: "c@example.com", Phone: "+6281000000003"},
+	)
+	svc := &AdminUser{UserRepository: repo, SessionRepository: newFakeSessionRepository()}
+	active := true
+
+	// Act
+	result, err := svc.ListUsers(context.Background(), models.UserFilter{IsActive: &active, Limit: 10, Offset: 10})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Total != 2 || result.Page != 2 || result.PageSize != 10 {
+		t.Errorf("Unexpected pagination: total=%d page=%d page_size=%d", result.Total, result.Page, result.PageSize)
+	}
+}
+
+func TestAdminUser_UpdateUser(t *testing.T) {
+	t.Parallel()
+
+	ptr := func(s string) *string { return &s }
+
+	t.Run("deactivation revokes sessions", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := &models.User{Email: "john@example.com", Phone: "+6281234567890", IsActive: true}
+		svc := &AdminUser{UserRepository: newFakeUserRepository(user), SessionRepository: newActiveSessionRepository(user.ID)}
+		inactive := false
+
+		// Act
+		updated, err := svc.UpdateUser(context.Background(), user.ID, &models.AdminUpdateUserRequest{IsActive: &inactive})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if updated.IsActive || updated.DeactivatedAt == nil {
+			t.Error("Expected user to be deactivated")
+		}
+		active, _ := svc.SessionRepository.ListActiveByUserID(context.Background(), user.ID)
+		if len(active) != 0 {
+			t.Errorf("Expected all sessions revoked, got %d active", len(active))
+		}
+	})
+
+	t.Run("email change needs a new verification", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := &models.User{ID: uuid.New(), Email: "john@example.com", Phone: "+6281234567890", IsActive: true, IsVerified: true}
+		tokens := &fakeUserTokenRepository{}
+		for _, purpose := range []string{constants.TokenPurposeEmailVerification, constants.TokenPurposePasswordReset} {
+			_ = tokens.Create(context.Background(), &models.UserToken{
+				UserID: user.ID, Purpose: purpose, TokenHash: purpose, ExpiresAt: time.Now().Add(time.Hour),
+			})
+		}
+		svc := &AdminUser{
+			UserRepository:      newFakeUserRepository(user),
+			SessionRepository:   newFakeSessionRepository(),
+			UserTokenRepository: tokens,
+		}
+
+		// Act
+		email := "jane@example.com"
+		updated, err := svc.UpdateUser(context.Background(), user.ID, &models.AdminUpdateUserRequest{Email: &email})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if updated.Email != "jane@example.com" || updated.IsVerified {
+			t.Errorf("Expected the new email to be unverified, got %+v", updated)
+		}
+		for _, purpose := range []string{constants.TokenPurposeEmailVerification, constants.TokenPurposePasswordReset} {
+			if _, err := tokens.GetActive(context.Background(), purpose, purpose); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("Expected the %s token to be invalidated, got %v", purpose, err)
+			}
+		}
+	})
+
+	t.Run("email change keeps an explicit verification", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := &models.User{Email: "john@example.com", Phone: "+6281234567890", IsVerified: true}
+		svc := &AdminUser{
+			UserRepository:      newFakeUserRepository(user),
+			SessionRepository:   newFakeSessionRepository(),
+			UserTokenRepository: &fakeUserTokenRepository{},
+		}
+		email := "jane@example.com"
+		verified := true
+
+		// Act
+		updated, err := svc.UpdateUser(context.Background(), user.ID, &models.AdminUpdateUserRequest{
+			Email:      &email,
+			IsVerified: &verified,
+		})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !updated.IsVerified {
+			t.Error("Expected the explicit verification to be kept")
+		}
+	})
+
+	t.Run("activation lifts a deactivation", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		deactivatedAt := time.Now().Add(-time.Hour)
+		user := &models.User{Email: "john@example.com", Phone: "+6281234567890", DeactivatedAt: &deactivatedAt}
+		svc := &AdminUser{UserRepository: newFakeUserRepository(user), SessionRepository: newFakeSessionRepository()}
+		active := true
+
+		// Act
+		updated, err := svc.UpdateUser(context.Background(), user.ID, &models.AdminUpdateUserRequest{IsActive: &active})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !updated.IsActive || updated.DeactivatedAt != nil {
+			t.Errorf("Expected user to be active again, got %+v", updated)
+		}
+	})
+
+	t.Run("duplicate email", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := &models.User{Email: "john@example.com", Phone: "+6281234567890"}
+		other := &models.User{Email: "jane@example.com", Phone: "+6280000000000"}
+		svc := &AdminUser{UserRepository: newFakeUserRepository(user, other), SessionRepository: newFakeSessionRepository()}
+
+		// Act
+		_, err := svc.UpdateUser(context.Background(), user.ID, &models.AdminUpdateUserRequest{Email: ptr(" Jane@Example.com")})
+
+		// Assert
+		if !errors.Is(err, ErrEmailAlreadyRegistered) {
+			t.Errorf("Expected ErrEmailAlreadyRegistered, got %v", err)
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc := &AdminUser{UserRepository: newFakeUserRepository(), SessionRepository: newFakeSessionRepository()}
+
+		// Act
+		_, err := svc.UpdateUser(context.Background(), uuid.New(), &models.AdminUpdateUserRequest{FullName: ptr("Jane")})
+
+		// Assert
+		if !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+}
+
+func TestAdminUser_DeleteUser(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := &models.User{Email: "john@example.com", Phone: "+6281234567890", IsActive: true}
+		repo := newFakeUserRepository(user)
+		sessions := newActiveSessionRepository(user.ID)
+		svc := &AdminUser{UserRepository: repo, SessionRepository: sessions}
+
+		// Act
+		err := svc.DeleteUser(context.Background(), user.ID)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := svc.GetUser(context.Background(), user.ID); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("Expected deleted user to be hidden, got %v", err)
+		}
+		active, _ := sessions.ListActiveByUserID(context.Background(), user.ID)
+		if len(active) != 0 {
+			t.Errorf("Expected all sessions revoked, got %d active", len(active))
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc := &AdminUser{UserRepository: newFakeUserRepository(), SessionRepository: newFakeSessionRepository()}
+
+		// Act
+		err := svc.DeleteUser(context.Background(), uuid.New())
+
+		// Assert
+		if !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+}
+
+func TestAdminUser_RevokeSessions(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	user := &models.User{Email: "john@example.com", Phone: "+6281234567890", IsActive: true}
+	repo := newFakeUserRepository(user)
+	sessions := newActiveSessionRepository(user.ID)
+	svc := &AdminUser{UserRepository: repo, SessionRepository: sessions}
+
+	// Act
+	err := svc.RevokeSessions(context.Background(), user.ID)
+	unknownErr := svc.RevokeSessions(context.Background(), uuid.New())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	active, _ := sessions.ListActiveByUserID(context.Background(), user.ID)
+	if len(active) != 0 {
+		t.Errorf("Expected all sessions revoked, got %d active", len(active))
+	}
+	if !errors.Is(unknownErr, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound for unknown user, got %v", unknownErr)
+	}
+}
+
+func TestAdminUser_UnlockUser(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lockedAt := time.Now()
+	user := &models.User{Email: "john@example.com", HardLockedAt: &lockedAt, LockoutCount: 3, FailedLoginAttempts: 2}
+	svc := &AdminUser{UserRepository: newFakeUserRepository(user), SessionRepository: newFakeSessionRepository()}
+
+	// Act
+	unlocked, err := svc.UnlockUser(context.Background(), user.ID)
+	_, unknownErr := svc.UnlockUser(context.Background(), uuid.New())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if unlocked.HardLockedAt != nil || unlocked.LockoutCount != 0 || unlocked.FailedLoginAttempts != 0 {
+		t.Errorf("Expected the lockout to be cleared, got %+v", unlocked)
+	}
+	if !errors.Is(unknownErr, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound for unknown user, got %v", unknownErr)
+	}
+}
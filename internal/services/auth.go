@@ -0,0 +1,515 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// dummyPasswordHash is compared against when the user does not exist so that
+// unknown and known identifiers take the same time to reject.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := helpers.HashPassword(uuid.NewString())
+	return hash
+})
+
+// Auth service implementation.
+type Auth struct {
+	UserRepository    interfaces.IUserRepository
+	SessionRepository interfaces.ISessionRepository
+	RoleRepository    interfaces.IRoleRepository
+	// MFARepository and UserTokenRepository are only used for users who enabled MFA
+	MFARepository       interfaces.IMFARepository
+	UserTokenRepository interfaces.IUserTokenRepository
+	// WebAuthnRepository and WebAuthn verify passkeys for passwordless login and MFA
+	WebAuthnRepository interfaces.IWebAuthnRepository
+	WebAuthn           *webauthn.WebAuthn
+	// LoginIPLimiter counts failed logins per client IP; nil disables the per-IP limit
+	LoginIPLimiter *helpers.RateLimiter
+	// DeviceRepository remembers the devices of verified logins. Password logins from other
+	// devices are confirmed with a code sent by SMS through PhoneOTPRepository and
+	// SMSProvider, at most PhoneLimiter codes per phone number. nil disables device tracking
+	DeviceRepository   interfaces.IDeviceRepository
+	PhoneOTPRepository interfaces.IPhoneOTPRepository
+	SMSProvider        interfaces.ISMSProvider
+	PhoneLimiter       *helpers.RateLimiter
+	// LoginRiskAssessor checks every new session for signs of account takeover; nil disables it
+	LoginRiskAssessor interfaces.ILoginRiskAssessor
+}
+
+// Login verifies the credentials and issues a new session. Failed logins count against the
+// client IP and the user, whose account is locked out after repeated failures. Users who
+// enabled MFA or log in from a new device get a challenge instead, completed with
+// CompleteMFALogin.
+func (s *Auth) Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.Phone = strings.TrimSpace(req.Phone)
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	if blocked, retryAfter := s.loginIPBlocked(req.IPAddress); blocked {
+		return nil, &domain.RetryAfterError{Err: ErrTooManyLoginAttempts, RetryAfter: retryAfter}
+	}
+
+	user, err := s.findLoginUser(ctx, req)
+	if err != nil {
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		helpers.CheckPassword(dummyPasswordHash(), req.Password)
+		s.recordIPFailure(req.IPAddress)
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := checkLockout(user); err != nil {
+		s.recordIPFailure(req.IPAddress)
+		return nil, err
+	}
+
+	if !helpers.CheckPassword(user.PasswordHash, req.Password) {
+		s.recordIPFailure(req.IPAddress)
+		if err := recordLoginFailure(ctx, s.UserRepository, user, req.IPAddress); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	s.upgradePasswordHash(ctx, user, req.Password)
+
+	// Failures are only cleared once the second factor is verified too, so the lockout
+	// keeps guarding the code
+	if user.IsMFAEnabled {
+		return s.issueMFAChallenge(ctx, user)
+	}
+
+	stepUp, err := s.requiresStepUp(ctx, user, &req.DeviceInfo)
+	if err != nil {
+		return nil, err
+	}
+	if stepUp {
+		return s.issueStepUpChallenge(ctx, user, req.IPAddress)
+	}
+
+	if err := s.clearLoginFailures(ctx, user); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.createSession(ctx, user, req.IPAddress, req.UserAgent, &req.DeviceInfo)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{TokenResponse: tokens}, nil
+}
+
+// issueMFAChallenge asks a user who enabled MFA for one of their second factors.
+func (s *Auth) issueMFAChallenge(ctx context.Context, user *models.User) (*models.LoginResponse, error) {
+	methods, err := s.mfaMethods(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueLoginChallenge(ctx, user, constants.MFAReasonMFAEnabled, methods)
+}
+
+// issueLoginChallenge stores a short-lived MFA token for a user whose password was verified.
+func (s *Auth) issueLoginChallenge(ctx context.Context, user *models.User, reason string, methods []string) (*models.LoginResponse, error) {
+	ttl := helpers.GetEnvDuration("MFA_CHALLENGE_TTL", constants.DefaultMFAChallengeTTL)
+	token, err := issueUserToken(ctx, s.UserTokenRepository, user.ID, constants.TokenPurposeMFALogin, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		MFARequired: true,
+		MFA: &models.MFAChallenge{
+			Token:     token,
+			Reason:    reason,
+			Methods:   methods,
+			ExpiresAt: time.Now().Add(ttl),
+		},
+	}, nil
+}
+
+// mfaMethods lists the second factors the user has set up. Recovery codes come with a
+// confirmed authenticator app.
+func (s *Auth) mfaMethods(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var methods []string
+
+	totp, err := s.MFARepository.GetTOTP(ctx, userID)
+	switch {
+	case err == nil && totp.ConfirmedAt.Valid:
+		methods = append(methods, constants.MFAMethodTOTP, constants.MFAMethodRecoveryCode)
+	case err != nil && !errors.Is(err, domain.ErrNotFound):
+		return nil, err
+	}
+
+	credentials, err := s.WebAuthnRepository.ListCredentialsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(credentials) > 0 {
+		methods = append(methods, constants.MFAMethodPasskey)
+	}
+
+	return methods, nil
+}
+
+// CompleteMFALogin exchanges an MFA challenge token and a TOTP code, recovery code, SMS code
+// or passkey assertion for a new session. Wrong codes and passkeys count towards the login
+// lockout like wrong passwords, and the challenge stays usable until it expires or a factor
+// is accepted.
+func (s *Auth) CompleteMFALogin(ctx context.Context, req *models.LoginMFARequest) (*models.TokenResponse, error) {
+	req.Code = strings.TrimSpace(req.Code)
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	if blocked, retryAfter := s.loginIPBlocked(req.IPAddress); blocked {
+		return nil, &domain.RetryAfterError{Err: ErrTooManyLoginAttempts, RetryAfter: retryAfter}
+	}
+
+	tokenHash := helpers.HashToken(req.MFAToken)
+	challenge, err := s.UserTokenRepository.GetActive(ctx, constants.TokenPurposeMFALogin, tokenHash)
+	if err != nil {
+		return nil, mapMFATokenNotFound(err)
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, mapMFATokenNotFound(err)
+	}
+
+	if err := checkLockout(user); err != nil {
+		s.recordIPFailure(req.IPAddress)
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	method, err := s.verifySecondFactor(ctx, user, req)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidMFACode) && !errors.Is(err, ErrInvalidPasskey) {
+			return nil, err
+		}
+		s.recordIPFailure(req.IPAddress)
+		if err := recordLoginFailure(ctx, s.UserRepository, user, req.IPAddress); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+
+	switch method {
+	case constants.MFAMethodRecoveryCode:
+		helpers.LogSecurityEvent(constants.SecurityEventRecoveryCodeUsed, logrus.Fields{
+			"user_id":    user.ID,
+			"ip_address": req.IPAddress,
+		})
+	case constants.MFAMethodSMS:
+		helpers.LogSecurityEvent(constants.SecurityEventDeviceTrusted, logrus.Fields{
+			"user_id":     user.ID,
+			"ip_address":  req.IPAddress,
+			"device_name": req.DeviceName,
+			"platform":    req.Platform,
+		})
+	}
+
+	if _, err := s.UserTokenRepository.Consume(ctx, constants.TokenPurposeMFALogin, tokenHash); err != nil {
+		return nil, mapMFATokenNotFound(err)
+	}
+
+	if err := s.clearLoginFailures(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return s.createSession(ctx, user, req.IPAddress, req.UserAgent, &req.DeviceInfo)
+}
+
+// verifySecondFactor checks the passkey assertion of the request when there is one and its
+// code otherwise, and returns the method used. Users without MFA only get a challenge for
+// a new device, which they confirm with the code sent by SMS.
+func (s *Auth) verifySecondFactor(ctx context.Context, user *models.User, req *models.LoginMFARequest) (string, error) {
+	if len(req.Passkey) > 0 {
+		return constants.MFAMethodPasskey, s.verifyMFAPasskey(ctx, user, req.Passkey)
+	}
+	if !user.IsMFAEnabled {
+		return constants.MFAMethodSMS, s.verifyStepUpCode(ctx, user, req.Code)
+	}
+	return verifyMFACode(ctx, s.MFARepository, user.ID, req.Code)
+}
+
+func mapMFATokenNotFound(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrInvalidMFAToken
+	}
+	return err
+}
+
+// upgradePasswordHash rehashes the password with the current argon2id parameters when the
+// stored hash is bcrypt or uses older parameters. Failures only delay the upgrade, so they
+// are logged and the login goes ahead.
+func (s *Auth) upgradePasswordHash(ctx context.Context, user *models.User, password string) {
+	if !helpers.NeedsRehash(user.PasswordHash) {
+		return
+	}
+
+	passwordHash, err := helpers.HashPassword(password)
+	if err != nil {
+		helpers.Logger.Errorf("Failed to rehash password of user %s: %v", user.ID, err)
+		return
+	}
+	if err := s.UserRepository.UpdatePassword(ctx, user.ID, passwordHash); err != nil {
+		helpers.Logger.Errorf("Failed to store rehashed password of user %s: %v", user.ID, err)
+		return
+	}
+	user.PasswordHash = passwordHash
+}
+
+func (s *Auth) findLoginUser(ctx context.Context, req *models.LoginRequest) (*models.User, error) {
+	if req.Email != "" {
+		return s.UserRepository.GetByEmail(ctx, req.Email)
+	}
+	return s.UserRepository.GetByPhone(ctx, req.Phone)
+}
+
+// createSession stores a new session holding only token hashes and returns the raw tokens.
+// The login was fully verified, so the device it came from becomes a known device.
+func (s *Auth) createSession(
+	ctx context.Context, user *models.User, ipAddress, userAgent string, device *models.DeviceInfo,
+) (*models.TokenResponse, error) {
+	deviceID, err := s.trustDevice(ctx, user, device, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.UserSession{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		IPAddress:  sql.NullString{String: ipAddress, Valid: ipAddress != ""},
+		UserAgent:  sql.NullString{String: userAgent, Valid: userAgent != ""},
+		DeviceID:   deviceID,
+		DeviceName: sql.NullString{String: device.DeviceName, Valid: device.DeviceName != ""},
+		Platform:   sql.NullString{String: device.Platform, Valid: device.Platform != ""},
+	}
+
+	tokens, err := s.issueTokens(ctx, user, session)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.SessionRepository.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	// The login already succeeded, so a failed risk check must not keep the user out
+	if s.LoginRiskAssessor != nil {
+		if _, err := s.LoginRiskAssessor.AssessLogin(ctx, user, session); err != nil {
+			helpers.Logger.Errorf("Failed to assess login risk of session %s: %v", session.ID, err)
+		}
+	}
+
+	return tokens, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair and invalidates the old refresh token.
+// Presenting an already rotated refresh token revokes the whole session.
+func (s *Auth) Refresh(ctx context.Context, req *models.RefreshTokenRequest) (*models.TokenResponse, error) {
+	if err := helpers.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	refreshTokenHash := helpers.HashToken(req.RefreshToken)
+
+	session, err := s.SessionRepository.GetByRefreshToken(ctx, refreshTokenHash)
+	if err != nil {
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		return nil, s.detectRefreshTokenReuse(ctx, refreshTokenHash, req.IPAddress)
+	}
+
+	if session.IsRevoked || time.Now().After(session.RefreshTokenExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	session.IPAddress = sql.NullString{String: req.IPAddress, Valid: req.IPAddress != ""}
+	session.UserAgent = sql.NullString{String: req.UserAgent, Valid: req.UserAgent != ""}
+
+	tokens, err := s.issueTokens(ctx, user, session)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.SessionRepository.Rotate(ctx, session, refreshTokenHash); err != nil {
+		// Another request rotated or revoked the session first
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// detectRefreshTokenReuse revokes the owning session when an unknown refresh token
+// turns out to be one that was already rotated.
+func (s *Auth) detectRefreshTokenReuse(ctx context.Context, refreshTokenHash, ipAddress string) error {
+	session, err := s.SessionRepository.GetByRotatedRefreshToken(ctx, refreshTokenHash)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+
+	helpers.LogSecurityEvent(constants.SecurityEventRefreshTokenReuse, logrus.Fields{
+		"user_id":    session.UserID,
+		"session_id": session.ID,
+		"ip_address": ipAddress,
+	})
+
+	if !session.IsRevoked {
+		if err := s.SessionRepository.Revoke(ctx, session.ID); err != nil {
+			return fmt.Errorf("failed to revoke reused session: %w", err)
+		}
+	}
+
+	return ErrRefreshTokenReused
+}
+
+// Authenticate verifies an access token and returns its session. The session
+// must still exist and be unrevoked, so revocation takes effect immediately.
+func (s *Auth) Authenticate(ctx context.Context, accessToken string) (*models.UserSession, error) {
+	claims, err := helpers.ParseAccessToken(accessToken)
+	if err != nil {
+		return nil, ErrInvalidAccessToken
+	}
+
+	session, err := s.SessionRepository.GetByAccessToken(ctx, helpers.HashToken(accessToken))
+	if err != nil {
+		return nil, ErrInvalidAccessToken
+	}
+
+	if session.IsRevoked || session.ID.String() != claims.SessionID || time.Now().After(session.AccessTokenExpiresAt) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	return session, nil
+}
+
+// Logout revokes a single session.
+func (s *Auth) Logout(ctx context.Context, sessionID uuid.UUID) error {
+	return s.SessionRepository.Revoke(ctx, sessionID)
+}
+
+// LogoutAll revokes every session of a user.
+func (s *Auth) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	return s.SessionRepository.RevokeAllByUserID(ctx, userID)
+}
+
+// ValidateToken reports whether an access token is active for internal services.
+// Invalid tokens are not an error: they are reported with Active set to false.
+func (s *Auth) ValidateToken(ctx context.Context, accessToken string) (*models.TokenValidationResponse, error) {
+	session, err := s.Authenticate(ctx, accessToken)
+	if err != nil {
+		return &models.TokenValidationResponse{Active: false}, nil
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, session.UserID)
+	if err != nil || !user.IsActive {
+		return &models.TokenValidationResponse{Active: false}, nil
+	}
+
+	// Report current permissions rather than the ones frozen into the token
+	permissions, err := s.RoleRepository.GetPermissionsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenValidationResponse{
+		Active:      true,
+		UserID:      &user.ID,
+		SessionID:   &session.ID,
+		ExpiresAt:   &session.AccessTokenExpiresAt,
+		Permissions: permissions,
+		IsVerified:  user.AccountVerified(),
+	}, nil
+}
+
+// issueTokens generates a new token pair for the user's session, sets the token hashes
+// and expiries on it and returns the raw tokens. The access token carries the
+// permissions currently granted to the user and whether the user verified their email
+// address or phone number.
+func (s *Auth) issueTokens(ctx context.Context, user *models.User, session *models.UserSession) (*models.TokenResponse, error) {
+	permissions, err := s.RoleRepository.GetPermissionsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	accessTTL := helpers.GetEnvDuration("JWT_ACCESS_TOKEN_TTL", constants.DefaultAccessTokenTTL)
+	refreshTTL := helpers.GetEnvDuration("JWT_REFRESH_TOKEN_TTL", constants.DefaultRefreshTokenTTL)
+
+	session.AccessTokenExpiresAt = now.Add(accessTTL)
+	session.RefreshTokenExpiresAt = now.Add(refreshTTL)
+
+	accessToken, err := helpers.GenerateAccessToken(helpers.AccessTokenParams{
+		UserID:      user.ID,
+		SessionID:   session.ID,
+		Permissions: permissions,
+		Verified:    user.AccountVerified(),
+		ExpiresAt:   session.AccessTokenExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := helpers.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session.AccessToken = helpers.HashToken(accessToken)
+	session.RefreshToken = helpers.HashToken(refreshToken)
+
+	return &models.TokenResponse{
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		TokenType:             constants.TokenTypeBearer,
+		ExpiresIn:             int64(accessTTL.Seconds()),
+		AccessTokenExpiresAt:  session.AccessTokenExpiresAt,
+		RefreshTokenExpiresAt: session.RefreshTokenExpiresAt,
+	}, nil
+}
@@ -0,0 +1,817 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// fakeSessionRepository is an in-memory ISessionRepository for service tests.
+type fakeSessionRepository struct {
+	err      error
+	sessions map[uuid.UUID]*models.UserSession
+	rotated  map[string]uuid.UUID
+	mu       sync.Mutex
+}
+
+func newFakeSessionRepository() *fakeSessionRepository {
+	return &fakeSessionRepository{
+		sessions: make(map[uuid.UUID]*models.UserSession),
+		rotated:  make(map[string]uuid.UUID),
+	}
+}
+
+func (f *fakeSessionRepository) Create(_ context.Context, session *models.UserSession) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = session.CreatedAt
+	stored := *session
+	f.sessions[session.ID] = &stored
+	return nil
+}
+
+func (f *fakeSessionRepository) find(match func(*models.UserSession) bool) (*models.UserSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, s := range f.sessions {
+		if match(s) {
+			found := *s
+			return &found, nil
+		}
+	}
+	return nil, domain.NewError(domain.ErrNotFound, "session not found")
+}
+
+func (f *fakeSessionRepository) GetByAccessToken(_ context.Context, hash string) (*models.UserSession, error) {
+	return f.find(func(s *models.UserSession) bool { return s.AccessToken == hash })
+}
+
+func (f *fakeSessionRepository) GetByRefreshToken(_ context.Context, hash string) (*models.UserSession, error) {
+	return f.find(func(s *models.UserSession) bool { return s.RefreshToken == hash })
+}
+
+func (f *fakeSessionRepository) GetByRotatedRefreshToken(_ context.Context, hash string) (*models.UserSession, error) {
+	f.mu.Lock()
+	id, ok := f.rotated[hash]
+	err := f.err
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.NewError(domain.ErrNotFound, "session not found")
+	}
+	return f.find(func(s *models.UserSession) bool { return s.ID == id })
+}
+
+func (f *fakeSessionRepository) Rotate(_ context.Context, session *models.UserSession, previousHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	existing, ok := f.sessions[session.ID]
+	if !ok || existing.IsRevoked || existing.RefreshToken != previousHash {
+		return domain.NewError(domain.ErrNotFound, "session not found")
+	}
+	stored := *session
+	f.sessions[session.ID] = &stored
+	f.rotated[previousHash] = session.ID
+	return nil
+}
+
+func (f *fakeSessionRepository) Revoke(_ context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	s, ok := f.sessions[id]
+	if !ok || s.IsRevoked {
+		return domain.NewError(domain.ErrNotFound, "session not found")
+	}
+	s.IsRevoked = true
+	return nil
+}
+
+func (f *fakeSessionRepository) RevokeAllByUserID(_ context.Context, userID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	for _, s := range f.sessions {
+		if s.UserID == userID {
+			s.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+func (f *fakeSessionRepository) RevokeOthersByUserID(_ context.Context, userID, keepSessionID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	for _, s := range f.sessions {
+		if s.UserID == userID && s.ID != keepSessionID {
+			s.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+func (f *fakeSessionRepository) RevokeByDeviceID(_ context.Context, userID, deviceID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	for _, s := range f.sessions {
+		if s.UserID == userID && s.DeviceID.Valid && s.DeviceID.UUID == deviceID {
+			s.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+func (f *fakeSessionRepository) ListActiveByUserID(_ context.Context, userID uuid.UUID) ([]*models.UserSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	var sessions []*models.UserSession
+	for _, s := range f.sessions {
+		if s.UserID == userID && !s.IsRevoked && s.RefreshTokenExpiresAt.After(time.Now()) {
+			found := *s
+			sessions = append(sessions, &found)
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeSessionRepository) ListRecentByUserID(_ context.Context, userID uuid.UUID, limit int) ([]*models.UserSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	var sessions []*models.UserSession
+	for _, s := range f.sessions {
+		if s.UserID == userID {
+			found := *s
+			sessions = append(sessions, &found)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b *models.UserSession) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions, nil
+}
+
+func (f *fakeSessionRepository) DeleteExpired(_ context.Context, before time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var deleted int64
+	for id, s := range f.sessions {
+		if s.RefreshTokenExpiresAt.Before(before) {
+			delete(f.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func newLoginUser(t *testing.T, active bool) *models.User {
+	t.Helper()
+
+	hash, err := helpers.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	return &models.User{
+		Email:        "john@example.com",
+		Phone:        "+6281234567890",
+		PasswordHash: hash,
+		IsActive:     active,
+	}
+}
+
+func TestAuth_Login(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success with email", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		sessions := newFakeSessionRepository()
+		svc := &Auth{UserRepository: newFakeUserRepository(user), SessionRepository: sessions, RoleRepository: newFakeRoleRepository()}
+		req := &models.LoginRequest{
+			Email:     "John@Example.com",
+			Password:  "password123",
+			IPAddress: "10.0.0.1",
+			UserAgent: "test-agent",
+		}
+
+		// Act
+		tokens, err := svc.Login(context.Background(), req)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		claims, err := helpers.ParseAccessToken(tokens.AccessToken)
+		if err != nil {
+			t.Fatalf("Expected valid access token, got %v", err)
+		}
+		if claims.Subject != user.ID.String() {
+			t.Errorf("Expected subject %s, got %s", user.ID, claims.Subject)
+		}
+
+		session, ok := sessions.sessions[uuid.MustParse(claims.SessionID)]
+		if !ok {
+			t.Fatal("Expected session to be persisted")
+		}
+		if session.AccessToken != helpers.HashToken(tokens.AccessToken) ||
+			session.RefreshToken != helpers.HashToken(tokens.RefreshToken) {
+			t.Error("Expected only token hashes to be persisted")
+		}
+		if session.IPAddress.String != "10.0.0.1" || session.UserAgent.String != "test-agent" {
+			t.Errorf("Expected session metadata to be stored, got %+v", session)
+		}
+	})
+
+	t.Run("access token carries permissions", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		users := newFakeUserRepository(user)
+		roles := newFakeRoleRepository()
+		_ = roles.AssignRole(context.Background(), user.ID, "support")
+		svc := &Auth{UserRepository: users, SessionRepository: newFakeSessionRepository(), RoleRepository: roles}
+
+		// Act
+		tokens, err := svc.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "password123"})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		claims, err := helpers.ParseAccessToken(tokens.AccessToken)
+		if err != nil {
+			t.Fatalf("Expected valid access token, got %v", err)
+		}
+		if !slices.Equal(claims.Permissions, []string{"sessions:revoke", "users:deactivate", "users:read"}) {
+			t.Errorf("Expected support permissions in token, got %v", claims.Permissions)
+		}
+	})
+
+	t.Run("verified claim", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name          string
+			emailVerified bool
+			phoneVerified bool
+			want          bool
+		}{
+			{name: "unverified", want: false},
+			{name: "email verified", emailVerified: true, want: true},
+			{name: "phone verified", phoneVerified: true, want: true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				user := newLoginUser(t, true)
+				user.IsVerified = tt.emailVerified
+				user.IsPhoneVerified = tt.phoneVerified
+				svc := &Auth{
+					UserRepository:    newFakeUserRepository(user),
+					SessionRepository: newFakeSessionRepository(),
+					RoleRepository:    newFakeRoleRepository(),
+				}
+
+				// Act
+				tokens := loginForTest(t, svc)
+
+				// Assert
+				claims, err := helpers.ParseAccessToken(tokens.AccessToken)
+				if err != nil {
+					t.Fatalf("Expected valid access token, got %v", err)
+				}
+				if claims.Verified != tt.want {
+					t.Errorf("Expected verified %v, got %v", tt.want, claims.Verified)
+				}
+			})
+		}
+	})
+
+	t.Run("success with phone", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(newLoginUser(t, true)),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+
+		// Act
+		_, err := svc.Login(context.Background(), &models.LoginRequest{Phone: "+6281234567890", Password: "password123"})
+
+		// Assert
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("upgrades a bcrypt hash", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		legacyHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		if err != nil {
+			t.Fatalf("Failed to hash password: %v", err)
+		}
+		user := newLoginUser(t, true)
+		user.PasswordHash = string(legacyHash)
+		users := newFakeUserRepository(user)
+		svc := &Auth{UserRepository: users, SessionRepository: newFakeSessionRepository(), RoleRepository: newFakeRoleRepository()}
+
+		// Act
+		_, err = svc.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "password123"})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		stored, _ := users.GetByID(context.Background(), user.ID)
+		if !strings.HasPrefix(stored.PasswordHash, "$argon2id$") || !helpers.CheckPassword(stored.PasswordHash, "password123") {
+			t.Errorf("Expected the hash to be upgraded to argon2id, got %q", stored.PasswordHash)
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(newLoginUser(t, true)),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+
+		// Act
+		_, err := svc.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "wrong-password"})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+
+		// Act
+		_, err := svc.Login(context.Background(), &models.LoginRequest{Email: "nobody@example.com", Password: "password123"})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("repository failure is not a failed login", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		users := newFakeUserRepository(newLoginUser(t, true))
+		users.err = errors.New("connection refused")
+		limiter := helpers.NewRateLimiter(1, time.Hour)
+		svc := &Auth{
+			UserRepository:    users,
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+			LoginIPLimiter:    limiter,
+		}
+		req := &models.LoginRequest{Email: "john@example.com", Password: "password123", IPAddress: "10.0.0.1"}
+
+		// Act
+		_, err := svc.Login(context.Background(), req)
+
+		// Assert
+		if err == nil || errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Expected the repository error, got %v", err)
+		}
+		if blocked, _ := limiter.Exceeded("10.0.0.1"); blocked {
+			t.Error("Expected the IP not to be charged for a repository failure")
+		}
+	})
+
+	t.Run("inactive user", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(newLoginUser(t, false)),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+
+		// Act
+		_, err := svc.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "password123"})
+
+		// Assert
+		if !errors.Is(err, ErrUserInactive) {
+			t.Errorf("Expected ErrUserInactive, got %v", err)
+		}
+	})
+
+	t.Run("missing identifier", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+
+		// Act
+		_, err := svc.Login(context.Background(), &models.LoginRequest{Password: "password123"})
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("Expected ValidationError, got %v", err)
+		}
+	})
+}
+
+// loginForTest logs the user in and returns the issued tokens.
+func loginForTest(t *testing.T, svc *Auth) *models.TokenResponse {
+	t.Helper()
+
+	resp, err := svc.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to log in: %v", err)
+	}
+	return resp.TokenResponse
+}
+
+func TestAuth_Refresh(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rotates tokens", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		sessions := newFakeSessionRepository()
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(newLoginUser(t, true)),
+			SessionRepository: sessions,
+			RoleRepository:    newFakeRoleRepository(),
+		}
+		tokens := loginForTest(t, svc)
+
+		// Act
+		refreshed, err := svc.Refresh(context.Background(), &models.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if refreshed.RefreshToken == tokens.RefreshToken || refreshed.AccessToken == tokens.AccessToken {
+			t.Error("Expected a new token pair")
+		}
+		session, err := sessions.GetByRefreshToken(context.Background(), helpers.HashToken(refreshed.RefreshToken))
+		if err != nil {
+			t.Fatalf("Expected session to hold the new refresh token hash: %v", err)
+		}
+		if session.AccessToken != helpers.HashToken(refreshed.AccessToken) {
+			t.Error("Expected session to hold the new access token hash")
+		}
+	})
+
+	t.Run("reuse of rotated token revokes session", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		sessions := newFakeSessionRepository()
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(newLoginUser(t, true)),
+			SessionRepository: sessions,
+			RoleRepository:    newFakeRoleRepository(),
+		}
+		tokens := loginForTest(t, svc)
+		refreshed, err := svc.Refresh(context.Background(), &models.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+		if err != nil {
+			t.Fatalf("Failed to refresh: %v", err)
+		}
+
+		// Act
+		_, err = svc.Refresh(context.Background(), &models.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+
+		// Assert
+		if !errors.Is(err, ErrRefreshTokenReused) {
+			t.Fatalf("Expected ErrRefreshTokenReused, got %v", err)
+		}
+		_, err = svc.Refresh(context.Background(), &models.RefreshTokenRequest{RefreshToken: refreshed.RefreshToken})
+		if !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("Expected latest refresh token to be revoked, got %v", err)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+
+		// Act
+		_, err := svc.Refresh(context.Background(), &models.RefreshTokenRequest{RefreshToken: "unknown"})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("Expected ErrInvalidRefreshToken, got %v", err)
+		}
+	})
+
+	t.Run("repository failures are not invalid tokens", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			fail func(*fakeUserRepository, *fakeSessionRepository)
+			name string
+		}{
+			{name: "session lookup", fail: func(_ *fakeUserRepository, s *fakeSessionRepository) { s.err = errors.New("connection refused") }},
+			{name: "user lookup", fail: func(u *fakeUserRepository, _ *fakeSessionRepository) { u.err = errors.New("connection refused") }},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				users := newFakeUserRepository(newLoginUser(t, true))
+				sessions := newFakeSessionRepository()
+				svc := &Auth{UserRepository: users, SessionRepository: sessions, RoleRepository: newFakeRoleRepository()}
+				tokens := loginForTest(t, svc)
+				tt.fail(users, sessions)
+
+				// Act
+				_, err := svc.Refresh(context.Background(), &models.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+
+				// Assert
+				if err == nil || errors.Is(err, ErrInvalidRefreshToken) || errors.Is(err, ErrRefreshTokenReused) {
+					t.Errorf("Expected the repository error, got %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		sessions := newFakeSessionRepository()
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(newLoginUser(t, true)),
+			SessionRepository: sessions,
+			RoleRepository:    newFakeRoleRepository(),
+		}
+		tokens := loginForTest(t, svc)
+		for _, session := range sessions.sessions {
+			session.RefreshTokenExpiresAt = time.Now().Add(-time.Minute)
+		}
+
+		// Act
+		_, err := svc.Refresh(context.Background(), &models.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("Expected ErrInvalidRefreshToken, got %v", err)
+		}
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+
+		// Act
+		_, err := svc.Refresh(context.Background(), &models.RefreshTokenRequest{})
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("Expected ValidationError, got %v", err)
+		}
+	})
+}
+
+func TestAuth_AuthenticateAndLogout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("authenticate valid token", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(user),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+		tokens := loginForTest(t, svc)
+
+		// Act
+		session, err := svc.Authenticate(context.Background(), tokens.AccessToken)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if session.UserID != user.ID {
+			t.Errorf("Expected session of user %s, got %s", user.ID, session.UserID)
+		}
+	})
+
+	t.Run("malformed token", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+
+		// Act
+		_, err := svc.Authenticate(context.Background(), "not-a-jwt")
+
+		// Assert
+		if !errors.Is(err, ErrInvalidAccessToken) {
+			t.Errorf("Expected ErrInvalidAccessToken, got %v", err)
+		}
+	})
+
+	t.Run("logout revokes access token immediately", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(newLoginUser(t, true)),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+		tokens := loginForTest(t, svc)
+		session, err := svc.Authenticate(context.Background(), tokens.AccessToken)
+		if err != nil {
+			t.Fatalf("Failed to authenticate: %v", err)
+		}
+
+		// Act
+		err = svc.Logout(context.Background(), session.ID)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := svc.Authenticate(context.Background(), tokens.AccessToken); !errors.Is(err, ErrInvalidAccessToken) {
+			t.Errorf("Expected ErrInvalidAccessToken after logout, got %v", err)
+		}
+		if _, err := svc.Refresh(context.Background(), &models.RefreshTokenRequest{RefreshToken: tokens.RefreshToken}); err == nil {
+			t.Error("Expected refresh to fail after logout")
+		}
+	})
+
+	t.Run("logout all revokes every session", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(user),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+		first := loginForTest(t, svc)
+		second := loginForTest(t, svc)
+
+		// Act
+		err := svc.LogoutAll(context.Background(), user.ID)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, tokens := range []*models.TokenResponse{first, second} {
+			if _, err := svc.Authenticate(context.Background(), tokens.AccessToken); !errors.Is(err, ErrInvalidAccessToken) {
+				t.Errorf("Expected ErrInvalidAccessToken after logout-all, got %v", err)
+			}
+		}
+	})
+}
+
+func TestAuth_ValidateToken(t *testing.T) {
+	t.Parallel()
+
+	t.Run("active token", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		user.IsVerified = true
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(user),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+		tokens := loginForTest(t, svc)
+
+		// Act
+		result, err := svc.ValidateToken(context.Background(), tokens.AccessToken)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !result.Active || !result.IsVerified || result.UserID == nil || *result.UserID != user.ID {
+			t.Errorf("Unexpected validation result %+v", result)
+		}
+		if result.SessionID == nil || result.ExpiresAt == nil {
+			t.Error("Expected session ID and expiry to be set")
+		}
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(user),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+		}
+		tokens := loginForTest(t, svc)
+		if err := svc.LogoutAll(context.Background(), user.ID); err != nil {
+			t.Fatalf("Failed to revoke sessions: %v", err)
+		}
+
+		// Act
+		result, err := svc.ValidateToken(context.Background(), tokens.AccessToken)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Active || result.UserID != nil {
+			t.Errorf("Expected inactive result without details, got %+v", result)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// Device service implementation. It lets users review where they are logged in and sign
+// out sessions and devices they do not recognise.
+type Device struct {
+	SessionRepository interfaces.ISessionRepository
+	DeviceRepository  interfaces.IDeviceRepository
+}
+
+// ListSessions returns the active sessions of a user, marking the one making the request,
+// together with the devices the user logged in from.
+func (s *Device) ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) (*models.SessionListResponse, error) {
+	sessions, err := s.SessionRepository.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := s.DeviceRepository.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if devices == nil {
+		devices = []*models.UserDevice{}
+	}
+
+	resp := &models.SessionListResponse{
+		Sessions: make([]models.SessionResponse, 0, len(sessions)),
+		Devices:  devices,
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, toSessionResponse(session, currentSessionID))
+	}
+	return resp, nil
+}
+
+// RevokeSession revokes one active session of a user. Revoking the current session logs
+// the caller out.
+func (s *Device) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	sessions, err := s.SessionRepository.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			return s.SessionRepository.Revoke(ctx, sessionID)
+		}
+	}
+	return ErrSessionNotFound
+}
+
+// RevokeDevice revokes the sessions created on a device of a user and forgets the device,
+// so the next password login from it needs the step-up verification again.
+func (s *Device) RevokeDevice(ctx context.Context, userID, deviceID uuid.UUID) error {
+	if err := s.SessionRepository.RevokeByDeviceID(ctx, userID, deviceID); err != nil {
+		return err
+	}
+
+	if err := s.DeviceRepository.Delete(ctx, userID, deviceID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrDeviceNotFound
+		}
+		return err
+	}
+
+	helpers.LogSecurityEvent(constants.SecurityEventDeviceRevoked, logrus.Fields{
+		"user_id":   userID,
+		"device_id": deviceID,
+	})
+	return nil
+}
+
+func toSessionResponse(session *models.UserSession, currentSessionID uuid.UUID) models.SessionResponse {
+	resp := models.SessionResponse{
+		ID:         session.ID,
+		CreatedAt:  session.CreatedAt,
+		LastUsedAt: session.UpdatedAt,
+		ExpiresAt:  session.RefreshTokenExpiresAt,
+		IPAddress:  session.IPAddress.String,
+		UserAgent:  session.UserAgent.String,
+		DeviceName: session.DeviceName.String,
+		Platform:   session.Platform.String,
+		Current:    session.ID == currentSessionID,
+	}
+	if session.DeviceID.Valid {
+		resp.DeviceID = &session.DeviceID.UUID
+	}
+	return resp
+}
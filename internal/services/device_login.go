@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// requiresStepUp reports whether a password login comes from a device the user never
+// completed a verified login from. The first device of a user is trusted without step-up,
+// and so is every device of a user without a verified phone number to send the code to.
+func (s *Auth) requiresStepUp(ctx context.Context, user *models.User, device *models.DeviceInfo) (bool, error) {
+	if s.DeviceRepository == nil || !user.IsPhoneVerified {
+		return false, nil
+	}
+
+	if device.DeviceID != "" {
+		_, err := s.DeviceRepository.GetByDeviceIDHash(ctx, user.ID, helpers.HashToken(device.DeviceID))
+		if err == nil {
+			return false, nil
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return false, err
+		}
+	}
+
+	count, err := s.DeviceRepository.CountByUserID(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// issueStepUpChallenge sends a login code by SMS to the verified phone number of a user
+// logging in from a new device and returns the challenge to complete with that code.
+func (s *Auth) issueStepUpChallenge(ctx context.Context, user *models.User, ipAddress string) (*models.LoginResponse, error) {
+	if ok, retryAfter := s.PhoneLimiter.Allow(user.Phone); !ok {
+		return nil, &domain.RetryAfterError{Err: ErrTooManyOTPRequests, RetryAfter: retryAfter}
+	}
+
+	message := "%s is your e-wallet code to log in on a new device. It expires in %d minutes. Never share this code."
+	err := sendPhoneOTP(ctx, s.PhoneOTPRepository, s.SMSProvider, user, constants.OTPPurposeLoginVerification, message)
+	if err != nil {
+		return nil, err
+	}
+
+	helpers.LogSecurityEvent(constants.SecurityEventNewDeviceLogin, logrus.Fields{
+		"user_id":    user.ID,
+		"ip_address": ipAddress,
+	})
+
+	return s.issueLoginChallenge(ctx, user, constants.MFAReasonNewDevice, []string{constants.MFAMethodSMS})
+}
+
+// verifyStepUpCode checks the SMS code sent by issueStepUpChallenge.
+func (s *Auth) verifyStepUpCode(ctx context.Context, user *models.User, code string) error {
+	otp, err := checkPhoneOTP(ctx, s.PhoneOTPRepository, constants.OTPPurposeLoginVerification, user.Phone, code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidOTP) {
+			return ErrInvalidMFACode
+		}
+		return err
+	}
+	// The number may have changed hands since the code was sent
+	if otp.UserID != user.ID {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// trustDevice remembers the device of a verified login, so later logins from it skip the
+// step-up verification, and returns its ID. Logins without a device ID are not remembered.
+func (s *Auth) trustDevice(ctx context.Context, user *models.User, device *models.DeviceInfo, ipAddress string) (uuid.NullUUID, error) {
+	if s.DeviceRepository == nil || device.DeviceID == "" {
+		return uuid.NullUUID{}, nil
+	}
+
+	stored := &models.UserDevice{
+		UserID:        user.ID,
+		DeviceIDHash:  helpers.HashToken(device.DeviceID),
+		Name:          optionalString(device.DeviceName),
+		Platform:      optionalString(device.Platform),
+		LastIPAddress: optionalString(ipAddress),
+	}
+	if err := s.DeviceRepository.Upsert(ctx, stored); err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: stored.ID, Valid: true}, nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+const (
+	testDeviceID      = "8c1f0a52-4d7e-4b61-9a3e-2f6d1c9b7e05"
+	testOtherDeviceID = "3e9b7d14-a6c2-4f08-8d5b-71e0c4a92f36"
+)
+
+// fakeDeviceRepository is an in-memory IDeviceRepository for service tests.
+type fakeDeviceRepository struct {
+	devices []*models.UserDevice
+	mu      sync.Mutex
+}
+
+func (f *fakeDeviceRepository) Upsert(_ context.Context, device *models.UserDevice) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range f.devices {
+		if d.UserID == device.UserID && d.DeviceIDHash == device.DeviceIDHash {
+			d.LastIPAddress = device.LastIPAddress
+			d.LastSeenAt = time.Now()
+			*device = *d
+			return nil
+		}
+	}
+	device.ID = uuid.New()
+	device.CreatedAt = time.Now()
+	device.LastSeenAt = device.CreatedAt
+	stored := *device
+	f.devices = append(f.devices, &stored)
+	return nil
+}
+
+func (f *fakeDeviceRepository) GetByDeviceIDHash(_ context.Context, userID uuid.UUID, deviceIDHash string) (*models.UserDevice, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range f.devices {
+		if d.UserID == userID && d.DeviceIDHash == deviceIDHash {
+			found := *d
+			return &found, nil
+		}
+	}
+	return nil, domain.NewError(domain.ErrNotFound, "device not found")
+}
+
+func (f *fakeDeviceRepository) CountByUserID(_ context.Context, userID uuid.UUID) (int, error) {
+	devices, _ := f.ListByUserID(context.Background(), userID)
+	return len(devices), nil
+}
+
+func (f *fakeDeviceRepository) ListByUserID(_ context.Context, userID uuid.UUID) ([]*models.UserDevice, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var devices []*models.UserDevice
+	for _, d := range f.devices {
+		if d.UserID == userID {
+			found := *d
+			devices = append(devices, &found)
+		}
+	}
+	return devices, nil
+}
+
+func (f *fakeDeviceRepository) Delete(_ context.Context, userID, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, d := range f.devices {
+		if d.UserID == userID && d.ID == id {
+			f.devices = append(f.devices[:i], f.devices[i+1:]...)
+			return nil
+		}
+	}
+	return domain.NewError(domain.ErrNotFound, "device not found")
+}
+
+// newDeviceAuth returns an Auth service tracking the devices of a user with a verified
+// phone number and the password "password123".
+func newDeviceAuth(t *testing.T) (*Auth, *models.User, *fakeDeviceRepository, *fakeSMSProvider) {
+	t.Helper()
+
+	user := newPINUser(t, "")
+	devices := &fakeDeviceRepository{}
+	sms := &fakeSMSProvider{codes: make(map[string][]string)}
+	svc := &Auth{
+		UserRepository:      newFakeUserRepository(user),
+		SessionRepository:   newFakeSessionRepository(),
+		RoleRepository:      newFakeRoleRepository(),
+		UserTokenRepository: &fakeUserTokenRepository{},
+		DeviceRepository:    devices,
+		PhoneOTPRepository:  &fakePhoneOTPRepository{},
+		SMSProvider:         sms,
+		PhoneLimiter:        helpers.NewRateLimiter(3, time.Hour),
+	}
+	return svc, user, devices, sms
+}
+
+func deviceLoginRequest(deviceID string) *models.LoginRequest {
+	return &models.LoginRequest{
+		Email:      "john@example.com",
+		Password:   "password123",
+		IPAddress:  "10.0.0.1",
+		DeviceInfo: models.DeviceInfo{DeviceID: deviceID, DeviceName: "Pixel 9", Platform: "android"},
+	}
+}
+
+func TestAuth_LoginDeviceStepUp(t *testing.T) {
+	t.Parallel()
+
+	t.Run("first device is trusted", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, user, devices, sms := newDeviceAuth(t)
+
+		// Act
+		resp, err := svc.Login(context.Background(), deviceLoginRequest(testDeviceID))
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.MFARequired || resp.TokenResponse == nil {
+			t.Fatalf("Expected tokens without a challenge, got %+v", resp)
+		}
+		if len(devices.devices) != 1 || devices.devices[0].DeviceIDHash != helpers.HashToken(testDeviceID) {
+			t.Errorf("Expected the device to be stored by its hash, got %+v", devices.devices)
+		}
+		if len(sms.sent(user.Phone)) != 0 {
+			t.Error("Expected no SMS for the first device")
+		}
+	})
+
+	t.Run("known device skips step-up", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, _, _, _ := newDeviceAuth(t)
+		if _, err := svc.Login(context.Background(), deviceLoginRequest(testDeviceID)); err != nil {
+			t.Fatalf("Failed to log in: %v", err)
+		}
+
+		// Act
+		resp, err := svc.Login(context.Background(), deviceLoginRequest(testDeviceID))
+
+		// Assert
+		if err != nil || resp.MFARequired {
+			t.Errorf("Expected tokens for a known device, got %+v, %v", resp, err)
+		}
+	})
+
+	t.Run("new device is confirmed by SMS", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, user, devices, sms := newDeviceAuth(t)
+		sessions := svc.SessionRepository.(*fakeSessionRepository)
+		if _, err := svc.Login(context.Background(), deviceLoginRequest(testDeviceID)); err != nil {
+			t.Fatalf("Failed to log in: %v", err)
+		}
+
+		// Act
+		resp, err := svc.Login(context.Background(), deviceLoginRequest(testOtherDeviceID))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		tokens, completeErr := svc.CompleteMFALogin(context.Background(), &models.LoginMFARequest{
+			MFAToken:   resp.MFA.Token,
+			Code:       sms.sent(user.Phone)[0],
+			DeviceInfo: models.DeviceInfo{DeviceID: testOtherDeviceID, DeviceName: "iPad", Platform: "ios"},
+		})
+
+		// Assert
+		if !resp.MFARequired || resp.MFA.Reason != constants.MFAReasonNewDevice || resp.TokenResponse != nil {
+			t.Fatalf("Expected a new device challenge without tokens, got %+v", resp)
+		}
+		if len(resp.MFA.Methods) != 1 || resp.MFA.Methods[0] != constants.MFAMethodSMS {
+			t.Errorf("Expected only the SMS method, got %v", resp.MFA.Methods)
+		}
+		if completeErr != nil || tokens.AccessToken == "" {
+			t.Fatalf("Expected the SMS code to complete the login, got %v", completeErr)
+		}
+		if len(devices.devices) != 2 {
+			t.Errorf("Expected the new device to be trusted, got %d devices", len(devices.devices))
+		}
+		session, err := sessions.GetByAccessToken(context.Background(), helpers.HashToken(tokens.AccessToken))
+		if err != nil || !session.DeviceID.Valid || session.DeviceName.String != "iPad" {
+			t.Errorf("Expected the session to record its device, got %+v, %v", session, err)
+		}
+	})
+
+	t.Run("login without a device ID needs step-up once a device is known", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, _, _, _ := newDeviceAuth(t)
+		if _, err := svc.Login(context.Background(), deviceLoginRequest(testDeviceID)); err != nil {
+			t.Fatalf("Failed to log in: %v", err)
+		}
+
+		// Act
+		resp, err := svc.Login(context.Background(), deviceLoginRequest(""))
+
+		// Assert
+		if err != nil || !resp.MFARequired {
+			t.Errorf("Expected a new device challenge, got %+v, %v", resp, err)
+		}
+	})
+
+	t.Run("wrong SMS code counts as a failed login", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, user, devices, _ := newDeviceAuth(t)
+		if _, err := svc.Login(context.Background(), deviceLoginRequest(testDeviceID)); err != nil {
+			t.Fatalf("Failed to log in: %v", err)
+		}
+		resp, err := svc.Login(context.Background(), deviceLoginRequest(testOtherDeviceID))
+		if err != nil {
+			t.Fatalf("Failed to log in: %v", err)
+		}
+
+		// Act
+		_, err = svc.CompleteMFALogin(context.Background(), &models.LoginMFARequest{MFAToken: resp.MFA.Token, Code: "000000"})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidMFACode) {
+			t.Errorf("Expected ErrInvalidMFACode, got %v", err)
+		}
+		if stored, _ := svc.UserRepository.GetByID(context.Background(), user.ID); stored.FailedLoginAttempts != 1 {
+			t.Errorf("Expected one failed login, got %d", stored.FailedLoginAttempts)
+		}
+		if len(devices.devices) != 1 {
+			t.Errorf("Expected the new device not to be trusted, got %d devices", len(devices.devices))
+		}
+	})
+
+	t.Run("invalid platform", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, _, _, _ := newDeviceAuth(t)
+		req := deviceLoginRequest(testDeviceID)
+		req.Platform = "symbian"
+
+		// Act
+		_, err := svc.Login(context.Background(), req)
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != "platform" {
+			t.Errorf("Expected ValidationError on platform, got %v", err)
+		}
+	})
+
+	t.Run("unverified phone skips step-up", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, user, _, sms := newDeviceAuth(t)
+		if _, err := svc.Login(context.Background(), deviceLoginRequest(testDeviceID)); err != nil {
+			t.Fatalf("Failed to log in: %v", err)
+		}
+		user.IsPhoneVerified = false
+
+		// Act
+		resp, err := svc.Login(context.Background(), deviceLoginRequest(testOtherDeviceID))
+
+		// Assert
+		if err != nil || resp.MFARequired {
+			t.Errorf("Expected tokens without a challenge, got %+v, %v", resp, err)
+		}
+		if len(sms.sent(user.Phone)) != 0 {
+			t.Error("Expected no SMS to be sent")
+		}
+	})
+}
+
+func TestDevice_Sessions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lists sessions and devices", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		auth, user, devices, _ := newDeviceAuth(t)
+		tokens := loginForTest(t, auth)
+		current, _ := auth.Authenticate(context.Background(), tokens.AccessToken)
+		svc := &Device{SessionRepository: auth.SessionRepository, DeviceRepository: devices}
+		if _, err := auth.Login(context.Background(), deviceLoginRequest(testDeviceID)); err != nil {
+			t.Fatalf("Failed to log in: %v", err)
+		}
+
+		// Act
+		resp, err := svc.ListSessions(context.Background(), user.ID, current.ID)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("ListSessions returned error: %v", err)
+		}
+		if len(resp.Sessions) != 2 || len(resp.Devices) != 1 {
+			t.Fatalf("Expected two sessions and one device, got %+v", resp)
+		}
+		for _, session := range resp.Sessions {
+			if session.Current != (session.ID == current.ID) {
+				t.Errorf("Expected only session %s to be current, got %+v", current.ID, session)
+			}
+			if session.DeviceID != nil && *session.DeviceID != resp.Devices[0].ID {
+				t.Errorf("Expected the session device to be listed, got %s", *session.DeviceID)
+			}
+		}
+	})
+
+	t.Run("revokes a session of the user", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		auth, user, devices, _ := newDeviceAuth(t)
+		tokens := loginForTest(t, auth)
+		session, _ := auth.Authenticate(context.Background(), tokens.AccessToken)
+		svc := &Device{SessionRepository: auth.SessionRepository, DeviceRepository: devices}
+
+		// Act
+		otherUserErr := svc.RevokeSession(context.Background(), uuid.New(), session.ID)
+		err := svc.RevokeSession(context.Background(), user.ID, session.ID)
+
+		// Assert
+		if !errors.Is(otherUserErr, ErrSessionNotFound) {
+			t.Errorf("Expected ErrSessionNotFound for another user, got %v", otherUserErr)
+		}
+		if err != nil {
+			t.Fatalf("RevokeSession returned error: %v", err)
+		}
+		if _, err := auth.Authenticate(context.Background(), tokens.AccessToken); !errors.Is(err, ErrInvalidAccessToken) {
+			t.Errorf("Expected the revoked session to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("revoking a device signs it out and forgets it", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		auth, user, devices, _ := newDeviceAuth(t)
+		resp, err := auth.Login(context.Background(), deviceLoginRequest(testDeviceID))
+		if err != nil {
+			t.Fatalf("Failed to log in: %v", err)
+		}
+		svc := &Device{SessionRepository: auth.SessionRepository, DeviceRepository: devices}
+		deviceID := devices.devices[0].ID
+
+		// Act
+		err = svc.RevokeDevice(context.Background(), user.ID, deviceID)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("RevokeDevice returned error: %v", err)
+		}
+		if _, err := auth.Authenticate(context.Background(), resp.AccessToken); !errors.Is(err, ErrInvalidAccessToken) {
+			t.Errorf("Expected the device session to be revoked, got %v", err)
+		}
+		if len(devices.devices) != 0 {
+			t.Errorf("Expected the device to be forgotten, got %d devices", len(devices.devices))
+		}
+		if err := svc.RevokeDevice(context.Background(), user.ID, deviceID); !errors.Is(err, ErrDeviceNotFound) {
+			t.Errorf("Expected ErrDeviceNotFound, got %v", err)
+		}
+	})
+}
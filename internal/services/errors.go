@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+)
+
+// Errors of the services are domain errors, so helpers.SendError answers them with the
+// status of their kind. Errors only returned inside a domain.RetryAfterError carry no kind.
+var (
+	// ErrEmailAlreadyRegistered is returned when the email belongs to another user.
+	ErrEmailAlreadyRegistered = domain.NewError(domain.ErrConflict, "email already registered")
+
+	// ErrPhoneAlreadyRegistered is returned when the phone number belongs to another user.
+	ErrPhoneAlreadyRegistered = domain.NewError(domain.ErrConflict, "phone number already registered")
+
+	// ErrUserNotFound is returned when the user does not exist or was deleted.
+	ErrUserNotFound = domain.NewError(domain.ErrNotFound, "user not found")
+
+	// ErrInvalidVerificationToken is returned when an email verification token is unknown, expired or already used.
+	ErrInvalidVerificationToken = domain.NewError(domain.ErrValidation, "invalid or expired verification token")
+
+	// ErrInvalidResetToken is returned when a password reset token is unknown, expired or already used.
+	ErrInvalidResetToken = domain.NewError(domain.ErrValidation, "invalid or expired password reset token")
+
+	// ErrIncorrectPassword is returned when the current password given to change it is wrong.
+	ErrIncorrectPassword = domain.NewError(domain.ErrValidation, "current password is incorrect")
+
+	// ErrInvalidOTP is returned when a phone verification code is wrong, expired, used or out of attempts.
+	ErrInvalidOTP = domain.NewError(domain.ErrValidation, "invalid or expired verification code")
+
+	// ErrTooManyOTPRequests is returned when a phone number has been sent too many verification codes.
+	ErrTooManyOTPRequests = errors.New("too many verification code requests")
+
+	// ErrMFAAlreadyEnabled is returned when a user who already confirmed an authenticator enrolls another.
+	ErrMFAAlreadyEnabled = domain.NewError(domain.ErrConflict, "two-factor authentication already enabled")
+
+	// ErrMFANotEnrolled is returned when confirming an authenticator that was never enrolled.
+	ErrMFANotEnrolled = domain.NewError(domain.ErrValidation, "no authenticator enrollment in progress")
+
+	// ErrTOTPNotEnabled is returned when disabling an authenticator or renewing recovery codes without a confirmed authenticator.
+	ErrTOTPNotEnabled = domain.NewError(domain.ErrValidation, "no authenticator app enabled")
+
+	// ErrPhoneNotVerified is returned when an action needs a verified phone number and the user has none.
+	ErrPhoneNotVerified = domain.NewError(domain.ErrForbidden, "phone number not verified")
+
+	// ErrPINAlreadySet is returned when setting a transaction PIN for a user who already has one.
+	ErrPINAlreadySet = domain.NewError(domain.ErrConflict, "transaction PIN already set")
+
+	// ErrPINNotSet is returned when changing or verifying the transaction PIN of a user who has none.
+	ErrPINNotSet = domain.NewError(domain.ErrConflict, "transaction PIN not set")
+
+	// ErrIncorrectPIN is returned when the current transaction PIN given to change it is wrong.
+	ErrIncorrectPIN = domain.NewError(domain.ErrValidation, "current transaction PIN is incorrect")
+
+	// ErrPINLocked is returned while PIN verification is locked after repeated wrong PINs.
+	ErrPINLocked = errors.New("transaction PIN locked after repeated wrong PINs")
+)
+
+var (
+	// ErrInvalidCredentials is returned when the login identifier or password is wrong.
+	ErrInvalidCredentials = domain.NewError(domain.ErrUnauthorized, "invalid credentials")
+
+	// ErrUserInactive is returned when an inactive user tries to log in.
+	ErrUserInactive = domain.NewError(domain.ErrForbidden, "user account is not active")
+
+	// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired or revoked.
+	ErrInvalidRefreshToken = domain.NewError(domain.ErrUnauthorized, "invalid refresh token")
+
+	// ErrInvalidAccessToken is returned when an access token is malformed, expired or its session is revoked.
+	ErrInvalidAccessToken = domain.NewError(domain.ErrUnauthorized, "invalid access token")
+
+	// ErrAccountTemporarilyLocked is returned while a user is locked out after repeated failed logins.
+	ErrAccountTemporarilyLocked = errors.New("account temporarily locked after repeated failed logins")
+
+	// ErrAccountLocked is returned when a user is locked out until their password is reset.
+	ErrAccountLocked = domain.NewError(domain.ErrLocked, "account locked, reset the password to unlock it")
+
+	// ErrTooManyLoginAttempts is returned when a client IP has failed to log in too often.
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+
+	// ErrInvalidMFAToken is returned when an MFA challenge token is unknown, expired or already used.
+	ErrInvalidMFAToken = domain.NewError(domain.ErrUnauthorized, "invalid or expired MFA token, log in again")
+
+	// ErrInvalidMFACode is returned when a TOTP code or recovery code is wrong or already used.
+	ErrInvalidMFACode = domain.NewError(domain.ErrUnauthorized, "invalid authentication code")
+
+	// ErrInvalidPasskey is returned when a WebAuthn response is malformed, fails verification or
+	// answers an unknown, expired or already used challenge.
+	ErrInvalidPasskey = domain.NewError(domain.ErrUnauthorized, "invalid passkey")
+
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again.
+	ErrRefreshTokenReused = domain.NewError(domain.ErrUnauthorized, "refresh token reuse detected")
+
+	// ErrSessionNotFound is returned when revoking a session that is not an active session of the user.
+	ErrSessionNotFound = domain.NewError(domain.ErrNotFound, "session not found")
+
+	// ErrDeviceNotFound is returned when revoking a device the user never logged in from.
+	ErrDeviceNotFound = domain.NewError(domain.ErrNotFound, "device not found")
+
+	// ErrPasskeyNotFound is returned when deleting a passkey the user did not register.
+	ErrPasskeyNotFound = domain.NewError(domain.ErrNotFound, "passkey not found")
+)
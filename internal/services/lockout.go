@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// checkLockout returns ErrAccountLocked for a hard locked user and a RetryAfterError
+// wrapping ErrAccountTemporarilyLocked while a temporary lockout lasts.
+func checkLockout(user *models.User) error {
+	if user.HardLockedAt != nil {
+		return ErrAccountLocked
+	}
+	if user.LockedUntil != nil {
+		if remaining := time.Until(*user.LockedUntil); remaining > 0 {
+			return &domain.RetryAfterError{Err: ErrAccountTemporarilyLocked, RetryAfter: remaining}
+		}
+	}
+	return nil
+}
+
+// verifyCurrentPassword checks the current password a user enters to change their second
+// factors. Wrong passwords count towards the login lockout, so a stolen session cannot be
+// used to guess it.
+func verifyCurrentPassword(ctx context.Context, repo interfaces.IUserRepository, user *models.User, password string) error {
+	if err := checkLockout(user); err != nil {
+		return err
+	}
+	if !helpers.CheckPassword(user.PasswordHash, password) {
+		if err := recordLoginFailure(ctx, repo, user, ""); err != nil {
+			return err
+		}
+		return ErrIncorrectPassword
+	}
+	return nil
+}
+
+// recordLoginFailure counts a wrong password against the user, whether it was entered to log
+// in or to confirm a change of credentials. Every LOGIN_MAX_FAILED_ATTEMPTS failures lock the
+// account for LOGIN_LOCKOUT_DURATION, doubling with each further lockout up to
+// LOGIN_LOCKOUT_MAX_DURATION. Reaching the threshold again after LOGIN_MAX_LOCKOUTS lockouts
+// hard locks the account until the password is reset.
+func recordLoginFailure(ctx context.Context, repo interfaces.IUserRepository, user *models.User, ipAddress string) error {
+	attempts, err := repo.RecordFailedLogin(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	helpers.LogSecurityEvent(constants.SecurityEventLoginFailed, logrus.Fields{
+		"user_id":    user.ID,
+		"ip_address": ipAddress,
+		"attempts":   attempts,
+	})
+
+	if attempts < helpers.GetEnvInt("LOGIN_MAX_FAILED_ATTEMPTS", constants.DefaultLoginMaxFailedAttempts) {
+		return nil
+	}
+
+	lockouts := user.LockoutCount + 1
+	if lockouts > helpers.GetEnvInt("LOGIN_MAX_LOCKOUTS", constants.DefaultLoginMaxLockouts) {
+		if err := repo.HardLock(ctx, user.ID); err != nil {
+			return err
+		}
+		helpers.LogSecurityEvent(constants.SecurityEventAccountHardLocked, logrus.Fields{
+			"user_id":    user.ID,
+			"ip_address": ipAddress,
+		})
+		return nil
+	}
+
+	until := time.Now().Add(lockoutDuration(lockouts))
+	if err := repo.Lock(ctx, user.ID, lockouts, until); err != nil {
+		return err
+	}
+	helpers.LogSecurityEvent(constants.SecurityEventAccountLocked, logrus.Fields{
+		"user_id":       user.ID,
+		"ip_address":    ipAddress,
+		"lockout_count": lockouts,
+		"locked_until":  until,
+	})
+	return nil
+}
+
+// lockoutDuration returns how long the given lockout lasts: the base duration doubled for
+// every earlier lockout, capped at the maximum duration.
+func lockoutDuration(lockouts int) time.Duration {
+	duration := helpers.GetEnvDuration("LOGIN_LOCKOUT_DURATION", constants.DefaultLoginLockoutDuration)
+	maxDuration := helpers.GetEnvDuration("LOGIN_LOCKOUT_MAX_DURATION", constants.DefaultLoginLockoutMaxDuration)
+
+	for i := 1; i < lockouts && duration < maxDuration; i++ {
+		duration *= 2
+	}
+	return min(duration, maxDuration)
+}
+
+// clearLoginFailures resets the failed login counters after a successful login.
+func (s *Auth) clearLoginFailures(ctx context.Context, user *models.User) error {
+	if user.FailedLoginAttempts == 0 && user.LockoutCount == 0 && user.LockedUntil == nil {
+		return nil
+	}
+	return s.UserRepository.Unlock(ctx, user.ID)
+}
+
+// loginIPBlocked reports whether the client IP has failed to log in too often and
+// the time until it may try again.
+func (s *Auth) loginIPBlocked(ipAddress string) (bool, time.Duration) {
+	if s.LoginIPLimiter == nil || ipAddress == "" {
+		return false, 0
+	}
+	return s.LoginIPLimiter.Exceeded(ipAddress)
+}
+
+// recordIPFailure counts a failed login against the client IP.
+func (s *Auth) recordIPFailure(ipAddress string) {
+	if s.LoginIPLimiter == nil || ipAddress == "" {
+		return
+	}
+
+	s.LoginIPLimiter.Allow(ipAddress)
+	if blocked, retryAfter := s.LoginIPLimiter.Exceeded(ipAddress); blocked {
+		helpers.LogSecurityEvent(constants.SecurityEventLoginIPBlocked, logrus.Fields{
+			"ip_address":  ipAddress,
+			"retry_after": retryAfter.String(),
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// failLogins attempts n logins with a wrong password.
+func failLogins(t *testing.T, svc *Auth, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		_, err := svc.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "wrong-password"})
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Expected ErrInvalidCredentials on attempt %d, got %v", i+1, err)
+		}
+	}
+}
+
+func TestAuth_Login_Lockout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("temporary lockout after repeated failures", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		users := newFakeUserRepository(user)
+		svc := &Auth{UserRepository: users, SessionRepository: newFakeSessionRepository(), RoleRepository: newFakeRoleRepository()}
+		failLogins(t, svc, constants.DefaultLoginMaxFailedAttempts)
+
+		// Act
+		_, err := svc.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "password123"})
+
+		// Assert
+		var retryErr *domain.RetryAfterError
+		if !errors.As(err, &retryErr) || !errors.Is(err, ErrAccountTemporarilyLocked) {
+			t.Fatalf("Expected ErrAccountTemporarilyLocked, got %v", err)
+		}
+		if retryErr.RetryAfter <= 0 || retryErr.RetryAfter > constants.DefaultLoginLockoutDuration {
+			t.Errorf("Expected a retry delay within the lockout duration, got %s", retryErr.RetryAfter)
+		}
+		stored, _ := users.GetByID(context.Background(), user.ID)
+		if stored.LockoutCount != 1 || stored.FailedLoginAttempts != 0 {
+			t.Errorf("Expected one lockout and a new failure count, got %d and %d", stored.LockoutCount, stored.FailedLoginAttempts)
+		}
+	})
+
+	t.Run("expired lockout allows login and clears counters", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		expired := time.Now().Add(-time.Second)
+		user.LockedUntil = &expired
+		user.LockoutCount = 2
+		user.FailedLoginAttempts = 1
+		users := newFakeUserRepository(user)
+		svc := &Auth{UserRepository: users, SessionRepository: newFakeSessionRepository(), RoleRepository: newFakeRoleRepository()}
+
+		// Act
+		_, err := svc.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "password123"})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		stored, _ := users.GetByID(context.Background(), user.ID)
+		if stored.LockedUntil != nil || stored.LockoutCount != 0 || stored.FailedLoginAttempts != 0 {
+			t.Errorf("Expected the counters to be cleared, got %+v", stored)
+		}
+	})
+
+	t.Run("hard lock after exhausting lockouts", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		user.LockoutCount = constants.DefaultLoginMaxLockouts
+		users := newFakeUserRepository(user)
+		svc := &Auth{UserRepository: users, SessionRepository: newFakeSessionRepository(), RoleRepository: newFakeRoleRepository()}
+		failLogins(t, svc, constants.DefaultLoginMaxFailedAttempts)
+
+		// Act
+		_, err := svc.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "password123"})
+
+		// Assert
+		if !errors.Is(err, ErrAccountLocked) {
+			t.Fatalf("Expected ErrAccountLocked, got %v", err)
+		}
+		if stored, _ := users.GetByID(context.Background(), user.ID); stored.HardLockedAt == nil {
+			t.Error("Expected the hard lock to be stored")
+		}
+	})
+
+	t.Run("client IP blocked after repeated failures", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(newLoginUser(t, true)),
+			SessionRepository: newFakeSessionRepository(),
+			RoleRepository:    newFakeRoleRepository(),
+			LoginIPLimiter:    helpers.NewRateLimiter(2, time.Hour),
+		}
+		for _, email := range []string{"nobody@example.com", "john@example.com"} {
+			req := &models.LoginRequest{Email: email, Password: "wrong-password", IPAddress: "192.0.2.1"}
+			if _, err := svc.Login(context.Background(), req); !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("Expected ErrInvalidCredentials, got %v", err)
+			}
+		}
+
+		// Act
+		_, err := svc.Login(context.Background(), &models.LoginRequest{
+			Email:     "john@example.com",
+			Password:  "password123",
+			IPAddress: "192.0.2.1",
+		})
+		_, otherErr := svc.Login(context.Background(), &models.LoginRequest{
+			Email:     "john@example.com",
+			Password:  "password123",
+			IPAddress: "192.0.2.2",
+		})
+
+		// Assert
+		if !errors.Is(err, ErrTooManyLoginAttempts) {
+			t.Errorf("Expected ErrTooManyLoginAttempts, got %v", err)
+		}
+		if otherErr != nil {
+			t.Errorf("Expected other IPs to log in, got %v", otherErr)
+		}
+	})
+}
+
+func TestLockoutDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		lockouts int
+		want     time.Duration
+	}{
+		{lockouts: 1, want: time.Minute},
+		{lockouts: 2, want: 2 * time.Minute},
+		{lockouts: 3, want: 4 * time.Minute},
+		{lockouts: 20, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		// Act
+		got := lockoutDuration(tt.lockouts)
+
+		// Assert
+		if got != tt.want {
+			t.Errorf("Expected lockout %d to last %s, got %s", tt.lockouts, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// LoginRisk service implementation. It compares every new session with the session history
+// of its user and alerts the user when the login looks like an account takeover.
+type LoginRisk struct {
+	SessionRepository interfaces.ISessionRepository
+	Notifier          interfaces.INotifier
+	// GeoLocator places IP addresses for the impossible travel check; nil disables the check
+	GeoLocator interfaces.IGeoLocator
+	// Denylist holds the IP ranges logins are not expected from, e.g. known abuse networks
+	Denylist []netip.Prefix
+}
+
+// AssessLogin flags a session created from a device the user never logged in from, from a
+// location too far from the previous login to have travelled in between, or from a
+// denylisted IP range. Suspicious logins are written to the security log and the user is
+// notified.
+func (s *LoginRisk) AssessLogin(
+	ctx context.Context, user *models.User, session *models.UserSession,
+) (*models.LoginRiskAssessment, error) {
+	history, err := s.SessionRepository.ListRecentByUserID(ctx, user.ID, constants.LoginRiskHistorySize+1)
+	if err != nil {
+		return nil, err
+	}
+	history = slices.DeleteFunc(history, func(prev *models.UserSession) bool { return prev.ID == session.ID })
+
+	assessment := &models.LoginRiskAssessment{
+		SessionID:  session.ID,
+		IPAddress:  session.IPAddress.String,
+		UserAgent:  session.UserAgent.String,
+		DeviceName: session.DeviceName.String,
+		Location:   s.locate(session.IPAddress.String),
+		Flags:      []string{},
+	}
+
+	if len(history) > 0 && !knownDevice(session, history) {
+		assessment.Flags = append(assessment.Flags, constants.LoginRiskFlagNewDevice)
+		assessment.Score += constants.LoginRiskScoreNewDevice
+	}
+	if s.impossibleTravel(assessment.Location, session, history) {
+		assessment.Flags = append(assessment.Flags, constants.LoginRiskFlagImpossibleTravel)
+		assessment.Score += constants.LoginRiskScoreImpossibleTravel
+	}
+	if s.denylisted(session.IPAddress.String) {
+		assessment.Flags = append(assessment.Flags, constants.LoginRiskFlagDenylistedIP)
+		assessment.Score += constants.LoginRiskScoreDenylistedIP
+	}
+	assessment.Score = min(assessment.Score, constants.LoginRiskMaxScore)
+
+	if !assessment.Suspicious() {
+		return assessment, nil
+	}
+
+	helpers.LogSecurityEvent(constants.SecurityEventSuspiciousLogin, logrus.Fields{
+		"user_id":    user.ID,
+		"session_id": session.ID,
+		"ip_address": assessment.IPAddress,
+		"flags":      assessment.Flags,
+		"score":      assessment.Score,
+	})
+
+	if err := s.Notifier.SendLoginAlert(ctx, user, assessment); err != nil {
+		return nil, err
+	}
+	return assessment, nil
+}
+
+// knownDevice reports whether an earlier session came from the same device as session.
+// Sessions are matched by device ID when both have one, and by user agent otherwise.
+func knownDevice(session *models.UserSession, history []*models.UserSession) bool {
+	for _, prev := range history {
+		if session.DeviceID.Valid && prev.DeviceID.Valid {
+			if prev.DeviceID.UUID == session.DeviceID.UUID {
+				return true
+			}
+			continue
+		}
+		if session.UserAgent.Valid && prev.UserAgent.String == session.UserAgent.String {
+			return true
+		}
+	}
+	return false
+}
+
+// impossibleTravel reports whether the distance between the location of a login and that
+// of the most recent earlier login with an IP address could not have been covered since,
+// at LOGIN_MAX_TRAVEL_SPEED_KMH. The accuracy radius of both locations is given the
+// benefit of the doubt, and distances under LoginTravelMinDistance are never flagged.
+func (s *LoginRisk) impossibleTravel(location *models.GeoLocation, session *models.UserSession, history []*models.UserSession) bool {
+	if location == nil {
+		return false
+	}
+
+	idx := slices.IndexFunc(history, func(prev *models.UserSession) bool { return prev.IPAddress.Valid })
+	if idx < 0 {
+		return false
+	}
+	prev := history[idx]
+
+	addr, _ := helpers.ParseIP(session.IPAddress.String)
+	if prevAddr, ok := helpers.ParseIP(prev.IPAddress.String); !ok || prevAddr == addr {
+		return false
+	}
+	prevLocation := s.locate(prev.IPAddress.String)
+	if prevLocation == nil {
+		return false
+	}
+
+	distance := helpers.DistanceKm(prevLocation.Latitude, prevLocation.Longitude, location.Latitude, location.Longitude)
+	distance -= float64(prevLocation.AccuracyRadius + location.AccuracyRadius)
+	if distance < constants.LoginTravelMinDistance {
+		return false
+	}
+
+	hours := time.Since(prev.UpdatedAt).Hours()
+	maxSpeed := helpers.GetEnvInt("LOGIN_MAX_TRAVEL_SPEED_KMH", constants.DefaultLoginMaxTravelSpeed)
+	return hours <= 0 || distance/hours > float64(maxSpeed)
+}
+
+// denylisted reports whether an IP address falls in one of the denylisted ranges.
+func (s *LoginRisk) denylisted(ip string) bool {
+	addr, ok := helpers.ParseIP(ip)
+	if !ok {
+		return false
+	}
+	return slices.ContainsFunc(s.Denylist, func(prefix netip.Prefix) bool { return prefix.Contains(addr) })
+}
+
+// locate returns the location of an IP address, or nil when it is unknown or there is no
+// GeoLocator. Lookup failures only disable the travel check, so they are logged and ignored.
+func (s *LoginRisk) locate(ip string) *models.GeoLocation {
+	if s.GeoLocator == nil || ip == "" {
+		return nil
+	}
+
+	location, err := s.GeoLocator.Locate(ip)
+	if err != nil {
+		helpers.Logger.Warnf("Failed to locate IP address %s: %v", ip, err)
+		return nil
+	}
+	return location
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+const testAppUserAgent = "ewallet-android/2.4.0"
+
+// fakeGeoLocator is an IGeoLocator over a fixed table of IP addresses.
+type fakeGeoLocator map[string]*models.GeoLocation
+
+func (f fakeGeoLocator) Locate(ip string) (*models.GeoLocation, error) {
+	return f[ip], nil
+}
+
+var testGeoLocator = fakeGeoLocator{
+	"10.0.0.1":    {Country: "ID", City: "Jakarta", Latitude: -6.2088, Longitude: 106.8456, AccuracyRadius: 20},
+	"10.0.0.2":    {Country: "ID", City: "Jakarta", Latitude: -6.1751, Longitude: 106.8650, AccuracyRadius: 20},
+	"192.0.2.10":  {Country: "GB", City: "London", Latitude: 51.5074, Longitude: -0.1278, AccuracyRadius: 50},
+	"192.0.2.200": {Country: "SG", City: "Singapore", Latitude: 1.3521, Longitude: 103.8198, AccuracyRadius: 1000},
+}
+
+// addTestSession stores an earlier session of a user that was last used at lastUsed.
+func addTestSession(repo *fakeSessionRepository, userID uuid.UUID, ip, userAgent string, lastUsed time.Time) *models.UserSession {
+	session := &models.UserSession{
+		ID:                    uuid.New(),
+		UserID:                userID,
+		IPAddress:             sql.NullString{String: ip, Valid: ip != ""},
+		UserAgent:             sql.NullString{String: userAgent, Valid: userAgent != ""},
+		RefreshTokenExpiresAt: lastUsed.Add(24 * time.Hour),
+		CreatedAt:             lastUsed,
+		UpdatedAt:             lastUsed,
+	}
+	repo.sessions[session.ID] = session
+	return session
+}
+
+// newLoginRisk returns a LoginRisk service over in-memory sessions and the test locations.
+func newLoginRisk() (*LoginRisk, *fakeSessionRepository, *fakeNotifier) {
+	sessions := newFakeSessionRepository()
+	notifier := newFakeNotifier()
+	return &LoginRisk{
+		SessionRepository: sessions,
+		Notifier:          notifier,
+		GeoLocator:        testGeoLocator,
+		Denylist:          []netip.Prefix{netip.MustParsePrefix("198.51.100.0/24")},
+	}, sessions, notifier
+}
+
+func TestLoginRisk_AssessLogin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		history   func(repo *fakeSessionRepository, userID uuid.UUID)
+		name      string
+		ip        string
+		userAgent string
+		wantFlags []string
+		deviceID  uuid.NullUUID
+		wantScore int
+	}{
+		{
+			name:      "first login",
+			history:   func(*fakeSessionRepository, uuid.UUID) {},
+			ip:        "10.0.0.1",
+			userAgent: testAppUserAgent,
+		},
+		{
+			name: "known device from a nearby IP",
+			history: func(repo *fakeSessionRepository, userID uuid.UUID) {
+				addTestSession(repo, userID, "10.0.0.1", testAppUserAgent, time.Now().Add(-time.Minute))
+			},
+			ip:        "10.0.0.2",
+			userAgent: testAppUserAgent,
+		},
+		{
+			name: "new device",
+			history: func(repo *fakeSessionRepository, userID uuid.UUID) {
+				addTestSession(repo, userID, "10.0.0.1", testAppUserAgent, time.Now().Add(-time.Hour))
+			},
+			ip:        "10.0.0.1",
+			userAgent: "curl/8.5.0",
+			wantFlags: []string{constants.LoginRiskFlagNewDevice},
+			wantScore: constants.LoginRiskScoreNewDevice,
+		},
+		{
+			name: "known device ID with another user agent",
+			history: func(repo *fakeSessionRepository, userID uuid.UUID) {
+				prev := addTestSession(repo, userID, "10.0.0.1", testAppUserAgent, time.Now().Add(-time.Hour))
+				prev.DeviceID = uuid.NullUUID{UUID: uuid.MustParse(testDeviceID), Valid: true}
+			},
+			ip:        "10.0.0.1",
+			userAgent: "ewallet-android/2.5.0",
+			deviceID:  uuid.NullUUID{UUID: uuid.MustParse(testDeviceID), Valid: true},
+		},
+		{
+			name: "other device ID with the same user agent",
+			history: func(repo *fakeSessionRepository, userID uuid.UUID) {
+				prev := addTestSession(repo, userID, "10.0.0.1", testAppUserAgent, time.Now().Add(-time.Hour))
+				prev.DeviceID = uuid.NullUUID{UUID: uuid.MustParse(testDeviceID), Valid: true}
+			},
+			ip:        "10.0.0.1",
+			userAgent: testAppUserAgent,
+			deviceID:  uuid.NullUUID{UUID: uuid.MustParse(testOtherDeviceID), Valid: true},
+			wantFlags: []string{constants.LoginRiskFlagNewDevice},
+			wantScore: constants.LoginRiskScoreNewDevice,
+		},
+		{
+			name: "impossible travel",
+			history: func(repo *fakeSessionRepository, userID uuid.UUID) {
+				addTestSession(repo, userID, "10.0.0.1", testAppUserAgent, time.Now().Add(-time.Hour))
+			},
+			ip:        "192.0.2.10",
+			userAgent: testAppUserAgent,
+			wantFlags: []string{constants.LoginRiskFlagImpossibleTravel},
+			wantScore: constants.LoginRiskScoreImpossibleTravel,
+		},
+		{
+			name: "enough time to travel",
+			history: func(repo *fakeSessionRepository, userID uuid.UUID) {
+				addTestSession(repo, userID, "10.0.0.1", testAppUserAgent, time.Now().Add(-24*time.Hour))
+			},
+			ip:        "192.0.2.10",
+			userAgent: testAppUserAgent,
+		},
+		{
+			name: "only the most recent login is compared",
+			history: func(repo *fakeSessionRepository, userID uuid.UUID) {
+				addTestSession(repo, userID, "192.0.2.10", testAppUserAgent, time.Now().Add(-48*time.Hour))
+				addTestSession(repo, userID, "10.0.0.1", testAppUserAgent, time.Now().Add(-2*time.Hour))
+			},
+			ip:        "10.0.0.2",
+			userAgent: testAppUserAgent,
+		},
+		{
+			name: "distance within the accuracy radius",
+			history: func(repo *fakeSessionRepository, userID uuid.UUID) {
+				addTestSession(repo, userID, "10.0.0.1", testAppUserAgent, time.Now().Add(-time.Minute))
+			},
+			ip:        "192.0.2.200",
+			userAgent: testAppUserAgent,
+		},
+		{
+			name: "unknown location",
+			history: func(repo *fakeSessionRepository, userID uuid.UUID) {
+				addTestSession(repo, userID, "10.0.0.1", testAppUserAgent, time.Now().Add(-time.Minute))
+			},
+			ip:        "203.0.113.7",
+			userAgent: testAppUserAgent,
+		},
+		{
+			name:      "denylisted IP on first login",
+			history:   func(*fakeSessionRepository, uuid.UUID) {},
+			ip:        "198.51.100.23",
+			userAgent: testAppUserAgent,
+			wantFlags: []string{constants.LoginRiskFlagDenylistedIP},
+			wantScore: constants.LoginRiskScoreDenylistedIP,
+		},
+		{
+			name: "score is capped",
+			history: func(repo *fakeSessionRepository, userID uuid.UUID) {
+				addTestSession(repo, userID, "10.0.0.1", testAppUserAgent, time.Now().Add(-time.Minute))
+			},
+			ip:        "198.51.100.23",
+			userAgent: "curl/8.5.0",
+			wantFlags: []string{constants.LoginRiskFlagNewDevice, constants.LoginRiskFlagDenylistedIP},
+			wantScore: constants.LoginRiskMaxScore,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			svc, sessions, notifier := newLoginRisk()
+			user := &models.User{ID: uuid.New(), Email: "john@example.com"}
+			tt.history(sessions, user.ID)
+			session := addTestSession(sessions, user.ID, tt.ip, tt.userAgent, time.Now())
+			session.DeviceID = tt.deviceID
+
+			// Act
+			assessment, err := svc.AssessLogin(context.Background(), user, session)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(assessment.Flags) != len(tt.wantFlags) || assessment.Score != tt.wantScore {
+				t.Fatalf("Expected flags %v with score %d, got %v with score %d",
+					tt.wantFlags, tt.wantScore, assessment.Flags, assessment.Score)
+			}
+			for _, flag := range tt.wantFlags {
+				if !assessment.HasFlag(flag) {
+					t.Errorf("Expected flag %s, got %v", flag, assessment.Flags)
+				}
+			}
+
+			alerts := notifier.sentAlerts(user.ID)
+			if tt.wantFlags == nil && len(alerts) != 0 {
+				t.Errorf("Expected no login alert, got %d", len(alerts))
+			}
+			if tt.wantFlags != nil && (len(alerts) != 1 || alerts[0].SessionID != session.ID) {
+				t.Errorf("Expected a login alert for session %s, got %v", session.ID, alerts)
+			}
+		})
+	}
+}
+
+func TestLoginRisk_AssessLoginErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("session history unavailable", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, sessions, _ := newLoginRisk()
+		sessions.err = errors.New("database unavailable")
+		user := &models.User{ID: uuid.New()}
+
+		// Act
+		_, err := svc.AssessLogin(context.Background(), user, &models.UserSession{ID: uuid.New(), UserID: user.ID})
+
+		// Assert
+		if err == nil {
+			t.Error("Expected an error, got nil")
+		}
+	})
+
+	t.Run("alert not delivered", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, sessions, notifier := newLoginRisk()
+		notifier.err = errors.New("mail server unavailable")
+		user := &models.User{ID: uuid.New()}
+		session := addTestSession(sessions, user.ID, "198.51.100.23", testAppUserAgent, time.Now())
+
+		// Act
+		_, err := svc.AssessLogin(context.Background(), user, session)
+
+		// Assert
+		if !errors.Is(err, notifier.err) {
+			t.Errorf("Expected the notifier error, got %v", err)
+		}
+	})
+}
+
+func TestAuth_LoginRiskAssessment(t *testing.T) {
+	t.Parallel()
+
+	t.Run("suspicious login is reported", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		risk, sessions, notifier := newLoginRisk()
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(user),
+			SessionRepository: sessions,
+			RoleRepository:    newFakeRoleRepository(),
+			LoginRiskAssessor: risk,
+		}
+		addTestSession(sessions, user.ID, "10.0.0.1", testAppUserAgent, time.Now().Add(-time.Hour))
+		req := &models.LoginRequest{Email: user.Email, Password: "password123", IPAddress: "192.0.2.10", UserAgent: "curl/8.5.0"}
+
+		// Act
+		resp, err := svc.Login(context.Background(), req)
+
+		// Assert
+		if err != nil || resp.TokenResponse == nil {
+			t.Fatalf("Expected tokens, got %+v, %v", resp, err)
+		}
+		alerts := notifier.sentAlerts(user.ID)
+		if len(alerts) != 1 {
+			t.Fatalf("Expected 1 login alert, got %d", len(alerts))
+		}
+		if !alerts[0].HasFlag(constants.LoginRiskFlagNewDevice) || !alerts[0].HasFlag(constants.LoginRiskFlagImpossibleTravel) {
+			t.Errorf("Expected new device and impossible travel, got %v", alerts[0].Flags)
+		}
+		if alerts[0].Location == nil || alerts[0].Location.City != "London" {
+			t.Errorf("Expected the login location, got %+v", alerts[0].Location)
+		}
+	})
+
+	t.Run("failed assessment does not block login", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		risk, sessions, notifier := newLoginRisk()
+		notifier.err = errors.New("mail server unavailable")
+		svc := &Auth{
+			UserRepository:    newFakeUserRepository(user),
+			SessionRepository: sessions,
+			RoleRepository:    newFakeRoleRepository(),
+			LoginRiskAssessor: risk,
+		}
+		req := &models.LoginRequest{Email: user.Email, Password: "password123", IPAddress: "198.51.100.23"}
+
+		// Act
+		resp, err := svc.Login(context.Background(), req)
+
+		// Assert
+		if err != nil || resp.TokenResponse == nil {
+			t.Fatalf("Expected tokens despite the failed alert, got %+v, %v", resp, err)
+		}
+	})
+}
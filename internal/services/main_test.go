@@ -0,0 +1,23 @@
+package services
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+)
+
+func TestMain(m *testing.M) {
+	// Services log failures they recover from, such as undelivered notifications
+	helpers.SetupLogger()
+
+	// Cheap hash parameters keep the many password hashes in these tests fast
+	os.Setenv("PASSWORD_HASH_MEMORY", "1024")
+	os.Setenv("PASSWORD_HASH_TIME", "1")
+	os.Setenv("PASSWORD_HASH_PARALLELISM", "1")
+	if err := helpers.SetupPasswordHashing(); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
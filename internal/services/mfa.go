@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// recoveryCodeBytes is the random size of a recovery code, shown as 10 hex characters.
+const recoveryCodeBytes = 5
+
+// MFA service implementation.
+type MFA struct {
+	UserRepository interfaces.IUserRepository
+	MFARepository  interfaces.IMFARepository
+}
+
+// EnrollTOTP checks the current password, generates a new TOTP secret for the user and
+// stores it encrypted until it is confirmed. Enrolling again before confirming replaces the
+// pending secret. Users whose MFA was enabled by a passkey can still add an authenticator app.
+func (s *MFA) EnrollTOTP(ctx context.Context, userID uuid.UUID, req *models.EnrollTOTPRequest) (*models.TOTPEnrollmentResponse, error) {
+	if err := helpers.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, mapUserNotFound(err)
+	}
+
+	if err := verifyCurrentPassword(ctx, s.UserRepository, user, req.Password); err != nil {
+		return nil, err
+	}
+
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	secretEncrypted, err := helpers.EncryptSecret(secret, user.ID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.MFARepository.SavePendingTOTP(ctx, user.ID, secretEncrypted); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	issuer := helpers.GetEnv("TOTP_ISSUER", constants.DefaultTOTPIssuer)
+	return &models.TOTPEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: helpers.TOTPURI(issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP checks the current password and the first code of a pending authenticator,
+// enables MFA for the user and returns a new set of one-time recovery codes, replacing any
+// earlier ones.
+func (s *MFA) ConfirmTOTP(ctx context.Context, userID uuid.UUID, req *models.ConfirmTOTPRequest) (*models.RecoveryCodesResponse, error) {
+	req.Code = strings.TrimSpace(req.Code)
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, mapUserNotFound(err)
+	}
+
+	if err := verifyCurrentPassword(ctx, s.UserRepository, user, req.Password); err != nil {
+		return nil, err
+	}
+
+	totp, err := s.MFARepository.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+	if totp.ConfirmedAt.Valid {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := helpers.DecryptSecret(totp.SecretEncrypted, userID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := helpers.ValidateTOTP(secret, req.Code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.MFARepository.ConfirmTOTP(ctx, userID, step, hashes); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	helpers.LogSecurityEvent(constants.SecurityEventMFAEnabled, logrus.Fields{
+		"user_id": userID,
+		"method":  constants.MFAMethodTOTP,
+	})
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTOTP removes the authenticator app and recovery codes of the user after checking a
+// current TOTP code or an unused recovery code. MFA stays enabled while the user has a passkey.
+func (s *MFA) DisableTOTP(ctx context.Context, userID uuid.UUID, req *models.MFACodeRequest) error {
+	if err := s.verifyCurrentCode(ctx, userID, req); err != nil {
+		return err
+	}
+
+	if err := s.MFARepository.DeleteTOTP(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrTOTPNotEnabled
+		}
+		return err
+	}
+
+	helpers.LogSecurityEvent(constants.SecurityEventMFADisabled, logrus.Fields{
+		"user_id": userID,
+		"method":  constants.MFAMethodTOTP,
+	})
+	return nil
+}
+
+// RegenerateRecoveryCodes returns a new set of one-time recovery codes after checking a
+// current TOTP code or an unused recovery code. Every earlier recovery code stops working.
+func (s *MFA) RegenerateRecoveryCodes(
+	ctx context.Context, userID uuid.UUID, req *models.MFACodeRequest,
+) (*models.RecoveryCodesResponse, error) {
+	if err := s.verifyCurrentCode(ctx, userID, req); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.MFARepository.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrTOTPNotEnabled
+		}
+		return nil, err
+	}
+
+	helpers.LogSecurityEvent(constants.SecurityEventRecoveryCodesRenewed, logrus.Fields{
+		"user_id": userID,
+	})
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// verifyCurrentCode checks the TOTP code or recovery code confirming a change to the
+// authenticator app of a user.
+func (s *MFA) verifyCurrentCode(ctx context.Context, userID uuid.UUID, req *models.MFACodeRequest) error {
+	if err := helpers.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return mapUserNotFound(err)
+	}
+
+	totp, err := s.MFARepository.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrTOTPNotEnabled
+		}
+		return err
+	}
+	if !totp.ConfirmedAt.Valid {
+		return ErrTOTPNotEnabled
+	}
+
+	return verifyCurrentMFACode(ctx, s.UserRepository, s.MFARepository, user, req.Code)
+}
+
+// generateRecoveryCodes returns new recovery codes formatted as "xxxxx-xxxxx" and the
+// hashes to store for them.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, constants.MFARecoveryCodeCount)
+	hashes := make([]string, 0, constants.MFARecoveryCodeCount)
+
+	for range constants.MFARecoveryCodeCount {
+		raw := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		code := hex.EncodeToString(raw)
+		codes = append(codes, code[:len(code)/2]+"-"+code[len(code)/2:])
+		hashes = append(hashes, helpers.HashToken(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeMFACode strips the separators users may type or copy along with a code.
+func normalizeMFACode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+// verifyMFACode accepts a current TOTP code or an unused recovery code of the user and
+// returns the method it matched. Each TOTP code and recovery code is accepted only once.
+func verifyMFACode(ctx context.Context, repo interfaces.IMFARepository, userID uuid.UUID, code string) (string, error) {
+	code = normalizeMFACode(code)
+
+	if len(code) != helpers.TOTPDigits {
+		if err := repo.UseRecoveryCode(ctx, userID, helpers.HashToken(code)); err != nil {
+			return "", mapMFACodeNotFound(err)
+		}
+		return constants.MFAMethodRecoveryCode, nil
+	}
+
+	totp, err := repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return "", mapMFACodeNotFound(err)
+	}
+	if !totp.ConfirmedAt.Valid {
+		return "", ErrInvalidMFACode
+	}
+
+	secret, err := helpers.DecryptSecret(totp.SecretEncrypted, userID[:])
+	if err != nil {
+		return "", err
+	}
+
+	step, ok := helpers.ValidateTOTP(secret, code, time.Now())
+	if !ok || step <= totp.LastUsedStep {
+		return "", ErrInvalidMFACode
+	}
+
+	if err := repo.UseTOTPStep(ctx, userID, step); err != nil {
+		return "", mapMFACodeNotFound(err)
+	}
+	return constants.MFAMethodTOTP, nil
+}
+
+// verifyCurrentMFACode checks the TOTP code or recovery code a user enters to change their
+// second factors. Wrong codes count towards the login lockout like wrong passwords.
+func verifyCurrentMFACode(
+	ctx context.Context, users interfaces.IUserRepository, mfa interfaces.IMFARepository, user *models.User, code string,
+) error {
+	if err := checkLockout(user); err != nil {
+		return err
+	}
+
+	method, err := verifyMFACode(ctx, mfa, user.ID, code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			if err := recordLoginFailure(ctx, users, user, ""); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+
+	if method == constants.MFAMethodRecoveryCode {
+		helpers.LogSecurityEvent(constants.SecurityEventRecoveryCodeUsed, logrus.Fields{
+			"user_id": user.ID,
+		})
+	}
+	return nil
+}
+
+func mapMFACodeNotFound(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrInvalidMFACode
+	}
+	return err
+}
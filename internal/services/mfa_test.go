@@ -0,0 +1,600 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// fakeMFARepository is an in-memory IMFARepository for service tests.
+type fakeMFARepository struct {
+	totp          map[uuid.UUID]*models.UserTOTP
+	recoveryCodes map[uuid.UUID]map[string]bool
+	mu            sync.Mutex
+}
+
+func newFakeMFARepository() *fakeMFARepository {
+	return &fakeMFARepository{
+		totp:          make(map[uuid.UUID]*models.UserTOTP),
+		recoveryCodes: make(map[uuid.UUID]map[string]bool),
+	}
+}
+
+func (f *fakeMFARepository) SavePendingTOTP(_ context.Context, userID uuid.UUID, secretEncrypted string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.totp[userID]; ok && existing.ConfirmedAt.Valid {
+		return domain.NewError(domain.ErrNotFound, "unconfirmed TOTP not found")
+	}
+	f.totp[userID] = &models.UserTOTP{UserID: userID, SecretEncrypted: secretEncrypted, CreatedAt: time.Now()}
+	return nil
+}
+
+func (f *fakeMFARepository) GetTOTP(_ context.Context, userID uuid.UUID) (*models.UserTOTP, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	totp, ok := f.totp[userID]
+	if !ok {
+		return nil, domain.NewError(domain.ErrNotFound, "TOTP not found")
+	}
+	found := *totp
+	return &found, nil
+}
+
+func (f *fakeMFARepository) ConfirmTOTP(_ context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	totp, ok := f.totp[userID]
+	if !ok || totp.ConfirmedAt.Valid {
+		return domain.NewError(domain.ErrNotFound, "unconfirmed TOTP not found")
+	}
+	totp.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	totp.LastUsedStep = step
+	f.recoveryCodes[userID] = make(map[string]bool)
+	for _, hash := range recoveryCodeHashes {
+		f.recoveryCodes[userID][hash] = false
+	}
+	return nil
+}
+
+func (f *fakeMFARepository) UseTOTPStep(_ context.Context, userID uuid.UUID, step int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	totp, ok := f.totp[userID]
+	if !ok || !totp.ConfirmedAt.Valid || totp.LastUsedStep >= step {
+		return domain.NewError(domain.ErrNotFound, "TOTP step already used")
+	}
+	totp.LastUsedStep = step
+	return nil
+}
+
+func (f *fakeMFARepository) UseRecoveryCode(_ context.Context, userID uuid.UUID, codeHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	used, ok := f.recoveryCodes[userID][codeHash]
+	if !ok || used {
+		return domain.NewError(domain.ErrNotFound, "recovery code not found")
+	}
+	f.recoveryCodes[userID][codeHash] = true
+	return nil
+}
+
+func (f *fakeMFARepository) ReplaceRecoveryCodes(_ context.Context, userID uuid.UUID, recoveryCodeHashes []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	totp, ok := f.totp[userID]
+	if !ok || !totp.ConfirmedAt.Valid {
+		return domain.NewError(domain.ErrNotFound, "confirmed TOTP not found")
+	}
+	f.recoveryCodes[userID] = make(map[string]bool)
+	for _, hash := range recoveryCodeHashes {
+		f.recoveryCodes[userID][hash] = false
+	}
+	return nil
+}
+
+func (f *fakeMFARepository) DeleteTOTP(_ context.Context, userID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	totp, ok := f.totp[userID]
+	if !ok || !totp.ConfirmedAt.Valid {
+		return domain.NewError(domain.ErrNotFound, "confirmed TOTP not found")
+	}
+	delete(f.totp, userID)
+	delete(f.recoveryCodes, userID)
+	return nil
+}
+
+// currentTOTPCode returns the code an authenticator would show for secret right now.
+func currentTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	code, err := helpers.TOTPCode(secret, helpers.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatalf("Failed to generate TOTP code: %v", err)
+	}
+	return code
+}
+
+// newMFAUser returns an active login user with a confirmed authenticator, its secret and
+// one recovery code.
+func newMFAUser(t *testing.T) (*models.User, *fakeMFARepository, string, string) {
+	t.Helper()
+
+	user := newLoginUser(t, true)
+	user.ID = uuid.New()
+	user.IsMFAEnabled = true
+
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("Failed to generate TOTP secret: %v", err)
+	}
+	secretEncrypted, err := helpers.EncryptSecret(secret, user.ID[:])
+	if err != nil {
+		t.Fatalf("Failed to encrypt TOTP secret: %v", err)
+	}
+
+	mfa := newFakeMFARepository()
+	_ = mfa.SavePendingTOTP(context.Background(), user.ID, secretEncrypted)
+	_ = mfa.ConfirmTOTP(context.Background(), user.ID, 0, []string{helpers.HashToken("abcde12345")})
+
+	return user, mfa, secret, "ABCDE-12345"
+}
+
+// newMFAAuth returns an Auth service for an MFA user and the challenge token of a password login.
+func newMFAAuth(t *testing.T, user *models.User, mfa *fakeMFARepository) (*Auth, *fakeSessionRepository, string) {
+	t.Helper()
+
+	sessions := newFakeSessionRepository()
+	svc := &Auth{
+		UserRepository:      newFakeUserRepository(user),
+		SessionRepository:   sessions,
+		RoleRepository:      newFakeRoleRepository(),
+		MFARepository:       mfa,
+		UserTokenRepository: &fakeUserTokenRepository{},
+		WebAuthnRepository:  newFakeWebAuthnRepository(),
+	}
+
+	resp, err := svc.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to log in: %v", err)
+	}
+	if !resp.MFARequired || resp.MFA == nil || resp.TokenResponse != nil {
+		t.Fatalf("Expected an MFA challenge without tokens, got %+v", resp)
+	}
+	if len(sessions.sessions) != 0 {
+		t.Fatal("Expected no session before the second factor")
+	}
+	return svc, sessions, resp.MFA.Token
+}
+
+func TestMFA_EnrollTOTP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stores the secret encrypted", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		mfa := newFakeMFARepository()
+		svc := &MFA{UserRepository: newFakeUserRepository(user), MFARepository: mfa}
+
+		// Act
+		enrollment, err := svc.EnrollTOTP(context.Background(), user.ID, &models.EnrollTOTPRequest{Password: "password123"})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !strings.HasPrefix(enrollment.OTPAuthURI, "otpauth://totp/") || !strings.Contains(enrollment.OTPAuthURI, enrollment.Secret) {
+			t.Errorf("Expected otpauth URI carrying the secret, got %s", enrollment.OTPAuthURI)
+		}
+		stored := mfa.totp[user.ID]
+		if stored == nil || stored.ConfirmedAt.Valid {
+			t.Fatalf("Expected a pending TOTP, got %+v", stored)
+		}
+		if strings.Contains(stored.SecretEncrypted, enrollment.Secret) {
+			t.Error("Expected the secret not to be stored in plaintext")
+		}
+		secret, err := helpers.DecryptSecret(stored.SecretEncrypted, user.ID[:])
+		if err != nil || secret != enrollment.Secret {
+			t.Errorf("Expected the stored secret to decrypt to the enrolled one, got %q, %v", secret, err)
+		}
+	})
+
+	t.Run("MFA enabled by a passkey", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		user.IsMFAEnabled = true
+		svc := &MFA{UserRepository: newFakeUserRepository(user), MFARepository: newFakeMFARepository()}
+
+		// Act
+		_, err := svc.EnrollTOTP(context.Background(), user.ID, &models.EnrollTOTPRequest{Password: "password123"})
+
+		// Assert
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		mfa := newFakeMFARepository()
+		users := newFakeUserRepository(user)
+		svc := &MFA{UserRepository: users, MFARepository: mfa}
+
+		// Act
+		_, err := svc.EnrollTOTP(context.Background(), user.ID, &models.EnrollTOTPRequest{Password: "wrong-password"})
+
+		// Assert
+		if !errors.Is(err, ErrIncorrectPassword) {
+			t.Errorf("Expected ErrIncorrectPassword, got %v", err)
+		}
+		if _, ok := mfa.totp[user.ID]; ok {
+			t.Error("Expected no pending TOTP")
+		}
+		if users.users[user.ID].FailedLoginAttempts != 1 {
+			t.Errorf("Expected the wrong password to count towards the lockout, got %d", users.users[user.ID].FailedLoginAttempts)
+		}
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, _, _ := newMFAUser(t)
+		svc := &MFA{UserRepository: newFakeUserRepository(user), MFARepository: mfa}
+
+		// Act
+		_, err := svc.EnrollTOTP(context.Background(), user.ID, &models.EnrollTOTPRequest{Password: "password123"})
+
+		// Assert
+		if !errors.Is(err, ErrMFAAlreadyEnabled) {
+			t.Errorf("Expected ErrMFAAlreadyEnabled, got %v", err)
+		}
+	})
+}
+
+func TestMFA_ConfirmTOTP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("enables MFA and issues recovery codes", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		mfa := newFakeMFARepository()
+		svc := &MFA{UserRepository: newFakeUserRepository(user), MFARepository: mfa}
+		enrollment, err := svc.EnrollTOTP(context.Background(), user.ID, &models.EnrollTOTPRequest{Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to enroll: %v", err)
+		}
+
+		// Act
+		resp, err := svc.ConfirmTOTP(context.Background(), user.ID, &models.ConfirmTOTPRequest{
+			Code:     currentTOTPCode(t, enrollment.Secret),
+			Password: "password123",
+		})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(resp.RecoveryCodes) != constants.MFARecoveryCodeCount {
+			t.Fatalf("Expected %d recovery codes, got %d", constants.MFARecoveryCodeCount, len(resp.RecoveryCodes))
+		}
+		if !mfa.totp[user.ID].ConfirmedAt.Valid {
+			t.Error("Expected the TOTP to be confirmed")
+		}
+		if _, ok := mfa.recoveryCodes[user.ID][helpers.HashToken(normalizeMFACode(resp.RecoveryCodes[0]))]; !ok {
+			t.Error("Expected only recovery code hashes to be stored")
+		}
+	})
+
+	t.Run("wrong code", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		mfa := newFakeMFARepository()
+		svc := &MFA{UserRepository: newFakeUserRepository(user), MFARepository: mfa}
+		enrollment, err := svc.EnrollTOTP(context.Background(), user.ID, &models.EnrollTOTPRequest{Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to enroll: %v", err)
+		}
+		code := currentTOTPCode(t, enrollment.Secret)
+		wrong := code[:5] + string('0'+(code[5]-'0'+1)%10)
+
+		// Act
+		_, err = svc.ConfirmTOTP(context.Background(), user.ID, &models.ConfirmTOTPRequest{Code: wrong, Password: "password123"})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidMFACode) {
+			t.Errorf("Expected ErrInvalidMFACode, got %v", err)
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		mfa := newFakeMFARepository()
+		svc := &MFA{UserRepository: newFakeUserRepository(user), MFARepository: mfa}
+		enrollment, err := svc.EnrollTOTP(context.Background(), user.ID, &models.EnrollTOTPRequest{Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to enroll: %v", err)
+		}
+
+		// Act
+		_, err = svc.ConfirmTOTP(context.Background(), user.ID, &models.ConfirmTOTPRequest{
+			Code:     currentTOTPCode(t, enrollment.Secret),
+			Password: "wrong-password",
+		})
+
+		// Assert
+		if !errors.Is(err, ErrIncorrectPassword) {
+			t.Errorf("Expected ErrIncorrectPassword, got %v", err)
+		}
+		if mfa.totp[user.ID].ConfirmedAt.Valid {
+			t.Error("Expected the TOTP to stay pending")
+		}
+	})
+
+	t.Run("not enrolled", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		svc := &MFA{UserRepository: newFakeUserRepository(user), MFARepository: newFakeMFARepository()}
+
+		// Act
+		_, err := svc.ConfirmTOTP(context.Background(), user.ID, &models.ConfirmTOTPRequest{Code: "123456", Password: "password123"})
+
+		// Assert
+		if !errors.Is(err, ErrMFANotEnrolled) {
+			t.Errorf("Expected ErrMFANotEnrolled, got %v", err)
+		}
+	})
+}
+
+func TestMFA_DisableTOTP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TOTP code removes the authenticator and recovery codes", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, secret, _ := newMFAUser(t)
+		svc := &MFA{UserRepository: newFakeUserRepository(user), MFARepository: mfa}
+
+		// Act
+		err := svc.DisableTOTP(context.Background(), user.ID, &models.MFACodeRequest{Code: currentTOTPCode(t, secret)})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, ok := mfa.totp[user.ID]; ok {
+			t.Error("Expected the TOTP to be deleted")
+		}
+		if len(mfa.recoveryCodes[user.ID]) != 0 {
+			t.Error("Expected the recovery codes to be deleted")
+		}
+	})
+
+	t.Run("recovery code", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, _, recoveryCode := newMFAUser(t)
+		svc := &MFA{UserRepository: newFakeUserRepository(user), MFARepository: mfa}
+
+		// Act
+		err := svc.DisableTOTP(context.Background(), user.ID, &models.MFACodeRequest{Code: recoveryCode})
+
+		// Assert
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("wrong code counts towards the lockout", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, _, _ := newMFAUser(t)
+		users := newFakeUserRepository(user)
+		svc := &MFA{UserRepository: users, MFARepository: mfa}
+
+		// Act
+		err := svc.DisableTOTP(context.Background(), user.ID, &models.MFACodeRequest{Code: "wrong-code"})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidMFACode) {
+			t.Errorf("Expected ErrInvalidMFACode, got %v", err)
+		}
+		if _, ok := mfa.totp[user.ID]; !ok {
+			t.Error("Expected the TOTP to be kept")
+		}
+		if users.users[user.ID].FailedLoginAttempts != 1 {
+			t.Errorf("Expected the wrong code to count towards the lockout, got %d", users.users[user.ID].FailedLoginAttempts)
+		}
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		svc := &MFA{UserRepository: newFakeUserRepository(user), MFARepository: newFakeMFARepository()}
+
+		// Act
+		err := svc.DisableTOTP(context.Background(), user.ID, &models.MFACodeRequest{Code: "123456"})
+
+		// Assert
+		if !errors.Is(err, ErrTOTPNotEnabled) {
+			t.Errorf("Expected ErrTOTPNotEnabled, got %v", err)
+		}
+	})
+}
+
+func TestMFA_RegenerateRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("replaces the recovery codes", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, secret, recoveryCode := newMFAUser(t)
+		svc := &MFA{UserRepository: newFakeUserRepository(user), MFARepository: mfa}
+
+		// Act
+		resp, err := svc.RegenerateRecoveryCodes(context.Background(), user.ID, &models.MFACodeRequest{Code: currentTOTPCode(t, secret)})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(resp.RecoveryCodes) != constants.MFARecoveryCodeCount {
+			t.Fatalf("Expected %d recovery codes, got %d", constants.MFARecoveryCodeCount, len(resp.RecoveryCodes))
+		}
+		if _, ok := mfa.recoveryCodes[user.ID][helpers.HashToken(normalizeMFACode(recoveryCode))]; ok {
+			t.Error("Expected the earlier recovery code to stop working")
+		}
+		if _, ok := mfa.recoveryCodes[user.ID][helpers.HashToken(normalizeMFACode(resp.RecoveryCodes[0]))]; !ok {
+			t.Error("Expected the new recovery codes to be stored")
+		}
+	})
+
+	t.Run("wrong code", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, _, recoveryCode := newMFAUser(t)
+		svc := &MFA{UserRepository: newFakeUserRepository(user), MFARepository: mfa}
+
+		// Act
+		_, err := svc.RegenerateRecoveryCodes(context.Background(), user.ID, &models.MFACodeRequest{Code: "wrong-code"})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidMFACode) {
+			t.Errorf("Expected ErrInvalidMFACode, got %v", err)
+		}
+		if _, ok := mfa.recoveryCodes[user.ID][helpers.HashToken(normalizeMFACode(recoveryCode))]; !ok {
+			t.Error("Expected the recovery codes to be kept")
+		}
+	})
+}
+
+func TestAuth_CompleteMFALogin(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TOTP code issues a session once", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, secret, _ := newMFAUser(t)
+		svc, sessions, token := newMFAAuth(t, user, mfa)
+		code := currentTOTPCode(t, secret)
+
+		// Act
+		tokens, err := svc.CompleteMFALogin(context.Background(), &models.LoginMFARequest{MFAToken: token, Code: code})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := helpers.ParseAccessToken(tokens.AccessToken); err != nil {
+			t.Errorf("Expected valid access token, got %v", err)
+		}
+		if len(sessions.sessions) != 1 {
+			t.Errorf("Expected one session, got %d", len(sessions.sessions))
+		}
+
+		_, err = svc.CompleteMFALogin(context.Background(), &models.LoginMFARequest{MFAToken: token, Code: code})
+		if !errors.Is(err, ErrInvalidMFAToken) {
+			t.Errorf("Expected used challenge to be rejected, got %v", err)
+		}
+		resp, _ := svc.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "password123"})
+		_, err = svc.CompleteMFALogin(context.Background(), &models.LoginMFARequest{MFAToken: resp.MFA.Token, Code: code})
+		if !errors.Is(err, ErrInvalidMFACode) {
+			t.Errorf("Expected replayed code to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("recovery code is single-use", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, _, recoveryCode := newMFAUser(t)
+		svc, _, token := newMFAAuth(t, user, mfa)
+
+		// Act
+		_, err := svc.CompleteMFALogin(context.Background(), &models.LoginMFARequest{MFAToken: token, Code: recoveryCode})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		resp, _ := svc.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "password123"})
+		_, err = svc.CompleteMFALogin(context.Background(), &models.LoginMFARequest{MFAToken: resp.MFA.Token, Code: recoveryCode})
+		if !errors.Is(err, ErrInvalidMFACode) {
+			t.Errorf("Expected used recovery code to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("wrong codes lock the account", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, _, _ := newMFAUser(t)
+		svc, _, token := newMFAAuth(t, user, mfa)
+		for i := 0; i < constants.DefaultLoginMaxFailedAttempts; i++ {
+			_, err := svc.CompleteMFALogin(context.Background(), &models.LoginMFARequest{MFAToken: token, Code: "wrong-code"})
+			if !errors.Is(err, ErrInvalidMFACode) {
+				t.Fatalf("Expected ErrInvalidMFACode on attempt %d, got %v", i+1, err)
+			}
+		}
+
+		// Act
+		_, err := svc.CompleteMFALogin(context.Background(), &models.LoginMFARequest{MFAToken: token, Code: "wrong-code"})
+
+		// Assert
+		if !errors.Is(err, ErrAccountTemporarilyLocked) {
+			t.Errorf("Expected ErrAccountTemporarilyLocked, got %v", err)
+		}
+	})
+
+	t.Run("unknown challenge", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, secret, _ := newMFAUser(t)
+		svc, _, _ := newMFAAuth(t, user, mfa)
+
+		// Act
+		_, err := svc.CompleteMFALogin(context.Background(), &models.LoginMFARequest{MFAToken: "unknown", Code: currentTOTPCode(t, secret)})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidMFAToken) {
+			t.Errorf("Expected ErrInvalidMFAToken, got %v", err)
+		}
+	})
+}
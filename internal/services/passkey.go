@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// Passkey service implementation.
+type Passkey struct {
+	UserRepository     interfaces.IUserRepository
+	MFARepository      interfaces.IMFARepository
+	WebAuthnRepository interfaces.IWebAuthnRepository
+	WebAuthn           *webauthn.WebAuthn
+}
+
+// BeginRegistration checks the current password, or a TOTP or recovery code, and returns the
+// options for navigator.credentials.create(). Only this challenge can complete a registration,
+// so a stolen access token alone cannot add a passkey. Authenticators are asked for a
+// discoverable credential so it can be used for passwordless login, and credentials the user
+// already registered are excluded.
+func (s *Passkey) BeginRegistration(
+	ctx context.Context, userID uuid.UUID, req *models.PasskeyOptionsRequest,
+) (*protocol.CredentialCreation, error) {
+	if err := helpers.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, mapUserNotFound(err)
+	}
+
+	if req.Password != "" {
+		err = verifyCurrentPassword(ctx, s.UserRepository, user, req.Password)
+	} else {
+		err = verifyCurrentMFACode(ctx, s.UserRepository, s.MFARepository, user, req.Code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	waUser, err := loadWebAuthnUser(ctx, s.WebAuthnRepository, user)
+	if err != nil {
+		return nil, err
+	}
+
+	creation, session, err := s.WebAuthn.BeginRegistration(waUser,
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+		webauthn.WithExclusions(webauthn.Credentials(waUser.credentials).CredentialDescriptors()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := uuid.NullUUID{UUID: user.ID, Valid: true}
+	if err := saveWebAuthnSession(ctx, s.WebAuthnRepository, constants.WebAuthnPurposeRegistration, owner, session); err != nil {
+		return nil, err
+	}
+	return creation, nil
+}
+
+// FinishRegistration verifies the authenticator's response to a registration challenge and
+// stores the new credential. Registering a passkey enables MFA for the user, so password
+// logins ask for a second factor from then on.
+func (s *Passkey) FinishRegistration(
+	ctx context.Context, userID uuid.UUID, req *models.RegisterPasskeyRequest,
+) (*models.UserCredential, error) {
+	req.Name = strings.TrimSpace(req.Name)
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(req.Credential)
+	if err != nil {
+		return nil, invalidPasskey(err)
+	}
+
+	challenge := parsed.Response.CollectedClientData.Challenge
+	session, err := consumeWebAuthnSession(ctx, s.WebAuthnRepository, constants.WebAuthnPurposeRegistration, challenge)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(session.UserID, userID[:]) {
+		return nil, ErrInvalidPasskey
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, mapUserNotFound(err)
+	}
+
+	waUser, err := loadWebAuthnUser(ctx, s.WebAuthnRepository, user)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := s.WebAuthn.CreateCredential(waUser, *session, parsed)
+	if err != nil {
+		return nil, invalidPasskey(err)
+	}
+
+	name := req.Name
+	if name == "" {
+		name = constants.DefaultPasskeyName
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	stored := &models.UserCredential{
+		ID:              uuid.New(),
+		UserID:          user.ID,
+		Name:            name,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		Transports:      transports,
+		SignCount:       int64(credential.Authenticator.SignCount),
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+	if err := s.WebAuthnRepository.CreateCredential(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	helpers.LogSecurityEvent(constants.SecurityEventPasskeyRegistered, logrus.Fields{
+		"user_id":       user.ID,
+		"credential_id": stored.ID,
+	})
+
+	return stored, nil
+}
+
+// ListPasskeys returns the passkeys of a user, oldest first.
+func (s *Passkey) ListPasskeys(ctx context.Context, userID uuid.UUID) ([]models.UserCredential, error) {
+	return s.WebAuthnRepository.ListCredentialsByUserID(ctx, userID)
+}
+
+// DeletePasskey deletes a passkey of a user. Deleting the last second factor disables MFA.
+func (s *Passkey) DeletePasskey(ctx context.Context, userID, id uuid.UUID) error {
+	if err := s.WebAuthnRepository.DeleteCredential(ctx, userID, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrPasskeyNotFound
+		}
+		return err
+	}
+
+	helpers.LogSecurityEvent(constants.SecurityEventPasskeyDeleted, logrus.Fields{
+		"user_id":       userID,
+		"credential_id": id,
+	})
+	return nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// BeginPasskeyLogin returns the options for a passwordless navigator.credentials.get(). No
+// user is named, so the authenticator offers its discoverable credentials for this site and
+// must verify the user with a PIN or biometric.
+func (s *Auth) BeginPasskeyLogin(ctx context.Context) (*protocol.CredentialAssertion, error) {
+	assertion, session, err := s.WebAuthn.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := saveWebAuthnSession(ctx, s.WebAuthnRepository, constants.WebAuthnPurposeLogin, uuid.NullUUID{}, session); err != nil {
+		return nil, err
+	}
+	return assertion, nil
+}
+
+// FinishPasskeyLogin verifies the response to a passwordless login challenge and issues a
+// new session for the user the passkey belongs to. The passkey replaces both factors, so
+// neither MFA nor the step-up verification of new devices is asked for.
+func (s *Auth) FinishPasskeyLogin(ctx context.Context, req *models.PasskeyLoginRequest) (*models.TokenResponse, error) {
+	if err := helpers.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	if blocked, retryAfter := s.loginIPBlocked(req.IPAddress); blocked {
+		return nil, &domain.RetryAfterError{Err: ErrTooManyLoginAttempts, RetryAfter: retryAfter}
+	}
+
+	user, credential, err := s.validatePasskeyLogin(ctx, req.Credential)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPasskey) {
+			s.recordIPFailure(req.IPAddress)
+		}
+		return nil, err
+	}
+
+	if err := checkLockout(user); err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	if err := recordPasskeyUse(ctx, s.WebAuthnRepository, user.ID, credential); err != nil {
+		return nil, err
+	}
+
+	if err := s.clearLoginFailures(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return s.createSession(ctx, user, req.IPAddress, req.UserAgent, &req.DeviceInfo)
+}
+
+// validatePasskeyLogin verifies a passwordless assertion and returns the user named by its
+// user handle.
+func (s *Auth) validatePasskeyLogin(ctx context.Context, response json.RawMessage) (*models.User, *webauthn.Credential, error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, nil, invalidPasskey(err)
+	}
+
+	challenge := parsed.Response.CollectedClientData.Challenge
+	session, err := consumeWebAuthnSession(ctx, s.WebAuthnRepository, constants.WebAuthnPurposeLogin, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var user *models.User
+	credential, err := s.WebAuthn.ValidateDiscoverableLogin(func(_, userHandle []byte) (webauthn.User, error) {
+		userID, err := uuid.FromBytes(userHandle)
+		if err != nil {
+			return nil, err
+		}
+
+		found, err := s.UserRepository.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		user = found
+
+		return loadWebAuthnUser(ctx, s.WebAuthnRepository, found)
+	}, *session, parsed)
+	if err != nil {
+		return nil, nil, invalidPasskey(err)
+	}
+
+	return user, credential, nil
+}
+
+// BeginMFAPasskey returns the options for navigator.credentials.get() to complete a pending
+// MFA login with one of the user's passkeys. The response is sent to CompleteMFALogin.
+func (s *Auth) BeginMFAPasskey(ctx context.Context, req *models.MFAPasskeyOptionsRequest) (*protocol.CredentialAssertion, error) {
+	if err := helpers.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.UserTokenRepository.GetActive(ctx, constants.TokenPurposeMFALogin, helpers.HashToken(req.MFAToken))
+	if err != nil {
+		return nil, mapMFATokenNotFound(err)
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, mapMFATokenNotFound(err)
+	}
+
+	waUser, err := loadWebAuthnUser(ctx, s.WebAuthnRepository, user)
+	if err != nil {
+		return nil, err
+	}
+	if len(waUser.credentials) == 0 {
+		return nil, ErrInvalidPasskey
+	}
+
+	assertion, session, err := s.WebAuthn.BeginLogin(waUser)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := uuid.NullUUID{UUID: user.ID, Valid: true}
+	if err := saveWebAuthnSession(ctx, s.WebAuthnRepository, constants.WebAuthnPurposeMFA, owner, session); err != nil {
+		return nil, err
+	}
+	return assertion, nil
+}
+
+// verifyMFAPasskey verifies a passkey assertion answering a challenge from BeginMFAPasskey.
+func (s *Auth) verifyMFAPasskey(ctx context.Context, user *models.User, response json.RawMessage) error {
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return invalidPasskey(err)
+	}
+
+	challenge := parsed.Response.CollectedClientData.Challenge
+	session, err := consumeWebAuthnSession(ctx, s.WebAuthnRepository, constants.WebAuthnPurposeMFA, challenge)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(session.UserID, user.ID[:]) {
+		return ErrInvalidPasskey
+	}
+
+	waUser, err := loadWebAuthnUser(ctx, s.WebAuthnRepository, user)
+	if err != nil {
+		return err
+	}
+
+	credential, err := s.WebAuthn.ValidateLogin(waUser, *session, parsed)
+	if err != nil {
+		return invalidPasskey(err)
+	}
+
+	return recordPasskeyUse(ctx, s.WebAuthnRepository, user.ID, credential)
+}
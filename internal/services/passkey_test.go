@@ -0,0 +1,730 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+const testWebAuthnOrigin = "https://wallet.example.com"
+
+// fakeWebAuthnRepository is an in-memory IWebAuthnRepository for service tests.
+type fakeWebAuthnRepository struct {
+	sessions    map[string]*models.WebAuthnSession
+	credentials []*models.UserCredential
+	mu          sync.Mutex
+}
+
+func newFakeWebAuthnRepository() *fakeWebAuthnRepository {
+	return &fakeWebAuthnRepository{sessions: make(map[string]*models.WebAuthnSession)}
+}
+
+func (f *fakeWebAuthnRepository) CreateCredential(_ context.Context, credential *models.UserCredential) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	credential.CreatedAt = time.Now()
+	stored := *credential
+	f.credentials = append(f.credentials, &stored)
+	return nil
+}
+
+func (f *fakeWebAuthnRepository) ListCredentialsByUserID(_ context.Context, userID uuid.UUID) ([]models.UserCredential, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var credentials []models.UserCredential
+	for _, c := range f.credentials {
+		if c.UserID == userID {
+			credentials = append(credentials, *c)
+		}
+	}
+	return credentials, nil
+}
+
+func (f *fakeWebAuthnRepository) UpdateCredentialUsage(_ context.Context, credentialID []byte, signCount uint32, backupState bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, c := range f.credentials {
+		if string(c.CredentialID) == string(credentialID) {
+			now := time.Now()
+			c.SignCount = int64(signCount)
+			c.BackupState = backupState
+			c.LastUsedAt = &now
+			return nil
+		}
+	}
+	return domain.NewError(domain.ErrNotFound, "credential not found")
+}
+
+func (f *fakeWebAuthnRepository) DeleteCredential(_ context.Context, userID, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, c := range f.credentials {
+		if c.ID == id && c.UserID == userID {
+			f.credentials = append(f.credentials[:i], f.credentials[i+1:]...)
+			return nil
+		}
+	}
+	return domain.NewError(domain.ErrNotFound, "credential not found")
+}
+
+func (f *fakeWebAuthnRepository) DeleteCredentialsByUserID(_ context.Context, userID uuid.UUID) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	kept := f.credentials[:0]
+	for _, c := range f.credentials {
+		if c.UserID != userID {
+			kept = append(kept, c)
+		}
+	}
+	deleted := int64(len(f.credentials) - len(kept))
+	f.credentials = kept
+	return deleted, nil
+}
+
+func (f *fakeWebAuthnRepository) CreateSession(_ context.Context, session *models.WebAuthnSession) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored := *session
+	f.sessions[session.Challenge] = &stored
+	return nil
+}
+
+func (f *fakeWebAuthnRepository) ConsumeSession(_ context.Context, purpose, challenge string) (*models.WebAuthnSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session, ok := f.sessions[challenge]
+	if !ok || session.Purpose != purpose || time.Now().After(session.ExpiresAt) {
+		return nil, domain.NewError(domain.ErrNotFound, "WebAuthn session not found")
+	}
+	delete(f.sessions, challenge)
+	return session, nil
+}
+
+// softwareAuthenticator is a platform authenticator holding one ES256 passkey in memory,
+// producing the responses a browser returns from navigator.credentials.
+type softwareAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftwareAuthenticator(t *testing.T) *softwareAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate authenticator key: %v", err)
+	}
+	credentialID := make([]byte, 16)
+	_, _ = rand.Read(credentialID)
+
+	return &softwareAuthenticator{key: key, credentialID: credentialID}
+}
+
+// authenticatorData builds the authenticator data for rpID, appending the attested
+// credential when attested is set.
+func (a *softwareAuthenticator) authenticatorData(t *testing.T, attested bool) []byte {
+	t.Helper()
+
+	const flagsUserPresentVerified = 0x05
+	const flagAttestedCredentialData = 0x40
+
+	rpIDHash := sha256.Sum256([]byte("wallet.example.com"))
+	data := append([]byte{}, rpIDHash[:]...)
+	if attested {
+		data = append(data, flagsUserPresentVerified|flagAttestedCredentialData)
+	} else {
+		data = append(data, flagsUserPresentVerified)
+	}
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if !attested {
+		return data
+	}
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode public key: %v", err)
+	}
+
+	data = append(data, make([]byte, 16)...) // AAGUID
+	data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+	data = append(data, a.credentialID...)
+	return append(data, publicKey...)
+}
+
+func clientDataJSON(t *testing.T, ceremony, challenge string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]string{"type": ceremony, "challenge": challenge, "origin": testWebAuthnOrigin})
+	if err != nil {
+		t.Fatalf("Failed to encode client data: %v", err)
+	}
+	return data
+}
+
+// create answers navigator.credentials.create() with a "none" attestation.
+func (a *softwareAuthenticator) create(t *testing.T, creation *protocol.CredentialCreation) json.RawMessage {
+	t.Helper()
+
+	a.userHandle = creation.Response.User.ID.(protocol.URLEncodedBase64)
+	attestation, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": a.authenticatorData(t, true),
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode attestation: %v", err)
+	}
+
+	return a.credential(t, map[string]string{
+		"clientDataJSON":    encodeB64(clientDataJSON(t, "webauthn.create", creation.Response.Challenge.String())),
+		"attestationObject": encodeB64(attestation),
+	})
+}
+
+// get answers navigator.credentials.get() with a signed assertion.
+func (a *softwareAuthenticator) get(t *testing.T, assertion *protocol.CredentialAssertion) json.RawMessage {
+	t.Helper()
+
+	a.signCount++
+	authData := a.authenticatorData(t, false)
+	clientData := clientDataJSON(t, "webauthn.get", assertion.Response.Challenge.String())
+	clientDataHash := sha256.Sum256(clientData)
+
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign assertion: %v", err)
+	}
+
+	return a.credential(t, map[string]string{
+		"clientDataJSON":    encodeB64(clientData),
+		"authenticatorData": encodeB64(authData),
+		"signature":         encodeB64(signature),
+		"userHandle":        encodeB64(a.userHandle),
+	})
+}
+
+func (a *softwareAuthenticator) credential(t *testing.T, response map[string]string) json.RawMessage {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{
+		"id":       encodeB64(a.credentialID),
+		"rawId":    encodeB64(a.credentialID),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode credential: %v", err)
+	}
+	return data
+}
+
+func encodeB64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func newTestWebAuthn(t *testing.T) *webauthn.WebAuthn {
+	t.Helper()
+
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: time.Minute, TimeoutUVD: time.Minute}
+	relyingParty, err := webauthn.New(&webauthn.Config{
+		RPID:          "wallet.example.com",
+		RPDisplayName: "E-Wallet",
+		RPOrigins:     []string{testWebAuthnOrigin},
+		Timeouts:      webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+	if err != nil {
+		t.Fatalf("Failed to configure WebAuthn: %v", err)
+	}
+	return relyingParty
+}
+
+// registerPasskey registers a new software authenticator for the user.
+func registerPasskey(t *testing.T, svc *Passkey, userID uuid.UUID) *softwareAuthenticator {
+	t.Helper()
+
+	creation, err := svc.BeginRegistration(context.Background(), userID, &models.PasskeyOptionsRequest{Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to begin registration: %v", err)
+	}
+
+	authenticator := newSoftwareAuthenticator(t)
+	req := &models.RegisterPasskeyRequest{Name: "Test phone", Credential: authenticator.create(t, creation)}
+	if _, err := svc.FinishRegistration(context.Background(), userID, req); err != nil {
+		t.Fatalf("Failed to finish registration: %v", err)
+	}
+	return authenticator
+}
+
+// newPasskeyAuth returns Auth and Passkey services sharing the repositories of an active user.
+func newPasskeyAuth(t *testing.T, user *models.User) (*Auth, *Passkey, *fakeWebAuthnRepository, *fakeSessionRepository) {
+	t.Helper()
+
+	users := newFakeUserRepository(user)
+	webAuthnRepo := newFakeWebAuthnRepository()
+	sessions := newFakeSessionRepository()
+	relyingParty := newTestWebAuthn(t)
+
+	auth := &Auth{
+		UserRepository:      users,
+		SessionRepository:   sessions,
+		RoleRepository:      newFakeRoleRepository(),
+		MFARepository:       newFakeMFARepository(),
+		UserTokenRepository: &fakeUserTokenRepository{},
+		WebAuthnRepository:  webAuthnRepo,
+		WebAuthn:            relyingParty,
+	}
+	passkey := &Passkey{
+		UserRepository:     users,
+		MFARepository:      auth.MFARepository,
+		WebAuthnRepository: webAuthnRepo,
+		WebAuthn:           relyingParty,
+	}
+	return auth, passkey, webAuthnRepo, sessions
+}
+
+func TestPasskey_BeginRegistration(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TOTP code instead of the password", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, secret, _ := newMFAUser(t)
+		_, svc, repo, _ := newPasskeyAuth(t, user)
+		svc.MFARepository = mfa
+
+		// Act
+		_, err := svc.BeginRegistration(context.Background(), user.ID, &models.PasskeyOptionsRequest{Code: currentTOTPCode(t, secret)})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(repo.sessions) != 1 {
+			t.Error("Expected a registration challenge")
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		_, svc, repo, _ := newPasskeyAuth(t, user)
+
+		// Act
+		_, err := svc.BeginRegistration(context.Background(), user.ID, &models.PasskeyOptionsRequest{Password: "wrong-password"})
+
+		// Assert
+		if !errors.Is(err, ErrIncorrectPassword) {
+			t.Errorf("Expected ErrIncorrectPassword, got %v", err)
+		}
+		if len(repo.sessions) != 0 {
+			t.Error("Expected no registration challenge")
+		}
+	})
+
+	t.Run("wrong code counts towards the lockout", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, _, _ := newMFAUser(t)
+		_, svc, repo, _ := newPasskeyAuth(t, user)
+		svc.MFARepository = mfa
+
+		// Act
+		_, err := svc.BeginRegistration(context.Background(), user.ID, &models.PasskeyOptionsRequest{Code: "wrong-code"})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidMFACode) {
+			t.Errorf("Expected ErrInvalidMFACode, got %v", err)
+		}
+		if len(repo.sessions) != 0 {
+			t.Error("Expected no registration challenge")
+		}
+		stored, _ := svc.UserRepository.GetByID(context.Background(), user.ID)
+		if stored.FailedLoginAttempts != 1 {
+			t.Errorf("Expected the wrong code to count towards the lockout, got %d", stored.FailedLoginAttempts)
+		}
+	})
+
+	t.Run("neither password nor code", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		_, svc, _, _ := newPasskeyAuth(t, user)
+
+		// Act
+		_, err := svc.BeginRegistration(context.Background(), user.ID, &models.PasskeyOptionsRequest{})
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("Expected a validation error, got %v", err)
+		}
+	})
+}
+
+func TestPasskey_FinishRegistration(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stores the credential", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		_, svc, repo, _ := newPasskeyAuth(t, user)
+		creation, err := svc.BeginRegistration(context.Background(), user.ID, &models.PasskeyOptionsRequest{Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to begin registration: %v", err)
+		}
+		authenticator := newSoftwareAuthenticator(t)
+
+		// Act
+		credential, err := svc.FinishRegistration(context.Background(), user.ID, &models.RegisterPasskeyRequest{
+			Credential: authenticator.create(t, creation),
+		})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if credential.Name != constants.DefaultPasskeyName {
+			t.Errorf("Expected default name %q, got %q", constants.DefaultPasskeyName, credential.Name)
+		}
+		if len(repo.credentials) != 1 || string(repo.credentials[0].CredentialID) != string(authenticator.credentialID) {
+			t.Fatalf("Expected the credential to be stored, got %+v", repo.credentials)
+		}
+		if len(repo.sessions) != 0 {
+			t.Error("Expected the registration challenge to be consumed")
+		}
+	})
+
+	t.Run("challenge of another user", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		_, svc, _, _ := newPasskeyAuth(t, user)
+		creation, err := svc.BeginRegistration(context.Background(), user.ID, &models.PasskeyOptionsRequest{Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to begin registration: %v", err)
+		}
+		authenticator := newSoftwareAuthenticator(t)
+
+		// Act
+		_, err = svc.FinishRegistration(context.Background(), uuid.New(), &models.RegisterPasskeyRequest{
+			Credential: authenticator.create(t, creation),
+		})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidPasskey) {
+			t.Errorf("Expected ErrInvalidPasskey, got %v", err)
+		}
+	})
+
+	t.Run("unknown challenge", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		_, svc, _, _ := newPasskeyAuth(t, user)
+		creation := &protocol.CredentialCreation{}
+		creation.Response.User.ID = protocol.URLEncodedBase64(user.ID[:])
+		creation.Response.Challenge = protocol.URLEncodedBase64("not-issued")
+
+		// Act
+		_, err := svc.FinishRegistration(context.Background(), user.ID, &models.RegisterPasskeyRequest{
+			Credential: newSoftwareAuthenticator(t).create(t, creation),
+		})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidPasskey) {
+			t.Errorf("Expected ErrInvalidPasskey, got %v", err)
+		}
+	})
+
+	t.Run("malformed credential", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		_, svc, _, _ := newPasskeyAuth(t, user)
+
+		// Act
+		_, err := svc.FinishRegistration(context.Background(), user.ID, &models.RegisterPasskeyRequest{
+			Credential: json.RawMessage(`{"id":"not-a-credential"}`),
+		})
+
+		// Assert
+		// The parser's details are only logged, never returned to clients
+		if err == nil || err.Error() != ErrInvalidPasskey.Error() {
+			t.Errorf("Expected only ErrInvalidPasskey, got %v", err)
+		}
+	})
+}
+
+func TestPasskey_DeletePasskey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deletes a passkey of the user", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		_, svc, repo, _ := newPasskeyAuth(t, user)
+		registerPasskey(t, svc, user.ID)
+		passkeys, err := svc.ListPasskeys(context.Background(), user.ID)
+		if err != nil || len(passkeys) != 1 {
+			t.Fatalf("Expected one passkey, got %d, %v", len(passkeys), err)
+		}
+
+		// Act
+		err = svc.DeletePasskey(context.Background(), user.ID, passkeys[0].ID)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(repo.credentials) != 0 {
+			t.Error("Expected the passkey to be deleted")
+		}
+	})
+
+	t.Run("passkey of another user", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		_, svc, repo, _ := newPasskeyAuth(t, user)
+		registerPasskey(t, svc, user.ID)
+
+		// Act
+		err := svc.DeletePasskey(context.Background(), uuid.New(), repo.credentials[0].ID)
+
+		// Assert
+		if !errors.Is(err, ErrPasskeyNotFound) {
+			t.Errorf("Expected ErrPasskeyNotFound, got %v", err)
+		}
+		if len(repo.credentials) != 1 {
+			t.Error("Expected the passkey to be kept")
+		}
+	})
+}
+
+func TestAuth_FinishPasskeyLogin(t *testing.T) {
+	t.Parallel()
+
+	t.Run("issues a session", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		auth, passkey, repo, sessions := newPasskeyAuth(t, user)
+		authenticator := registerPasskey(t, passkey, user.ID)
+		assertion, err := auth.BeginPasskeyLogin(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to begin login: %v", err)
+		}
+		response := authenticator.get(t, assertion)
+
+		// Act
+		tokens, err := auth.FinishPasskeyLogin(context.Background(), &models.PasskeyLoginRequest{Credential: response, IPAddress: "10.0.0.1"})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if tokens.AccessToken == "" || len(sessions.sessions) != 1 {
+			t.Fatalf("Expected one session with tokens, got %d sessions", len(sessions.sessions))
+		}
+		if repo.credentials[0].SignCount != 1 || repo.credentials[0].LastUsedAt == nil {
+			t.Errorf("Expected the credential usage to be recorded, got %+v", repo.credentials[0])
+		}
+
+		_, err = auth.FinishPasskeyLogin(context.Background(), &models.PasskeyLoginRequest{Credential: response})
+		if !errors.Is(err, ErrInvalidPasskey) {
+			t.Errorf("Expected replayed assertion to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("cloned authenticator", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		auth, passkey, _, sessions := newPasskeyAuth(t, user)
+		authenticator := registerPasskey(t, passkey, user.ID)
+		for range 2 {
+			assertion, _ := auth.BeginPasskeyLogin(context.Background())
+			req := &models.PasskeyLoginRequest{Credential: authenticator.get(t, assertion)}
+			if _, err := auth.FinishPasskeyLogin(context.Background(), req); err != nil {
+				t.Fatalf("Failed to log in: %v", err)
+			}
+		}
+		authenticator.signCount = 0
+		assertion, _ := auth.BeginPasskeyLogin(context.Background())
+
+		// Act
+		_, err := auth.FinishPasskeyLogin(context.Background(), &models.PasskeyLoginRequest{Credential: authenticator.get(t, assertion)})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidPasskey) {
+			t.Errorf("Expected ErrInvalidPasskey, got %v", err)
+		}
+		if len(sessions.sessions) != 2 {
+			t.Errorf("Expected no session for the cloned authenticator, got %d sessions", len(sessions.sessions))
+		}
+	})
+
+	t.Run("unregistered passkey", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		auth, _, _, _ := newPasskeyAuth(t, user)
+		authenticator := newSoftwareAuthenticator(t)
+		authenticator.userHandle = user.ID[:]
+		assertion, _ := auth.BeginPasskeyLogin(context.Background())
+
+		// Act
+		_, err := auth.FinishPasskeyLogin(context.Background(), &models.PasskeyLoginRequest{Credential: authenticator.get(t, assertion)})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidPasskey) {
+			t.Errorf("Expected ErrInvalidPasskey, got %v", err)
+		}
+	})
+
+	t.Run("inactive user", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		auth, passkey, _, _ := newPasskeyAuth(t, user)
+		authenticator := registerPasskey(t, passkey, user.ID)
+		user.IsActive = false
+		assertion, _ := auth.BeginPasskeyLogin(context.Background())
+
+		// Act
+		_, err := auth.FinishPasskeyLogin(context.Background(), &models.PasskeyLoginRequest{Credential: authenticator.get(t, assertion)})
+
+		// Assert
+		if !errors.Is(err, ErrUserInactive) {
+			t.Errorf("Expected ErrUserInactive, got %v", err)
+		}
+	})
+}
+
+func TestAuth_CompleteMFALogin_Passkey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("passkey completes the login", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		auth, passkey, _, sessions := newPasskeyAuth(t, user)
+		authenticator := registerPasskey(t, passkey, user.ID)
+		user.IsMFAEnabled = true
+		resp, err := auth.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "password123"})
+		if err != nil || !resp.MFARequired {
+			t.Fatalf("Expected an MFA challenge, got %+v, %v", resp, err)
+		}
+		if len(resp.MFA.Methods) != 1 || resp.MFA.Methods[0] != constants.MFAMethodPasskey {
+			t.Errorf("Expected only the passkey method, got %v", resp.MFA.Methods)
+		}
+		assertion, err := auth.BeginMFAPasskey(context.Background(), &models.MFAPasskeyOptionsRequest{MFAToken: resp.MFA.Token})
+		if err != nil {
+			t.Fatalf("Failed to begin passkey verification: %v", err)
+		}
+
+		// Act
+		tokens, err := auth.CompleteMFALogin(context.Background(), &models.LoginMFARequest{
+			MFAToken: resp.MFA.Token,
+			Passkey:  authenticator.get(t, assertion),
+		})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if tokens.AccessToken == "" || len(sessions.sessions) != 1 {
+			t.Errorf("Expected one session with tokens, got %d sessions", len(sessions.sessions))
+		}
+	})
+
+	t.Run("passwordless challenge is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newLoginUser(t, true)
+		auth, passkey, _, _ := newPasskeyAuth(t, user)
+		authenticator := registerPasskey(t, passkey, user.ID)
+		user.IsMFAEnabled = true
+		resp, err := auth.Login(context.Background(), &models.LoginRequest{Email: "john@example.com", Password: "password123"})
+		if err != nil {
+			t.Fatalf("Failed to log in: %v", err)
+		}
+		assertion, _ := auth.BeginPasskeyLogin(context.Background())
+
+		// Act
+		_, err = auth.CompleteMFALogin(context.Background(), &models.LoginMFARequest{
+			MFAToken: resp.MFA.Token,
+			Passkey:  authenticator.get(t, assertion),
+		})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidPasskey) {
+			t.Errorf("Expected ErrInvalidPasskey, got %v", err)
+		}
+	})
+
+	t.Run("no passkey registered", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user, mfa, _, _ := newMFAUser(t)
+		svc, _, token := newMFAAuth(t, user, mfa)
+
+		// Act
+		_, err := svc.BeginMFAPasskey(context.Background(), &models.MFAPasskeyOptionsRequest{MFAToken: token})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidPasskey) {
+			t.Errorf("Expected ErrInvalidPasskey, got %v", err)
+		}
+	})
+}
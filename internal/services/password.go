@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// Password service implementation.
+type Password struct {
+	UserRepository      interfaces.IUserRepository
+	SessionRepository   interfaces.ISessionRepository
+	UserTokenRepository interfaces.IUserTokenRepository
+	WebAuthnRepository  interfaces.IWebAuthnRepository
+	Notifier            interfaces.INotifier
+}
+
+// ForgotPassword emails a password reset token, invalidating earlier ones. It reports
+// success for unknown emails, during the cooldown and when delivery fails, so it
+// cannot be used to discover registered addresses.
+func (s *Password) ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error {
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	user, err := s.UserRepository.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	latest, err := s.UserTokenRepository.GetLatestByUserID(ctx, user.ID, constants.TokenPurposePasswordReset)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	cooldown := helpers.GetEnvDuration("PASSWORD_RESET_COOLDOWN", constants.DefaultPasswordResetCooldown)
+	if latest != nil && time.Since(latest.CreatedAt) < cooldown {
+		return nil
+	}
+
+	if err := s.UserTokenRepository.InvalidateByUserID(ctx, user.ID, constants.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	ttl := helpers.GetEnvDuration("PASSWORD_RESET_TTL", constants.DefaultPasswordResetTTL)
+	token, err := issueUserToken(ctx, s.UserTokenRepository, user.ID, constants.TokenPurposePasswordReset, ttl)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Notifier.SendPasswordReset(ctx, user, token); err != nil {
+		helpers.Logger.Errorf("Failed to send password reset email to user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword consumes a password reset token, sets a new password that passes the
+// password policy and revokes every session and passkey of the user, so neither outlives
+// the reset. The reset also lifts any login lockout, including a hard lock.
+func (s *Password) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error {
+	if err := helpers.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	tokenHash := helpers.HashToken(req.Token)
+	token, err := s.UserTokenRepository.GetActive(ctx, constants.TokenPurposePasswordReset, tokenHash)
+	if err != nil {
+		return mapResetTokenNotFound(err)
+	}
+
+	// Check the policy before consuming the token, so a rejected password can be retried
+	user, err := s.UserRepository.GetByID(ctx, token.UserID)
+	if err != nil {
+		return mapResetTokenNotFound(err)
+	}
+	if err := helpers.ValidatePassword("password", req.Password, user.Email, user.FullName); err != nil {
+		return err
+	}
+
+	if _, err := s.UserTokenRepository.Consume(ctx, constants.TokenPurposePasswordReset, tokenHash); err != nil {
+		return mapResetTokenNotFound(err)
+	}
+
+	passwordHash, err := helpers.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.UserRepository.UpdatePassword(ctx, token.UserID, passwordHash); err != nil {
+		return mapResetTokenNotFound(err)
+	}
+
+	if user.HardLockedAt != nil || user.LockedUntil != nil || user.FailedLoginAttempts > 0 {
+		if err := s.UserRepository.Unlock(ctx, user.ID); err != nil {
+			return err
+		}
+		helpers.LogSecurityEvent(constants.SecurityEventAccountUnlocked, logrus.Fields{
+			"user_id": user.ID,
+			"reason":  "password_reset",
+		})
+	}
+
+	if err := s.UserTokenRepository.InvalidateByUserID(ctx, token.UserID, constants.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	if err := s.SessionRepository.RevokeAllByUserID(ctx, token.UserID); err != nil {
+		return err
+	}
+
+	// Passkeys log in without the password, so one added by whoever took over the account
+	// must not outlive the reset
+	deleted, err := s.WebAuthnRepository.DeleteCredentialsByUserID(ctx, token.UserID)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		helpers.LogSecurityEvent(constants.SecurityEventPasskeyDeleted, logrus.Fields{
+			"user_id": token.UserID,
+			"count":   deleted,
+			"reason":  "password_reset",
+		})
+	}
+
+	helpers.Logger.Infof("Password of user %s reset, all sessions revoked", token.UserID)
+	return nil
+}
+
+// ChangePassword sets a new password after checking the current one. A wrong current password
+// counts towards the login lockout, so a stolen session cannot be used to guess it. Every other
+// session of the user is revoked, and the current session too when RevokeAllSessions is set.
+func (s *Password) ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, req *models.ChangePasswordRequest) error {
+	if err := helpers.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return mapUserNotFound(err)
+	}
+
+	if err := checkLockout(user); err != nil {
+		return err
+	}
+
+	if !helpers.CheckPassword(user.PasswordHash, req.CurrentPassword) {
+		if err := recordLoginFailure(ctx, s.UserRepository, user, ""); err != nil {
+			return err
+		}
+		return ErrIncorrectPassword
+	}
+
+	if err := helpers.ValidatePassword("new_password", req.NewPassword, user.Email, user.FullName); err != nil {
+		return err
+	}
+
+	passwordHash, err := helpers.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.UserRepository.UpdatePassword(ctx, userID, passwordHash); err != nil {
+		return mapUserNotFound(err)
+	}
+
+	// A pending reset link must not undo the change
+	if err := s.UserTokenRepository.InvalidateByUserID(ctx, userID, constants.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	if req.RevokeAllSessions {
+		err = s.SessionRepository.RevokeAllByUserID(ctx, userID)
+	} else {
+		err = s.SessionRepository.RevokeOthersByUserID(ctx, userID, sessionID)
+	}
+	if err != nil {
+		return err
+	}
+
+	helpers.Logger.Infof("Password of user %s changed", userID)
+	return nil
+}
+
+// validateWithPassword validates the request and checks the new password against the
+// password policy, reporting both kinds of violations in a single ValidationError.
+func validateWithPassword(req interface{}, field, password string, personal ...string) error {
+	err := helpers.ValidateStruct(req)
+	var validationErr *helpers.ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		return err
+	}
+
+	// An empty password is already reported as required
+	if password != "" {
+		err := helpers.ValidatePassword(field, password, personal...)
+		var policyErr *helpers.ValidationError
+		if err != nil && !errors.As(err, &policyErr) {
+			return err
+		}
+		if policyErr != nil {
+			if validationErr == nil {
+				return policyErr
+			}
+			validationErr.Fields = append(validationErr.Fields, policyErr.Fields...)
+		}
+	}
+
+	if validationErr != nil {
+		return validationErr
+	}
+	return nil
+}
+
+func mapResetTokenNotFound(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrInvalidResetToken
+	}
+	return err
+}
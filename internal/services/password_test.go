@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+func newTestPasswordService(user *models.User) (*Password, *fakeUserRepository, *fakeSessionRepository, *fakeNotifier) {
+	users := newFakeUserRepository(user)
+	sessions := newActiveSessionRepository(user.ID)
+	notifier := newFakeNotifier()
+	return &Password{
+		UserRepository:      users,
+		SessionRepository:   sessions,
+		UserTokenRepository: &fakeUserTokenRepository{},
+		WebAuthnRepository:  newFakeWebAuthnRepository(),
+		Notifier:            notifier,
+	}, users, sessions, notifier
+}
+
+func TestPassword_ForgotAndReset(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	user := &models.User{Email: "john@example.com", Phone: "+6281234567890", PasswordHash: "old-hash", IsActive: true}
+	svc, users, sessions, notifier := newTestPasswordService(user)
+	ctx := context.Background()
+
+	// Act
+	if err := svc.ForgotPassword(ctx, &models.ForgotPasswordRequest{Email: " John@Example.com "}); err != nil {
+		t.Fatalf("ForgotPassword returned error: %v", err)
+	}
+	sent := notifier.sent(user.ID)
+	if len(sent) != 1 {
+		t.Fatalf("Expected one reset email, got %d", len(sent))
+	}
+	err := svc.ResetPassword(ctx, &models.ResetPasswordRequest{Token: sent[0], Password: "new-password"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ResetPassword returned error: %v", err)
+	}
+	stored, _ := users.GetByID(ctx, user.ID)
+	if !helpers.CheckPassword(stored.PasswordHash, "new-password") {
+		t.Error("Expected the new password to be set")
+	}
+	if active, _ := sessions.ListActiveByUserID(ctx, user.ID); len(active) != 0 {
+		t.Errorf("Expected every session to be revoked, got %d active", len(active))
+	}
+	reuseErr := svc.ResetPassword(ctx, &models.ResetPasswordRequest{Token: sent[0], Password: "another-password"})
+	if !errors.Is(reuseErr, ErrInvalidResetToken) {
+		t.Errorf("Expected ErrInvalidResetToken on reuse, got %v", reuseErr)
+	}
+}
+
+func TestPassword_ForgotPassword(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unknown email is silent", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := &models.User{Email: "john@example.com"}
+		svc, _, _, notifier := newTestPasswordService(user)
+
+		// Act
+		err := svc.ForgotPassword(context.Background(), &models.ForgotPasswordRequest{Email: "nobody@example.com"})
+
+		// Assert
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if len(notifier.sent(user.ID)) != 0 {
+			t.Error("Expected no email to be sent")
+		}
+	})
+
+	t.Run("delivery failure is silent", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := &models.User{Email: "john@example.com"}
+		svc, _, _, notifier := newTestPasswordService(user)
+		notifier.err = errors.New("smtp down")
+
+		// Act
+		err := svc.ForgotPassword(context.Background(), &models.ForgotPasswordRequest{Email: user.Email})
+
+		// Assert
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("silent within cooldown", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := &models.User{Email: "john@example.com"}
+		svc, _, _, notifier := newTestPasswordService(user)
+		req := &models.ForgotPasswordRequest{Email: user.Email}
+
+		// Act
+		for i := 0; i < 2; i++ {
+			if err := svc.ForgotPassword(context.Background(), req); err != nil {
+				t.Fatalf("ForgotPassword returned error: %v", err)
+			}
+		}
+
+		// Assert
+		if len(notifier.sent(user.ID)) != 1 {
+			t.Errorf("Expected a single email within the cooldown, got %d", len(notifier.sent(user.ID)))
+		}
+	})
+}
+
+func TestPassword_ResetPassword(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unknown token", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, _, _, _ := newTestPasswordService(&models.User{Email: "john@example.com"})
+
+		// Act
+		err := svc.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: "unknown", Password: "new-password"})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidResetToken) {
+			t.Errorf("Expected ErrInvalidResetToken, got %v", err)
+		}
+	})
+
+	t.Run("policy violation keeps the token usable", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := &models.User{Email: "john@example.com", FullName: "John Doe"}
+		svc, _, _, notifier := newTestPasswordService(user)
+		if err := svc.ForgotPassword(context.Background(), &models.ForgotPasswordRequest{Email: user.Email}); err != nil {
+			t.Fatalf("ForgotPassword returned error: %v", err)
+		}
+		token := notifier.sent(user.ID)[0]
+
+		// Act
+		err := svc.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: token, Password: "johndoe2024"})
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+		retryErr := svc.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: token, Password: "new-password"})
+		if retryErr != nil {
+			t.Errorf("Expected the token to remain usable, got %v", retryErr)
+		}
+	})
+}
+
+func TestPassword_ResetPassword_Unlocks(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lockedAt := time.Now()
+	user := &models.User{Email: "john@example.com", HardLockedAt: &lockedAt, LockoutCount: 3}
+	svc, users, _, notifier := newTestPasswordService(user)
+	if err := svc.ForgotPassword(context.Background(), &models.ForgotPasswordRequest{Email: user.Email}); err != nil {
+		t.Fatalf("ForgotPassword returned error: %v", err)
+	}
+
+	// Act
+	err := svc.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: notifier.sent(user.ID)[0], Password: "new-password"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ResetPassword returned error: %v", err)
+	}
+	if stored, _ := users.GetByID(context.Background(), user.ID); stored.HardLockedAt != nil || stored.LockoutCount != 0 {
+		t.Errorf("Expected the reset to lift the lock, got %+v", stored)
+	}
+}
+
+func TestPassword_ResetPassword_DeletesPasskeys(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	user := &models.User{ID: uuid.New(), Email: "john@example.com", IsMFAEnabled: true}
+	svc, _, _, notifier := newTestPasswordService(user)
+	passkeys := newFakeWebAuthnRepository()
+	_ = passkeys.CreateCredential(context.Background(), &models.UserCredential{ID: uuid.New(), UserID: user.ID})
+	_ = passkeys.CreateCredential(context.Background(), &models.UserCredential{ID: uuid.New(), UserID: uuid.New()})
+	svc.WebAuthnRepository = passkeys
+	if err := svc.ForgotPassword(context.Background(), &models.ForgotPasswordRequest{Email: user.Email}); err != nil {
+		t.Fatalf("ForgotPassword returned error: %v", err)
+	}
+
+	// Act
+	err := svc.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: notifier.sent(user.ID)[0], Password: "new-password"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ResetPassword returned error: %v", err)
+	}
+	if remaining, _ := passkeys.ListCredentialsByUserID(context.Background(), user.ID); len(remaining) != 0 {
+		t.Errorf("Expected the passkeys of the user to be deleted, got %d", len(remaining))
+	}
+	if len(passkeys.credentials) != 1 {
+		t.Error("Expected the passkeys of other users to be kept")
+	}
+}
+
+func TestPassword_ChangePassword(t *testing.T) {
+	t.Parallel()
+
+	hash, err := helpers.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	newUser := func() *models.User {
+		return &models.User{Email: "john@example.com", PasswordHash: hash, IsActive: true}
+	}
+	// addSession adds a second active session and returns its ID.
+	addSession := func(sessions *fakeSessionRepository, userID uuid.UUID) uuid.UUID {
+		session := &models.UserSession{ID: uuid.New(), UserID: userID, RefreshTokenExpiresAt: time.Now().Add(time.Hour)}
+		_ = sessions.Create(context.Background(), session)
+		return session.ID
+	}
+
+	t.Run("keeps the current session", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newUser()
+		svc, users, sessions, _ := newTestPasswordService(user)
+		current := addSession(sessions, user.ID)
+		req := &models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "new-password123"}
+
+		// Act
+		err := svc.ChangePassword(context.Background(), user.ID, current, req)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		stored, _ := users.GetByID(context.Background(), user.ID)
+		if !helpers.CheckPassword(stored.PasswordHash, "new-password123") {
+			t.Error("Expected the new password to be set")
+		}
+		active, _ := sessions.ListActiveByUserID(context.Background(), user.ID)
+		if len(active) != 1 || active[0].ID != current {
+			t.Errorf("Expected only the current session to stay active, got %d sessions", len(active))
+		}
+	})
+
+	t.Run("revokes all sessions on request", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newUser()
+		svc, _, sessions, _ := newTestPasswordService(user)
+		current := addSession(sessions, user.ID)
+		req := &models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "new-password123", RevokeAllSessions: true}
+
+		// Act
+		err := svc.ChangePassword(context.Background(), user.ID, current, req)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if active, _ := sessions.ListActiveByUserID(context.Background(), user.ID); len(active) != 0 {
+			t.Errorf("Expected every session to be revoked, got %d active", len(active))
+		}
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newUser()
+		svc, users, _, _ := newTestPasswordService(user)
+		req := &models.ChangePasswordRequest{CurrentPassword: "wrong-password", NewPassword: "new-password123"}
+
+		// Act
+		err := svc.ChangePassword(context.Background(), user.ID, uuid.New(), req)
+
+		// Assert
+		if !errors.Is(err, ErrIncorrectPassword) {
+			t.Errorf("Expected ErrIncorrectPassword, got %v", err)
+		}
+		stored, _ := users.GetByID(context.Background(), user.ID)
+		if stored.PasswordHash != hash {
+			t.Error("Expected the password to stay unchanged")
+		}
+		if stored.FailedLoginAttempts != 1 {
+			t.Errorf("Expected the failure to be counted, got %d", stored.FailedLoginAttempts)
+		}
+	})
+
+	t.Run("wrong current passwords lock the account", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newUser()
+		svc, _, _, _ := newTestPasswordService(user)
+		wrong := &models.ChangePasswordRequest{CurrentPassword: "wrong-password", NewPassword: "new-password123"}
+		for i := 0; i < 5; i++ {
+			if err := svc.ChangePassword(context.Background(), user.ID, uuid.New(), wrong); !errors.Is(err, ErrIncorrectPassword) {
+				t.Fatalf("Expected ErrIncorrectPassword, got %v", err)
+			}
+		}
+		req := &models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "new-password123"}
+
+		// Act
+		err := svc.ChangePassword(context.Background(), user.ID, uuid.New(), req)
+
+		// Assert
+		if !errors.Is(err, ErrAccountTemporarilyLocked) {
+			t.Errorf("Expected ErrAccountTemporarilyLocked, got %v", err)
+		}
+	})
+
+	t.Run("new password must differ", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newUser()
+		svc, _, _, _ := newTestPasswordService(user)
+		req := &models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "password123"}
+
+		// Act
+		err := svc.ChangePassword(context.Background(), user.ID, uuid.New(), req)
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Fields[0].Message != "must be different from current_password" {
+			t.Errorf("Expected ValidationError for the unchanged password, got %v", err)
+		}
+	})
+}
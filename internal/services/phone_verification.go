@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// PhoneVerification service implementation.
+type PhoneVerification struct {
+	UserRepository     interfaces.IUserRepository
+	PhoneOTPRepository interfaces.IPhoneOTPRepository
+	SMSProvider        interfaces.ISMSProvider
+	// PhoneLimiter caps how many codes are requested for each phone number
+	PhoneLimiter *helpers.RateLimiter
+}
+
+// RequestOTP sends a new verification code to the phone number, invalidating earlier ones.
+// The phone limit is checked before the user lookup and unknown or already verified
+// numbers report success, so the endpoint does not reveal which numbers are registered.
+func (s *PhoneVerification) RequestOTP(ctx context.Context, req *models.RequestPhoneOTPRequest) error {
+	req.Phone = strings.TrimSpace(req.Phone)
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	if ok, retryAfter := s.PhoneLimiter.Allow(req.Phone); !ok {
+		return &domain.RetryAfterError{Err: ErrTooManyOTPRequests, RetryAfter: retryAfter}
+	}
+
+	user, err := s.UserRepository.GetByPhone(ctx, req.Phone)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsPhoneVerified {
+		return nil
+	}
+
+	message := "%s is your e-wallet verification code. It expires in %d minutes. Never share this code."
+	return sendPhoneOTP(ctx, s.PhoneOTPRepository, s.SMSProvider, user, constants.OTPPurposePhoneVerification, message)
+}
+
+// VerifyOTP checks the code against the latest active verification OTP of the phone number
+// and marks the user's phone verified. It also activates the account unless support staff
+// deactivated it.
+func (s *PhoneVerification) VerifyOTP(ctx context.Context, req *models.VerifyPhoneOTPRequest) error {
+	req.Phone = strings.TrimSpace(req.Phone)
+	req.Code = strings.TrimSpace(req.Code)
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	otp, err := checkPhoneOTP(ctx, s.PhoneOTPRepository, constants.OTPPurposePhoneVerification, req.Phone, req.Code)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, otp.UserID)
+	if err != nil {
+		return mapOTPNotFound(err)
+	}
+	// The number may have changed since the code was sent
+	if user.Phone != otp.Phone {
+		return ErrInvalidOTP
+	}
+
+	user.IsPhoneVerified = true
+	activateVerifiedUser(user)
+	return s.UserRepository.Update(ctx, user)
+}
+
+// sendPhoneOTP sends a new code for purpose to the user's phone number, invalidating the
+// earlier codes sent for the same purpose. message is formatted with the code and its
+// lifetime in minutes.
+func sendPhoneOTP(
+	ctx context.Context, repo interfaces.IPhoneOTPRepository, sms interfaces.ISMSProvider, user *models.User, purpose, message string,
+) error {
+	if err := repo.InvalidateByPhone(ctx, purpose, user.Phone); err != nil {
+		return err
+	}
+
+	code, err := helpers.GenerateNumericCode(constants.PhoneOTPLength)
+	if err != nil {
+		return err
+	}
+
+	ttl := helpers.GetEnvDuration("PHONE_OTP_TTL", constants.DefaultPhoneOTPTTL)
+	err = repo.Create(ctx, &models.PhoneOTP{
+		UserID:    user.ID,
+		Phone:     user.Phone,
+		Purpose:   purpose,
+		CodeHash:  hashOTP(user.Phone, code),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	return sms.SendSMS(ctx, user.Phone, fmt.Sprintf(message, code, int(ttl.Minutes())))
+}
+
+// checkPhoneOTP checks a code against the latest active OTP sent for purpose to the phone
+// number and consumes it. Every attempt counts against the OTP, which stops accepting codes
+// after PHONE_OTP_MAX_ATTEMPTS wrong guesses.
+func checkPhoneOTP(
+	ctx context.Context, repo interfaces.IPhoneOTPRepository, purpose, phone, code string,
+) (*models.PhoneOTP, error) {
+	otp, err := repo.GetActiveByPhone(ctx, purpose, phone)
+	if err != nil {
+		return nil, mapOTPNotFound(err)
+	}
+
+	maxAttempts := helpers.GetEnvInt("PHONE_OTP_MAX_ATTEMPTS", constants.DefaultPhoneOTPMaxAttempts)
+	if err := repo.RecordAttempt(ctx, otp.ID, maxAttempts); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			helpers.LogSecurityEvent(constants.SecurityEventOTPAttemptsExhausted, logrus.Fields{
+				"user_id": otp.UserID,
+				"otp_id":  otp.ID,
+				"purpose": purpose,
+			})
+		}
+		return nil, mapOTPNotFound(err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashOTP(phone, code)), []byte(otp.CodeHash)) != 1 {
+		return nil, ErrInvalidOTP
+	}
+
+	if err := repo.Consume(ctx, otp.ID); err != nil {
+		return nil, mapOTPNotFound(err)
+	}
+	return otp, nil
+}
+
+// hashOTP binds a code to its phone number before hashing, so equal codes sent
+// to different numbers are stored differently.
+func hashOTP(phone, code string) string {
+	return helpers.HashToken(phone + ":" + code)
+}
+
+func mapOTPNotFound(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrInvalidOTP
+	}
+	return err
+}
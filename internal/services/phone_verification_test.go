@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// fakePhoneOTPRepository is an in-memory IPhoneOTPRepository for service tests.
+type fakePhoneOTPRepository struct {
+	otps []*models.PhoneOTP
+	mu   sync.Mutex
+}
+
+func (f *fakePhoneOTPRepository) Create(_ context.Context, otp *models.PhoneOTP) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	otp.ID = uuid.New()
+	otp.CreatedAt = time.Now()
+	stored := *otp
+	f.otps = append(f.otps, &stored)
+	return nil
+}
+
+// active returns the OTP with the given ID if it can still be used; callers hold mu.
+func (f *fakePhoneOTPRepository) active(id uuid.UUID) (*models.PhoneOTP, error) {
+	for _, o := range f.otps {
+		if o.ID == id && !o.ConsumedAt.Valid && o.ExpiresAt.After(time.Now()) {
+			return o, nil
+		}
+	}
+	return nil, domain.NewError(domain.ErrNotFound, "OTP not found")
+}
+
+func (f *fakePhoneOTPRepository) GetActiveByPhone(_ context.Context, purpose, phone string) (*models.PhoneOTP, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.otps) - 1; i >= 0; i-- {
+		if o := f.otps[i]; o.Phone == phone && o.Purpose == purpose && !o.ConsumedAt.Valid && o.ExpiresAt.After(time.Now()) {
+			found := *o
+			return &found, nil
+		}
+	}
+	return nil, domain.NewError(domain.ErrNotFound, "OTP not found")
+}
+
+func (f *fakePhoneOTPRepository) RecordAttempt(_ context.Context, id uuid.UUID, maxAttempts int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	otp, err := f.active(id)
+	if err != nil || otp.Attempts >= maxAttempts {
+		return domain.NewError(domain.ErrNotFound, "OTP not found")
+	}
+	otp.Attempts++
+	return nil
+}
+
+func (f *fakePhoneOTPRepository) Consume(_ context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	otp, err := f.active(id)
+	if err != nil {
+		return err
+	}
+	otp.ConsumedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (f *fakePhoneOTPRepository) InvalidateByPhone(_ context.Context, purpose, phone string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, o := range f.otps {
+		if o.Phone == phone && o.Purpose == purpose && !o.ConsumedAt.Valid {
+			o.ConsumedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+	}
+	return nil
+}
+
+// fakeSMSProvider records the codes it was asked to send per phone number.
+type fakeSMSProvider struct {
+	codes map[string][]string
+	mu    sync.Mutex
+}
+
+func (f *fakeSMSProvider) SendSMS(_ context.Context, phone, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// Messages start with the code
+	f.codes[phone] = append(f.codes[phone], strings.Fields(message)[0])
+	return nil
+}
+
+func (f *fakeSMSProvider) sent(phone string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.codes[phone]
+}
+
+func newTestPhoneVerification(users ...*models.User) (*PhoneVerification, *fakeUserRepository, *fakeSMSProvider) {
+	repo := newFakeUserRepository(users...)
+	sms := &fakeSMSProvider{codes: make(map[string][]string)}
+	return &PhoneVerification{
+		UserRepository:     repo,
+		PhoneOTPRepository: &fakePhoneOTPRepository{},
+		SMSProvider:        sms,
+		PhoneLimiter:       helpers.NewRateLimiter(3, time.Hour),
+	}, repo, sms
+}
+
+func newUnverifiedPhoneUser() *models.User {
+	return &models.User{Email: "john@example.com", Phone: "+6281234567890", FullName: "John Doe"}
+}
+
+func TestPhoneVerification_RequestAndVerify(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	user := newUnverifiedPhoneUser()
+	svc, repo, sms := newTestPhoneVerification(user)
+	ctx := context.Background()
+
+	// Act
+	err := svc.RequestOTP(ctx, &models.RequestPhoneOTPRequest{Phone: user.Phone})
+	if err != nil {
+		t.Fatalf("RequestOTP returned error: %v", err)
+	}
+	codes := sms.sent(user.Phone)
+	if len(codes) != 1 || len(codes[0]) != 6 {
+		t.Fatalf("Expected one 6 digit code, got %v", codes)
+	}
+	err = svc.VerifyOTP(ctx, &models.VerifyPhoneOTPRequest{Phone: user.Phone, Code: codes[0]})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("VerifyOTP returned error: %v", err)
+	}
+	stored, _ := repo.GetByID(ctx, user.ID)
+	if !stored.IsPhoneVerified || !stored.IsActive {
+		t.Errorf("Expected phone verified and account active, got %+v", stored)
+	}
+	reuseErr := svc.VerifyOTP(ctx, &models.VerifyPhoneOTPRequest{Phone: user.Phone, Code: codes[0]})
+	if !errors.Is(reuseErr, ErrInvalidOTP) {
+		t.Errorf("Expected ErrInvalidOTP on reuse, got %v", reuseErr)
+	}
+}
+
+func TestPhoneVerification_VerifyOTP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("attempts are limited", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newUnverifiedPhoneUser()
+		svc, _, sms := newTestPhoneVerification(user)
+		ctx := context.Background()
+		if err := svc.RequestOTP(ctx, &models.RequestPhoneOTPRequest{Phone: user.Phone}); err != nil {
+			t.Fatalf("RequestOTP returned error: %v", err)
+		}
+		code := sms.sent(user.Phone)[0]
+		wrong := "000000"
+		if code == wrong {
+			wrong = "111111"
+		}
+
+		// Act
+		for i := 0; i < 5; i++ {
+			if err := svc.VerifyOTP(ctx, &models.VerifyPhoneOTPRequest{Phone: user.Phone, Code: wrong}); !errors.Is(err, ErrInvalidOTP) {
+				t.Fatalf("Expected ErrInvalidOTP for wrong code, got %v", err)
+			}
+		}
+		err := svc.VerifyOTP(ctx, &models.VerifyPhoneOTPRequest{Phone: user.Phone, Code: code})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidOTP) {
+			t.Errorf("Expected correct code to be rejected after too many attempts, got %v", err)
+		}
+	})
+
+	t.Run("keeps a deactivated user inactive", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		deactivatedAt := time.Now().Add(-time.Hour)
+		user := newUnverifiedPhoneUser()
+		user.DeactivatedAt = &deactivatedAt
+		svc, repo, sms := newTestPhoneVerification(user)
+		ctx := context.Background()
+		if err := svc.RequestOTP(ctx, &models.RequestPhoneOTPRequest{Phone: user.Phone}); err != nil {
+			t.Fatalf("RequestOTP returned error: %v", err)
+		}
+
+		// Act
+		err := svc.VerifyOTP(ctx, &models.VerifyPhoneOTPRequest{Phone: user.Phone, Code: sms.sent(user.Phone)[0]})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		stored, _ := repo.GetByID(ctx, user.ID)
+		if !stored.IsPhoneVerified || stored.IsActive {
+			t.Errorf("Expected phone verified but the user to stay inactive, got %+v", stored)
+		}
+	})
+
+	t.Run("earlier code is invalidated by a new request", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newUnverifiedPhoneUser()
+		svc, _, sms := newTestPhoneVerification(user)
+		ctx := context.Background()
+		for i := 0; i < 2; i++ {
+			if err := svc.RequestOTP(ctx, &models.RequestPhoneOTPRequest{Phone: user.Phone}); err != nil {
+				t.Fatalf("RequestOTP returned error: %v", err)
+			}
+		}
+		first := sms.sent(user.Phone)[0]
+		if first == sms.sent(user.Phone)[1] {
+			t.Skip("Both requests produced the same code")
+		}
+
+		// Act
+		err := svc.VerifyOTP(ctx, &models.VerifyPhoneOTPRequest{Phone: user.Phone, Code: first})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidOTP) {
+			t.Errorf("Expected ErrInvalidOTP for superseded code, got %v", err)
+		}
+	})
+
+	t.Run("validation failure", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, _, _ := newTestPhoneVerification()
+
+		// Act
+		err := svc.VerifyOTP(context.Background(), &models.VerifyPhoneOTPRequest{Phone: "0812", Code: "12ab"})
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) || len(validationErr.Fields) != 2 {
+			t.Errorf("Expected ValidationError for both fields, got %v", err)
+		}
+	})
+}
+
+func TestPhoneVerification_RequestOTP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("silent for unknown and verified numbers", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		verified := &models.User{Email: "jane@example.com", Phone: "+6280000000000", IsPhoneVerified: true}
+		svc, _, sms := newTestPhoneVerification(verified)
+
+		for _, phone := range []string{"+6289999999999", verified.Phone} {
+			// Act
+			err := svc.RequestOTP(context.Background(), &models.RequestPhoneOTPRequest{Phone: phone})
+
+			// Assert
+			if err != nil {
+				t.Errorf("Expected no error for %s, got %v", phone, err)
+			}
+			if len(sms.sent(phone)) != 0 {
+				t.Errorf("Expected no SMS to %s", phone)
+			}
+		}
+	})
+
+	t.Run("rate limited per phone", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, _, _ := newTestPhoneVerification()
+		req := &models.RequestPhoneOTPRequest{Phone: "+6289999999999"}
+		for i := 0; i < 3; i++ {
+			_ = svc.RequestOTP(context.Background(), req)
+		}
+
+		// Act
+		err := svc.RequestOTP(context.Background(), req)
+
+		// Assert
+		var retryErr *domain.RetryAfterError
+		if !errors.Is(err, ErrTooManyOTPRequests) || !errors.As(err, &retryErr) || retryErr.RetryAfter <= 0 {
+			t.Errorf("Expected ErrTooManyOTPRequests with a retry delay, got %v", err)
+		}
+	})
+}
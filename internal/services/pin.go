@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// PIN service implementation. The transaction PIN confirms money movements and is kept
+// apart from the login password, so a stolen session alone cannot move funds.
+type PIN struct {
+	UserRepository     interfaces.IUserRepository
+	PhoneOTPRepository interfaces.IPhoneOTPRepository
+	SMSProvider        interfaces.ISMSProvider
+	// PhoneLimiter caps how many codes are requested for each phone number
+	PhoneLimiter *helpers.RateLimiter
+}
+
+// SetPIN sets the first transaction PIN of a user, confirmed with the account password.
+// A wrong password counts towards the login lockout, so a stolen session cannot be used to
+// guess it. A PIN that is already set can only be changed or reset.
+func (s *PIN) SetPIN(ctx context.Context, userID uuid.UUID, req *models.SetPINRequest) error {
+	req.PIN = strings.TrimSpace(req.PIN)
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return mapUserNotFound(err)
+	}
+	if user.PINHash.Valid {
+		return ErrPINAlreadySet
+	}
+	if err := checkLockout(user); err != nil {
+		return err
+	}
+
+	if !helpers.CheckPassword(user.PasswordHash, req.Password) {
+		if err := recordLoginFailure(ctx, s.UserRepository, user, ""); err != nil {
+			return err
+		}
+		return ErrIncorrectPassword
+	}
+
+	return s.updatePIN(ctx, user, "pin", req.PIN, "set")
+}
+
+// ChangePIN replaces the transaction PIN after checking the current one. Wrong current
+// PINs count towards the same lockout as failed transaction confirmations.
+func (s *PIN) ChangePIN(ctx context.Context, userID uuid.UUID, req *models.ChangePINRequest) error {
+	req.CurrentPIN = strings.TrimSpace(req.CurrentPIN)
+	req.NewPIN = strings.TrimSpace(req.NewPIN)
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return mapUserNotFound(err)
+	}
+	if !user.PINHash.Valid {
+		return ErrPINNotSet
+	}
+	if err := checkPINLockout(user); err != nil {
+		return err
+	}
+
+	if !helpers.CheckPassword(user.PINHash.String, req.CurrentPIN) {
+		if _, err := s.recordPINFailure(ctx, user); err != nil {
+			return err
+		}
+		return ErrIncorrectPIN
+	}
+
+	return s.updatePIN(ctx, user, "new_pin", req.NewPIN, "change")
+}
+
+// RequestPINReset sends a PIN reset code by SMS to the user's verified phone number,
+// invalidating earlier reset codes.
+func (s *PIN) RequestPINReset(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return mapUserNotFound(err)
+	}
+	if !user.IsPhoneVerified {
+		return ErrPhoneNotVerified
+	}
+
+	if ok, retryAfter := s.PhoneLimiter.Allow(user.Phone); !ok {
+		return &domain.RetryAfterError{Err: ErrTooManyOTPRequests, RetryAfter: retryAfter}
+	}
+
+	message := "%s is your e-wallet PIN reset code. It expires in %d minutes. Never share this code."
+	return sendPhoneOTP(ctx, s.PhoneOTPRepository, s.SMSProvider, user, constants.OTPPurposePINReset, message)
+}
+
+// ResetPIN replaces a forgotten transaction PIN with the code sent by RequestPINReset. It
+// also sets a PIN for users who never had one and lifts any PIN lockout.
+func (s *PIN) ResetPIN(ctx context.Context, userID uuid.UUID, req *models.ResetPINRequest) error {
+	req.Code = strings.TrimSpace(req.Code)
+	req.NewPIN = strings.TrimSpace(req.NewPIN)
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return mapUserNotFound(err)
+	}
+	if !user.IsPhoneVerified {
+		return ErrPhoneNotVerified
+	}
+
+	otp, err := checkPhoneOTP(ctx, s.PhoneOTPRepository, constants.OTPPurposePINReset, user.Phone, req.Code)
+	if err != nil {
+		return err
+	}
+	// The number may have changed hands since the code was sent
+	if otp.UserID != user.ID {
+		return ErrInvalidOTP
+	}
+
+	return s.updatePIN(ctx, user, "new_pin", req.NewPIN, "reset")
+}
+
+// VerifyPIN checks the transaction PIN of a user for an internal service about to move
+// money. A wrong PIN is reported in the response rather than as an error, and every
+// PIN_MAX_FAILED_ATTEMPTS wrong PINs lock verification for PIN_LOCKOUT_DURATION.
+func (s *PIN) VerifyPIN(ctx context.Context, req *models.VerifyPINRequest) (*models.PINVerificationResponse, error) {
+	req.PIN = strings.TrimSpace(req.PIN)
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, mapUserNotFound(err)
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+	if !user.PINHash.Valid {
+		return nil, ErrPINNotSet
+	}
+
+	if user.PINLockedUntil != nil && user.PINLockedUntil.After(time.Now()) {
+		return &models.PINVerificationResponse{LockedUntil: user.PINLockedUntil}, nil
+	}
+
+	if !helpers.CheckPassword(user.PINHash.String, req.PIN) {
+		return s.recordPINFailure(ctx, user)
+	}
+
+	if user.PINFailedAttempts > 0 || user.PINLockedUntil != nil {
+		if err := s.UserRepository.ClearPINFailures(ctx, user.ID); err != nil {
+			return nil, mapUserNotFound(err)
+		}
+	}
+
+	maxAttempts := helpers.GetEnvInt("PIN_MAX_FAILED_ATTEMPTS", constants.DefaultPINMaxFailedAttempts)
+	return &models.PINVerificationResponse{Valid: true, AttemptsRemaining: maxAttempts}, nil
+}
+
+// updatePIN checks a new PIN against the PIN rules and stores its hash. field names the
+// request field holding the PIN and action is logged with the change.
+func (s *PIN) updatePIN(ctx context.Context, user *models.User, field, pin, action string) error {
+	if err := helpers.ValidatePIN(field, pin, user.Phone); err != nil {
+		return err
+	}
+
+	pinHash, err := helpers.HashPassword(pin)
+	if err != nil {
+		return err
+	}
+
+	if err := s.UserRepository.UpdatePIN(ctx, user.ID, pinHash); err != nil {
+		return mapUserNotFound(err)
+	}
+
+	helpers.LogSecurityEvent(constants.SecurityEventPINChanged, logrus.Fields{
+		"user_id": user.ID,
+		"action":  action,
+	})
+	return nil
+}
+
+// recordPINFailure counts a wrong PIN against the user and locks PIN verification for
+// PIN_LOCKOUT_DURATION once PIN_MAX_FAILED_ATTEMPTS is reached.
+func (s *PIN) recordPINFailure(ctx context.Context, user *models.User) (*models.PINVerificationResponse, error) {
+	attempts, err := s.UserRepository.RecordFailedPIN(ctx, user.ID)
+	if err != nil {
+		return nil, mapUserNotFound(err)
+	}
+
+	helpers.LogSecurityEvent(constants.SecurityEventPINFailed, logrus.Fields{
+		"user_id":  user.ID,
+		"attempts": attempts,
+	})
+
+	maxAttempts := helpers.GetEnvInt("PIN_MAX_FAILED_ATTEMPTS", constants.DefaultPINMaxFailedAttempts)
+	if attempts < maxAttempts {
+		return &models.PINVerificationResponse{AttemptsRemaining: maxAttempts - attempts}, nil
+	}
+
+	until := time.Now().Add(helpers.GetEnvDuration("PIN_LOCKOUT_DURATION", constants.DefaultPINLockoutDuration))
+	if err := s.UserRepository.LockPIN(ctx, user.ID, until); err != nil {
+		return nil, mapUserNotFound(err)
+	}
+	helpers.LogSecurityEvent(constants.SecurityEventPINLocked, logrus.Fields{
+		"user_id":      user.ID,
+		"locked_until": until,
+	})
+	return &models.PINVerificationResponse{LockedUntil: &until}, nil
+}
+
+// checkPINLockout returns a RetryAfterError wrapping ErrPINLocked while PIN verification
+// of the user is locked.
+func checkPINLockout(user *models.User) error {
+	if user.PINLockedUntil != nil {
+		if remaining := time.Until(*user.PINLockedUntil); remaining > 0 {
+			return &domain.RetryAfterError{Err: ErrPINLocked, RetryAfter: remaining}
+		}
+	}
+	return nil
+}
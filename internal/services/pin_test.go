@@ -0,0 +1,455 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+const testPIN = "582931"
+
+// newPINUser returns an active user with a verified phone number and the password
+// "password123". The transaction PIN is set to pin unless it is empty.
+func newPINUser(t *testing.T, pin string) *models.User {
+	t.Helper()
+
+	passwordHash, err := helpers.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	user := &models.User{
+		Email:           "john@example.com",
+		Phone:           "+6281234567890",
+		FullName:        "John Doe",
+		PasswordHash:    passwordHash,
+		IsActive:        true,
+		IsVerified:      true,
+		IsPhoneVerified: true,
+	}
+	if pin != "" {
+		pinHash, err := helpers.HashPassword(pin)
+		if err != nil {
+			t.Fatalf("Failed to hash PIN: %v", err)
+		}
+		user.PINHash = sql.NullString{String: pinHash, Valid: true}
+	}
+	return user
+}
+
+func newTestPIN(users ...*models.User) (*PIN, *fakeUserRepository, *fakeSMSProvider) {
+	repo := newFakeUserRepository(users...)
+	sms := &fakeSMSProvider{codes: make(map[string][]string)}
+	return &PIN{
+		UserRepository:     repo,
+		PhoneOTPRepository: &fakePhoneOTPRepository{},
+		SMSProvider:        sms,
+		PhoneLimiter:       helpers.NewRateLimiter(3, time.Hour),
+	}, repo, sms
+}
+
+// verifyPIN checks pin for the user and fails the test on an error.
+func verifyPIN(t *testing.T, svc *PIN, userID uuid.UUID, pin string) *models.PINVerificationResponse {
+	t.Helper()
+
+	result, err := svc.VerifyPIN(context.Background(), &models.VerifyPINRequest{UserID: userID, PIN: pin})
+	if err != nil {
+		t.Fatalf("VerifyPIN returned error: %v", err)
+	}
+	return result
+}
+
+func TestPIN_SetPIN(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newPINUser(t, "")
+		svc, repo, _ := newTestPIN(user)
+
+		// Act
+		err := svc.SetPIN(context.Background(), user.ID, &models.SetPINRequest{PIN: testPIN, Password: "password123"})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("SetPIN returned error: %v", err)
+		}
+		stored, _ := repo.GetByID(context.Background(), user.ID)
+		if stored.PINUpdatedAt == nil || stored.PINHash.String == testPIN {
+			t.Errorf("Expected a hashed PIN to be stored, got %+v", stored)
+		}
+		if result := verifyPIN(t, svc, user.ID, testPIN); !result.Valid {
+			t.Errorf("Expected the new PIN to verify, got %+v", result)
+		}
+	})
+
+	tests := []struct {
+		wantErr error
+		name    string
+		pin     string
+		req     models.SetPINRequest
+	}{
+		{name: "already set", pin: testPIN, req: models.SetPINRequest{PIN: "740316", Password: "password123"}, wantErr: ErrPINAlreadySet},
+		{name: "wrong password", req: models.SetPINRequest{PIN: testPIN, Password: "wrong-password"}, wantErr: ErrIncorrectPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			user := newPINUser(t, tt.pin)
+			svc, _, _ := newTestPIN(user)
+
+			// Act
+			err := svc.SetPIN(context.Background(), user.ID, &tt.req)
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("wrong passwords lock the account", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newPINUser(t, "")
+		svc, repo, _ := newTestPIN(user)
+		wrong := &models.SetPINRequest{PIN: testPIN, Password: "wrong-password"}
+		for i := 0; i < 5; i++ {
+			if err := svc.SetPIN(context.Background(), user.ID, wrong); !errors.Is(err, ErrIncorrectPassword) {
+				t.Fatalf("Expected ErrIncorrectPassword, got %v", err)
+			}
+		}
+
+		// Act
+		err := svc.SetPIN(context.Background(), user.ID, &models.SetPINRequest{PIN: testPIN, Password: "password123"})
+
+		// Assert
+		if !errors.Is(err, ErrAccountTemporarilyLocked) {
+			t.Errorf("Expected ErrAccountTemporarilyLocked, got %v", err)
+		}
+		if stored, _ := repo.GetByID(context.Background(), user.ID); stored.PINHash.Valid {
+			t.Error("Expected no PIN to be set")
+		}
+	})
+
+	t.Run("weak PIN", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newPINUser(t, "")
+		svc, _, _ := newTestPIN(user)
+
+		// Act
+		err := svc.SetPIN(context.Background(), user.ID, &models.SetPINRequest{PIN: "123456", Password: "password123"})
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != "pin" {
+			t.Errorf("Expected ValidationError on pin, got %v", err)
+		}
+	})
+
+	for _, pin := range []string{"+12345", "-00000", "1.2345"} {
+		t.Run("signed or decimal PIN "+pin, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			user := newPINUser(t, "")
+			svc, repo, _ := newTestPIN(user)
+
+			// Act
+			err := svc.SetPIN(context.Background(), user.ID, &models.SetPINRequest{PIN: pin, Password: "password123"})
+
+			// Assert
+			var validationErr *helpers.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Fields[0].Message != "must contain only digits" {
+				t.Errorf("Expected a digits only ValidationError, got %v", err)
+			}
+			if stored, _ := repo.GetByID(context.Background(), user.ID); stored.PINHash.Valid {
+				t.Error("Expected no PIN to be set")
+			}
+		})
+	}
+}
+
+func TestPIN_ChangePIN(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newPINUser(t, testPIN)
+		svc, _, _ := newTestPIN(user)
+
+		// Act
+		err := svc.ChangePIN(context.Background(), user.ID, &models.ChangePINRequest{CurrentPIN: testPIN, NewPIN: "740316"})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("ChangePIN returned error: %v", err)
+		}
+		if result := verifyPIN(t, svc, user.ID, "740316"); !result.Valid {
+			t.Errorf("Expected the new PIN to verify, got %+v", result)
+		}
+	})
+
+	t.Run("wrong current PIN counts towards the lockout", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newPINUser(t, testPIN)
+		svc, _, _ := newTestPIN(user)
+		req := &models.ChangePINRequest{CurrentPIN: "740316", NewPIN: "691027"}
+
+		// Act
+		for i := 0; i < 3; i++ {
+			if err := svc.ChangePIN(context.Background(), user.ID, req); !errors.Is(err, ErrIncorrectPIN) {
+				t.Fatalf("Expected ErrIncorrectPIN, got %v", err)
+			}
+		}
+		err := svc.ChangePIN(context.Background(), user.ID, &models.ChangePINRequest{CurrentPIN: testPIN, NewPIN: "691027"})
+
+		// Assert
+		var retryErr *domain.RetryAfterError
+		if !errors.As(err, &retryErr) || !errors.Is(err, ErrPINLocked) {
+			t.Errorf("Expected RetryAfterError wrapping ErrPINLocked, got %v", err)
+		}
+	})
+
+	t.Run("PIN not set", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newPINUser(t, "")
+		svc, _, _ := newTestPIN(user)
+
+		// Act
+		err := svc.ChangePIN(context.Background(), user.ID, &models.ChangePINRequest{CurrentPIN: testPIN, NewPIN: "740316"})
+
+		// Assert
+		if !errors.Is(err, ErrPINNotSet) {
+			t.Errorf("Expected ErrPINNotSet, got %v", err)
+		}
+	})
+}
+
+func TestPIN_ResetPIN(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newPINUser(t, testPIN)
+		until := time.Now().Add(time.Hour)
+		user.PINLockedUntil = &until
+		svc, _, sms := newTestPIN(user)
+		ctx := context.Background()
+		if err := svc.RequestPINReset(ctx, user.ID); err != nil {
+			t.Fatalf("RequestPINReset returned error: %v", err)
+		}
+		code := sms.sent(user.Phone)[0]
+
+		// Act
+		err := svc.ResetPIN(ctx, user.ID, &models.ResetPINRequest{Code: code, NewPIN: "740316"})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("ResetPIN returned error: %v", err)
+		}
+		if result := verifyPIN(t, svc, user.ID, "740316"); !result.Valid {
+			t.Errorf("Expected the new PIN to verify after the lock is lifted, got %+v", result)
+		}
+		reuseErr := svc.ResetPIN(ctx, user.ID, &models.ResetPINRequest{Code: code, NewPIN: "691027"})
+		if !errors.Is(reuseErr, ErrInvalidOTP) {
+			t.Errorf("Expected ErrInvalidOTP on reuse, got %v", reuseErr)
+		}
+	})
+
+	t.Run("reset code cannot verify a phone number", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newPINUser(t, testPIN)
+		svc, repo, sms := newTestPIN(user)
+		phoneVerification := &PhoneVerification{
+			UserRepository:     repo,
+			PhoneOTPRepository: svc.PhoneOTPRepository,
+			SMSProvider:        sms,
+			PhoneLimiter:       svc.PhoneLimiter,
+		}
+		if err := svc.RequestPINReset(context.Background(), user.ID); err != nil {
+			t.Fatalf("RequestPINReset returned error: %v", err)
+		}
+
+		// Act
+		err := phoneVerification.VerifyOTP(context.Background(),
+			&models.VerifyPhoneOTPRequest{Phone: user.Phone, Code: sms.sent(user.Phone)[0]})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidOTP) {
+			t.Errorf("Expected ErrInvalidOTP, got %v", err)
+		}
+	})
+
+	t.Run("phone not verified", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newPINUser(t, testPIN)
+		user.IsPhoneVerified = false
+		svc, _, sms := newTestPIN(user)
+
+		// Act
+		err := svc.RequestPINReset(context.Background(), user.ID)
+
+		// Assert
+		if !errors.Is(err, ErrPhoneNotVerified) {
+			t.Errorf("Expected ErrPhoneNotVerified, got %v", err)
+		}
+		if len(sms.sent(user.Phone)) != 0 {
+			t.Error("Expected no SMS to be sent")
+		}
+	})
+}
+
+func TestPIN_VerifyPIN(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lockout after repeated wrong PINs", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newPINUser(t, testPIN)
+		svc, _, _ := newTestPIN(user)
+
+		// Act
+		first := verifyPIN(t, svc, user.ID, "740316")
+		second := verifyPIN(t, svc, user.ID, "740316")
+		third := verifyPIN(t, svc, user.ID, "740316")
+		locked := verifyPIN(t, svc, user.ID, testPIN)
+
+		// Assert
+		if first.Valid || first.AttemptsRemaining != 2 || second.AttemptsRemaining != 1 {
+			t.Errorf("Expected remaining attempts to count down, got %+v and %+v", first, second)
+		}
+		if third.Valid || third.LockedUntil == nil {
+			t.Errorf("Expected the third wrong PIN to lock verification, got %+v", third)
+		}
+		if locked.Valid || locked.LockedUntil == nil {
+			t.Errorf("Expected the correct PIN to be rejected while locked, got %+v", locked)
+		}
+	})
+
+	t.Run("correct PIN clears failures", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newPINUser(t, testPIN)
+		svc, repo, _ := newTestPIN(user)
+		verifyPIN(t, svc, user.ID, "740316")
+
+		// Act
+		result := verifyPIN(t, svc, user.ID, testPIN)
+
+		// Assert
+		if !result.Valid || result.AttemptsRemaining != 3 {
+			t.Errorf("Expected a valid PIN with all attempts left, got %+v", result)
+		}
+		if stored, _ := repo.GetByID(context.Background(), user.ID); stored.PINFailedAttempts != 0 {
+			t.Errorf("Expected failed PINs to be cleared, got %d", stored.PINFailedAttempts)
+		}
+	})
+
+	tests := []struct {
+		wantErr error
+		prepare func(*models.User)
+		name    string
+		pin     string
+	}{
+		{name: "PIN not set", wantErr: ErrPINNotSet},
+		{name: "inactive user", pin: testPIN, prepare: func(u *models.User) { u.IsActive = false }, wantErr: ErrUserInactive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			user := newPINUser(t, tt.pin)
+			if tt.prepare != nil {
+				tt.prepare(user)
+			}
+			svc, _, _ := newTestPIN(user)
+
+			// Act
+			_, err := svc.VerifyPIN(context.Background(), &models.VerifyPINRequest{UserID: user.ID, PIN: testPIN})
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("unknown user", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, _, _ := newTestPIN()
+
+		// Act
+		_, err := svc.VerifyPIN(context.Background(), &models.VerifyPINRequest{UserID: uuid.New(), PIN: testPIN})
+
+		// Assert
+		if !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("signed PIN", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newPINUser(t, testPIN)
+		svc, _, _ := newTestPIN(user)
+
+		// Act
+		_, err := svc.VerifyPIN(context.Background(), &models.VerifyPINRequest{UserID: user.ID, PIN: "-58293"})
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("Expected ValidationError, got %v", err)
+		}
+	})
+
+	t.Run("missing user ID", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, _, _ := newTestPIN()
+
+		// Act
+		_, err := svc.VerifyPIN(context.Background(), &models.VerifyPINRequest{PIN: testPIN})
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("Expected ValidationError, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// User service implementation.
+type User struct {
+	UserRepository      interfaces.IUserRepository
+	UserTokenRepository interfaces.IUserTokenRepository
+	Notifier            interfaces.INotifier
+}
+
+// Register validates the request and creates a new inactive, unverified user with the customer role,
+// then emails them a verification token.
+func (s *User) Register(ctx context.Context, req *models.CreateUserRequest) (*models.User, error) {
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.Phone = strings.TrimSpace(req.Phone)
+	req.FullName = strings.TrimSpace(req.FullName)
+
+	if err := validateWithPassword(req, "password", req.Password, req.Email, req.FullName); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureUnique(ctx, req.Email, req.Phone); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := helpers.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Email:        req.Email,
+		Phone:        req.Phone,
+		FullName:     req.FullName,
+		PasswordHash: passwordHash,
+		IsActive:     false,
+		IsVerified:   false,
+	}
+
+	if err := s.UserRepository.CreateWithRole(ctx, user, constants.RoleCustomer); err != nil {
+		return nil, err
+	}
+
+	// The account exists at this point; a failed email can be retried through resend
+	if err := s.sendEmailVerification(ctx, user); err != nil {
+		helpers.Logger.Errorf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+
+	return user, nil
+}
+
+// GetProfile returns the user with the given ID.
+func (s *User) GetProfile(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	return s.UserRepository.GetByID(ctx, userID)
+}
+
+// UpdateProfile applies the fields set in the request to the user's profile.
+// A new phone number must not belong to another user and has to be verified again.
+func (s *User) UpdateProfile(ctx context.Context, userID uuid.UUID, req *models.UpdateUserRequest) (*models.User, error) {
+	if req.FullName != nil {
+		fullName := strings.TrimSpace(*req.FullName)
+		req.FullName = &fullName
+	}
+	if req.Phone != nil {
+		phone := strings.TrimSpace(*req.Phone)
+		req.Phone = &phone
+	}
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Phone != nil && *req.Phone != user.Phone {
+		count, err := s.UserRepository.Count(ctx, models.UserFilter{Phone: *req.Phone})
+		if err != nil {
+			return nil, fmt.Errorf("failed to check phone: %w", err)
+		}
+		if count > 0 {
+			return nil, ErrPhoneAlreadyRegistered
+		}
+		user.Phone = *req.Phone
+		user.IsPhoneVerified = false
+	}
+	if req.FullName != nil {
+		user.FullName = *req.FullName
+	}
+
+	if err := s.UserRepository.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// VerifyEmail consumes an email verification token and marks its user verified. It also
+// activates the account unless support staff deactivated it.
+func (s *User) VerifyEmail(ctx context.Context, req *models.VerifyEmailRequest) error {
+	if err := helpers.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	token, err := s.UserTokenRepository.Consume(ctx, constants.TokenPurposeEmailVerification, helpers.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	user.IsVerified = true
+	activateVerifiedUser(user)
+	return s.UserRepository.Update(ctx, user)
+}
+
+// activateVerifiedUser activates a user who verified their email address or phone number.
+// Accounts deactivated by support staff stay inactive until staff activate them again.
+func activateVerifiedUser(user *models.User) {
+	if user.DeactivatedAt == nil {
+		user.IsActive = true
+	}
+}
+
+// ResendEmailVerification sends a new verification token, invalidating earlier ones.
+// It reports success for unknown or already verified emails and during the resend
+// cooldown, so it cannot be used to discover registered addresses.
+func (s *User) ResendEmailVerification(ctx context.Context, req *models.ResendEmailVerificationRequest) error {
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+
+	if err := helpers.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	user, err := s.UserRepository.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsVerified {
+		return nil
+	}
+
+	latest, err := s.UserTokenRepository.GetLatestByUserID(ctx, user.ID, constants.TokenPurposeEmailVerification)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	cooldown := helpers.GetEnvDuration("EMAIL_VERIFICATION_RESEND_COOLDOWN", constants.DefaultEmailVerificationResendCooldown)
+	if latest != nil && time.Since(latest.CreatedAt) < cooldown {
+		return nil
+	}
+
+	if err := s.UserTokenRepository.InvalidateByUserID(ctx, user.ID, constants.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+	return s.sendEmailVerification(ctx, user)
+}
+
+// sendEmailVerification stores a new verification token for the user and sends it through the notifier.
+func (s *User) sendEmailVerification(ctx context.Context, user *models.User) error {
+	ttl := helpers.GetEnvDuration("EMAIL_VERIFICATION_TTL", constants.DefaultEmailVerificationTTL)
+	token, err := issueUserToken(ctx, s.UserTokenRepository, user.ID, constants.TokenPurposeEmailVerification, ttl)
+	if err != nil {
+		return err
+	}
+
+	return s.Notifier.SendEmailVerification(ctx, user, token)
+}
+
+// issueUserToken stores the hash of a new single-use token and returns the token itself.
+func issueUserToken(
+	ctx context.Context, repo interfaces.IUserTokenRepository, userID uuid.UUID, purpose string, ttl time.Duration,
+) (string, error) {
+	token, err := helpers.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = repo.Create(ctx, &models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: helpers.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ensureUnique checks that neither the email nor the phone is already registered.
+func (s *User) ensureUnique(ctx context.Context, email, phone string) error {
+	count, err := s.UserRepository.Count(ctx, models.UserFilter{Email: email})
+	if err != nil {
+		return fmt.Errorf("failed to check email: %w", err)
+	}
+	if count > 0 {
+		return ErrEmailAlreadyRegistered
+	}
+
+	count, err = s.UserRepository.Count(ctx, models.UserFilter{Phone: phone})
+	if err != nil {
+		return fmt.Errorf("failed to check phone: %w", err)
+	}
+	if count > 0 {
+		return ErrPhoneAlreadyRegistered
+	}
+
+	return nil
+}
@@ -0,0 +1,852 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// fakeUserRepository is an in-memory IUserRepository for service tests.
+type fakeUserRepository struct {
+	err   error
+	users map[uuid.UUID]*models.User
+	roles map[uuid.UUID][]string
+	mu    sync.Mutex
+}
+
+func newFakeUserRepository(users ...*models.User) *fakeUserRepository {
+	repo := &fakeUserRepository{users: make(map[uuid.UUID]*models.User), roles: make(map[uuid.UUID][]string)}
+	for _, u := range users {
+		if u.ID == uuid.Nil {
+			u.ID = uuid.New()
+		}
+		repo.users[u.ID] = u
+	}
+	return repo
+}
+
+func (f *fakeUserRepository) Create(_ context.Context, user *models.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	user.ID = uuid.New()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	stored := *user
+	f.users[user.ID] = &stored
+	return nil
+}
+
+func (f *fakeUserRepository) CreateWithRole(ctx context.Context, user *models.User, roleName string) error {
+	if err := f.Create(ctx, user); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.roles[user.ID] = append(f.roles[user.ID], roleName)
+	return nil
+}
+
+func (f *fakeUserRepository) find(match func(*models.User) bool) (*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, u := range f.users {
+		if !u.DeletedAt.Valid && match(u) {
+			found := *u
+			return &found, nil
+		}
+	}
+	return nil, domain.NewError(domain.ErrNotFound, "user not found")
+}
+
+func (f *fakeUserRepository) GetByID(_ context.Context, id uuid.UUID) (*models.User, error) {
+	return f.find(func(u *models.User) bool { return u.ID == id })
+}
+
+func (f *fakeUserRepository) GetByEmail(_ context.Context, email string) (*models.User, error) {
+	return f.find(func(u *models.User) bool { return u.Email == email })
+}
+
+func (f *fakeUserRepository) GetByPhone(_ context.Context, phone string) (*models.User, error) {
+	return f.find(func(u *models.User) bool { return u.Phone == phone })
+}
+
+func (f *fakeUserRepository) Update(_ context.Context, user *models.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	existing, ok := f.users[user.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return domain.NewError(domain.ErrNotFound, "user not found")
+	}
+	stored := *user
+	stored.PasswordHash = existing.PasswordHash
+	stored.PINHash = existing.PINHash
+	stored.UpdatedAt = time.Now()
+	f.users[user.ID] = &stored
+	return nil
+}
+
+func (f *fakeUserRepository) UpdatePassword(_ context.Context, id uuid.UUID, passwordHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	existing, ok := f.users[id]
+	if !ok || existing.DeletedAt.Valid {
+		return domain.NewError(domain.ErrNotFound, "user not found")
+	}
+	existing.PasswordHash = passwordHash
+	existing.UpdatedAt = time.Now()
+	return nil
+}
+
+// updateLockout applies update to the stored user id under the lock.
+func (f *fakeUserRepository) updateLockout(id uuid.UUID, update func(*models.User)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	existing, ok := f.users[id]
+	if !ok || existing.DeletedAt.Valid {
+		return domain.NewError(domain.ErrNotFound, "user not found")
+	}
+	update(existing)
+	return nil
+}
+
+func (f *fakeUserRepository) RecordFailedLogin(_ context.Context, id uuid.UUID) (int, error) {
+	var attempts int
+	err := f.updateLockout(id, func(u *models.User) {
+		u.FailedLoginAttempts++
+		attempts = u.FailedLoginAttempts
+	})
+	return attempts, err
+}
+
+func (f *fakeUserRepository) Lock(_ context.Context, id uuid.UUID, lockoutCount int, until time.Time) error {
+	return f.updateLockout(id, func(u *models.User) {
+		u.FailedLoginAttempts = 0
+		u.LockoutCount = lockoutCount
+		u.LockedUntil = &until
+	})
+}
+
+func (f *fakeUserRepository) HardLock(_ context.Context, id uuid.UUID) error {
+	return f.updateLockout(id, func(u *models.User) {
+		now := time.Now()
+		u.FailedLoginAttempts = 0
+		u.LockedUntil = nil
+		u.HardLockedAt = &now
+	})
+}
+
+func (f *fakeUserRepository) Unlock(_ context.Context, id uuid.UUID) error {
+	return f.updateLockout(id, func(u *models.User) {
+		u.FailedLoginAttempts = 0
+		u.LockoutCount = 0
+		u.LockedUntil = nil
+		u.HardLockedAt = nil
+	})
+}
+
+func (f *fakeUserRepository) UpdatePIN(_ context.Context, id uuid.UUID, pinHash string) error {
+	return f.updateLockout(id, func(u *models.User) {
+		now := time.Now()
+		u.PINHash = sql.NullString{String: pinHash, Valid: true}
+		u.PINUpdatedAt = &now
+		u.PINFailedAttempts = 0
+		u.PINLockedUntil = nil
+	})
+}
+
+func (f *fakeUserRepository) RecordFailedPIN(_ context.Context, id uuid.UUID) (int, error) {
+	var attempts int
+	err := f.updateLockout(id, func(u *models.User) {
+		u.PINFailedAttempts++
+		attempts = u.PINFailedAttempts
+	})
+	return attempts, err
+}
+
+func (f *fakeUserRepository) LockPIN(_ context.Context, id uuid.UUID, until time.Time) error {
+	return f.updateLockout(id, func(u *models.User) {
+		u.PINFailedAttempts = 0
+		u.PINLockedUntil = &until
+	})
+}
+
+func (f *fakeUserRepository) ClearPINFailures(_ context.Context, id uuid.UUID) error {
+	return f.updateLockout(id, func(u *models.User) {
+		u.PINFailedAttempts = 0
+		u.PINLockedUntil = nil
+	})
+}
+
+func (f *fakeUserRepository) Delete(_ context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	existing, ok := f.users[id]
+	if !ok || existing.DeletedAt.Valid {
+		return domain.NewError(domain.ErrNotFound, "user not found")
+	}
+	existing.DeletedAt.Time = time.Now()
+	existing.DeletedAt.Valid = true
+	return nil
+}
+
+func (f *fakeUserRepository) List(_ context.Context, filter models.UserFilter) ([]*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	var users []*models.User
+	for _, u := range f.users {
+		if matchesFilter(u, filter) {
+			found := *u
+			users = append(users, &found)
+		}
+	}
+	return users, nil
+}
+
+func (f *fakeUserRepository) Count(ctx context.Context, filter models.UserFilter) (int64, error) {
+	users, err := f.List(ctx, filter)
+	return int64(len(users)), err
+}
+
+func matchesFilter(u *models.User, filter models.UserFilter) bool {
+	switch {
+	case u.DeletedAt.Valid:
+		return false
+	case filter.Email != "" && u.Email != filter.Email:
+		return false
+	case filter.Phone != "" && u.Phone != filter.Phone:
+		return false
+	case filter.IsActive != nil && u.IsActive != *filter.IsActive:
+		return false
+	case filter.IsVerified != nil && u.IsVerified != *filter.IsVerified:
+		return false
+	}
+	return true
+}
+
+// fakeRoleRepository is an in-memory IRoleRepository for service tests.
+type fakeRoleRepository struct {
+	err         error
+	roles       map[uuid.UUID][]string
+	permissions map[string][]string
+	mu          sync.Mutex
+}
+
+func newFakeRoleRepository() *fakeRoleRepository {
+	return &fakeRoleRepository{
+		roles: make(map[uuid.UUID][]string),
+		permissions: map[string][]string{
+			"customer": nil,
+			"support":  {"users:read", "users:deactivate", "sessions:revoke"},
+		},
+	}
+}
+
+func (f *fakeRoleRepository) GetRolesByUserID(_ context.Context, userID uuid.UUID) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	return slices.Clone(f.roles[userID]), nil
+}
+
+func (f *fakeRoleRepository) GetPermissionsByUserID(_ context.Context, userID uuid.UUID) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	var permissions []string
+	for _, role := range f.roles[userID] {
+		permissions = append(permissions, f.permissions[role]...)
+	}
+	slices.Sort(permissions)
+	return slices.Compact(permissions), nil
+}
+
+func (f *fakeRoleRepository) AssignRole(_ context.Context, userID uuid.UUID, roleName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	if _, ok := f.permissions[roleName]; !ok {
+		return domain.NewError(domain.ErrNotFound, fmt.Sprintf("role %s not found", roleName))
+	}
+	if !slices.Contains(f.roles[userID], roleName) {
+		f.roles[userID] = append(f.roles[userID], roleName)
+	}
+	return nil
+}
+
+func (f *fakeRoleRepository) RemoveRole(_ context.Context, userID uuid.UUID, roleName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	i := slices.Index(f.roles[userID], roleName)
+	if i < 0 {
+		return domain.NewError(domain.ErrNotFound, "user role not found")
+	}
+	f.roles[userID] = slices.Delete(f.roles[userID], i, i+1)
+	return nil
+}
+
+// fakeUserTokenRepository is an in-memory IUserTokenRepository for service tests.
+type fakeUserTokenRepository struct {
+	err    error
+	tokens []*models.UserToken
+	mu     sync.Mutex
+}
+
+func (f *fakeUserTokenRepository) Create(_ context.Context, token *models.UserToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	token.ID = uuid.New()
+	token.CreatedAt = time.Now()
+	stored := *token
+	f.tokens = append(f.tokens, &stored)
+	return nil
+}
+
+// active returns the unused, unexpired token with the given hash; callers hold mu.
+func (f *fakeUserTokenRepository) active(purpose, tokenHash string) (*models.UserToken, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, t := range f.tokens {
+		if t.Purpose == purpose && t.TokenHash == tokenHash && !t.UsedAt.Valid && t.ExpiresAt.After(time.Now()) {
+			return t, nil
+		}
+	}
+	return nil, domain.NewError(domain.ErrNotFound, "token not found")
+}
+
+func (f *fakeUserTokenRepository) GetActive(_ context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, err := f.active(purpose, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	found := *t
+	return &found, nil
+}
+
+func (f *fakeUserTokenRepository) Consume(_ context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, err := f.active(purpose, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	t.UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	found := *t
+	return &found, nil
+}
+
+func (f *fakeUserTokenRepository) GetLatestByUserID(_ context.Context, userID uuid.UUID, purpose string) (*models.UserToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	for i := len(f.tokens) - 1; i >= 0; i-- {
+		if t := f.tokens[i]; t.UserID == userID && t.Purpose == purpose {
+			found := *t
+			return &found, nil
+		}
+	}
+	return nil, domain.NewError(domain.ErrNotFound, "token not found")
+}
+
+func (f *fakeUserTokenRepository) InvalidateByUserID(_ context.Context, userID uuid.UUID, purpose string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	for _, t := range f.tokens {
+		if t.UserID == userID && t.Purpose == purpose && !t.UsedAt.Valid {
+			t.UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+	}
+	return nil
+}
+
+// fakeNotifier records the tokens and login alerts it was asked to deliver.
+type fakeNotifier struct {
+	err    error
+	tokens map[uuid.UUID][]string
+	alerts map[uuid.UUID][]*models.LoginRiskAssessment
+	mu     sync.Mutex
+}
+
+func newFakeNotifier() *fakeNotifier {
+	return &fakeNotifier{
+		tokens: make(map[uuid.UUID][]string),
+		alerts: make(map[uuid.UUID][]*models.LoginRiskAssessment),
+	}
+}
+
+func (f *fakeNotifier) SendEmailVerification(_ context.Context, user *models.User, token string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.tokens[user.ID] = append(f.tokens[user.ID], token)
+	return nil
+}
+
+func (f *fakeNotifier) SendPasswordReset(ctx context.Context, user *models.User, token string) error {
+	return f.SendEmailVerification(ctx, user, token)
+}
+
+func (f *fakeNotifier) SendLoginAlert(_ context.Context, user *models.User, assessment *models.LoginRiskAssessment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.alerts[user.ID] = append(f.alerts[user.ID], assessment)
+	return nil
+}
+
+func (f *fakeNotifier) sent(userID uuid.UUID) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.tokens[userID])
+}
+
+func (f *fakeNotifier) sentAlerts(userID uuid.UUID) []*models.LoginRiskAssessment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.alerts[userID])
+}
+
+// newTestUserService returns a User service over in-memory repositories.
+func newTestUserService(users *fakeUserRepository) (*User, *fakeNotifier) {
+	notifier := newFakeNotifier()
+	return &User{
+		UserRepository:      users,
+		UserTokenRepository: &fakeUserTokenRepository{},
+		Notifier:            notifier,
+	}, notifier
+}
+
+func validCreateUserRequest() *models.CreateUserRequest {
+	return &models.CreateUserRequest{
+		Email:    " John@Example.com ",
+		Phone:    "+6281234567890",
+		FullName: "John Doe",
+		Password: "password123",
+	}
+}
+
+func TestUser_Register(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		repo := newFakeUserRepository()
+		svc, notifier := newTestUserService(repo)
+
+		// Act
+		user, err := svc.Register(context.Background(), validCreateUserRequest())
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if user.Email != "john@example.com" {
+			t.Errorf("Expected normalized email, got '%s'", user.Email)
+		}
+		if user.IsActive || user.IsVerified {
+			t.Error("Expected new user to be inactive and unverified")
+		}
+		if user.PasswordHash == "password123" || !helpers.CheckPassword(user.PasswordHash, "password123") {
+			t.Error("Expected password to be stored hashed")
+		}
+		if granted := repo.roles[user.ID]; !slices.Equal(granted, []string{"customer"}) {
+			t.Errorf("Expected customer role, got %v", granted)
+		}
+		if len(notifier.sent(user.ID)) != 1 {
+			t.Error("Expected a verification email to be sent")
+		}
+	})
+
+	t.Run("validation failure", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, _ := newTestUserService(newFakeUserRepository())
+		req := &models.CreateUserRequest{Email: "not-an-email", Phone: "0812", Password: "short"}
+
+		// Act
+		_, err := svc.Register(context.Background(), req)
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+		if len(validationErr.Fields) != 4 {
+			t.Errorf("Expected 4 invalid fields, got %+v", validationErr.Fields)
+		}
+	})
+
+	t.Run("password containing the name", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, _ := newTestUserService(newFakeUserRepository())
+		req := validCreateUserRequest()
+		req.Password = "JohnDoe-2024"
+
+		// Act
+		_, err := svc.Register(context.Background(), req)
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) || len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != "password" {
+			t.Errorf("Expected a password policy violation, got %v", err)
+		}
+	})
+
+	t.Run("duplicate email", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		repo := newFakeUserRepository(&models.User{Email: "john@example.com", Phone: "+6280000000000"})
+		svc, _ := newTestUserService(repo)
+
+		// Act
+		_, err := svc.Register(context.Background(), validCreateUserRequest())
+
+		// Assert
+		if !errors.Is(err, ErrEmailAlreadyRegistered) {
+			t.Errorf("Expected ErrEmailAlreadyRegistered, got %v", err)
+		}
+	})
+
+	t.Run("duplicate phone", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		repo := newFakeUserRepository(&models.User{Email: "other@example.com", Phone: "+6281234567890"})
+		svc, _ := newTestUserService(repo)
+
+		// Act
+		_, err := svc.Register(context.Background(), validCreateUserRequest())
+
+		// Assert
+		if !errors.Is(err, ErrPhoneAlreadyRegistered) {
+			t.Errorf("Expected ErrPhoneAlreadyRegistered, got %v", err)
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		repo := newFakeUserRepository()
+		repo.err = errors.New("db down")
+		svc, _ := newTestUserService(repo)
+
+		// Act
+		_, err := svc.Register(context.Background(), validCreateUserRequest())
+
+		// Assert
+		if err == nil {
+			t.Error("Expected error when repository fails, got nil")
+		}
+	})
+}
+
+func TestUser_UpdateProfile(t *testing.T) {
+	t.Parallel()
+
+	newUser := func() *models.User {
+		return &models.User{Email: "john@example.com", Phone: "+6281234567890", FullName: "John Doe", PasswordHash: "hash"}
+	}
+	ptr := func(s string) *string { return &s }
+
+	t.Run("applies only set fields", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newUser()
+		repo := newFakeUserRepository(user)
+		svc := &User{UserRepository: repo}
+
+		// Act
+		updated, err := svc.UpdateProfile(context.Background(), user.ID, &models.UpdateUserRequest{FullName: ptr(" Jane Doe ")})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if updated.FullName != "Jane Doe" || updated.Phone != user.Phone {
+			t.Errorf("Unexpected profile after update: %+v", updated)
+		}
+		stored, _ := repo.GetByID(context.Background(), user.ID)
+		if stored.FullName != "Jane Doe" {
+			t.Errorf("Expected update to be persisted, got '%s'", stored.FullName)
+		}
+	})
+
+	t.Run("keeping own phone is not a conflict", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newUser()
+		svc := &User{UserRepository: newFakeUserRepository(user)}
+
+		// Act
+		_, err := svc.UpdateProfile(context.Background(), user.ID, &models.UpdateUserRequest{Phone: ptr(user.Phone)})
+
+		// Assert
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("changing phone resets phone verification", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newUser()
+		user.IsPhoneVerified = true
+		svc := &User{UserRepository: newFakeUserRepository(user)}
+
+		// Act
+		updated, err := svc.UpdateProfile(context.Background(), user.ID, &models.UpdateUserRequest{Phone: ptr("+6281111111111")})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if updated.IsPhoneVerified {
+			t.Error("Expected new phone number to be unverified")
+		}
+	})
+
+	t.Run("duplicate phone", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newUser()
+		other := &models.User{Email: "other@example.com", Phone: "+6280000000000"}
+		svc := &User{UserRepository: newFakeUserRepository(user, other)}
+
+		// Act
+		_, err := svc.UpdateProfile(context.Background(), user.ID, &models.UpdateUserRequest{Phone: ptr(other.Phone)})
+
+		// Assert
+		if !errors.Is(err, ErrPhoneAlreadyRegistered) {
+			t.Errorf("Expected ErrPhoneAlreadyRegistered, got %v", err)
+		}
+	})
+
+	t.Run("validation failure", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		user := newUser()
+		svc := &User{UserRepository: newFakeUserRepository(user)}
+		req := &models.UpdateUserRequest{FullName: ptr("  "), Phone: ptr("0812")}
+
+		// Act
+		_, err := svc.UpdateProfile(context.Background(), user.ID, req)
+
+		// Assert
+		var validationErr *helpers.ValidationError
+		if !errors.As(err, &validationErr) || len(validationErr.Fields) != 2 {
+			t.Errorf("Expected ValidationError for both fields, got %v", err)
+		}
+	})
+}
+
+func TestUser_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	t.Run("activates the user and consumes the token", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		repo := newFakeUserRepository()
+		svc, notifier := newTestUserService(repo)
+		user, err := svc.Register(context.Background(), validCreateUserRequest())
+		if err != nil {
+			t.Fatalf("Failed to register: %v", err)
+		}
+		token := notifier.sent(user.ID)[0]
+
+		// Act
+		err = svc.VerifyEmail(context.Background(), &models.VerifyEmailRequest{Token: token})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		stored, _ := repo.GetByID(context.Background(), user.ID)
+		if !stored.IsVerified || !stored.IsActive {
+			t.Errorf("Expected user to be verified and active, got %+v", stored)
+		}
+		reuseErr := svc.VerifyEmail(context.Background(), &models.VerifyEmailRequest{Token: token})
+		if !errors.Is(reuseErr, ErrInvalidVerificationToken) {
+			t.Errorf("Expected ErrInvalidVerificationToken on reuse, got %v", reuseErr)
+		}
+	})
+
+	t.Run("keeps a deactivated user inactive", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		repo := newFakeUserRepository()
+		svc, notifier := newTestUserService(repo)
+		user, err := svc.Register(context.Background(), validCreateUserRequest())
+		if err != nil {
+			t.Fatalf("Failed to register: %v", err)
+		}
+		admin := &AdminUser{UserRepository: repo, SessionRepository: newFakeSessionRepository()}
+		inactive := false
+		if _, err := admin.UpdateUser(context.Background(), user.ID, &models.AdminUpdateUserRequest{IsActive: &inactive}); err != nil {
+			t.Fatalf("Failed to deactivate: %v", err)
+		}
+		token := notifier.sent(user.ID)[0]
+
+		// Act
+		err = svc.VerifyEmail(context.Background(), &models.VerifyEmailRequest{Token: token})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		stored, _ := repo.GetByID(context.Background(), user.ID)
+		if !stored.IsVerified || stored.IsActive {
+			t.Errorf("Expected user to be verified but stay inactive, got %+v", stored)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		svc, _ := newTestUserService(newFakeUserRepository())
+
+		// Act
+		err := svc.VerifyEmail(context.Background(), &models.VerifyEmailRequest{Token: "unknown"})
+
+		// Assert
+		if !errors.Is(err, ErrInvalidVerificationToken) {
+			t.Errorf("Expected ErrInvalidVerificationToken, got %v", err)
+		}
+	})
+}
+
+func TestUser_ResendEmailVerification(t *testing.T) {
+	t.Setenv("EMAIL_VERIFICATION_RESEND_COOLDOWN", "0s")
+
+	t.Run("sends a new token and invalidates the old one", func(t *testing.T) {
+		// Arrange
+		repo := newFakeUserRepository()
+		svc, notifier := newTestUserService(repo)
+		user, err := svc.Register(context.Background(), validCreateUserRequest())
+		if err != nil {
+			t.Fatalf("Failed to register: %v", err)
+		}
+
+		// Act
+		err = svc.ResendEmailVerification(context.Background(), &models.ResendEmailVerificationRequest{Email: user.Email})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		sent := notifier.sent(user.ID)
+		if len(sent) != 2 {
+			t.Fatalf("Expected 2 verification emails, got %d", len(sent))
+		}
+		oldErr := svc.VerifyEmail(context.Background(), &models.VerifyEmailRequest{Token: sent[0]})
+		if !errors.Is(oldErr, ErrInvalidVerificationToken) {
+			t.Errorf("Expected earlier token to be invalidated, got %v", oldErr)
+		}
+	})
+
+	t.Run("silent within cooldown", func(t *testing.T) {
+		// Arrange
+		t.Setenv("EMAIL_VERIFICATION_RESEND_COOLDOWN", "1h")
+		svc, notifier := newTestUserService(newFakeUserRepository())
+		user, err := svc.Register(context.Background(), validCreateUserRequest())
+		if err != nil {
+			t.Fatalf("Failed to register: %v", err)
+		}
+
+		// Act
+		err = svc.ResendEmailVerification(context.Background(), &models.ResendEmailVerificationRequest{Email: user.Email})
+
+		// Assert
+		if err != nil || len(notifier.sent(user.ID)) != 1 {
+			t.Errorf("Expected silent success without a new email, got err %v and %d emails", err, len(notifier.sent(user.ID)))
+		}
+	})
+
+	t.Run("silent for unknown and verified emails", func(t *testing.T) {
+		// Arrange
+		verified := &models.User{Email: "verified@example.com", IsVerified: true}
+		svc, notifier := newTestUserService(newFakeUserRepository(verified))
+
+		for _, email := range []string{"nobody@example.com", verified.Email} {
+			// Act
+			err := svc.ResendEmailVerification(context.Background(), &models.ResendEmailVerificationRequest{Email: email})
+
+			// Assert
+			if err != nil {
+				t.Errorf("Expected no error for %s, got %v", email, err)
+			}
+		}
+		if len(notifier.sent(verified.ID)) != 0 {
+			t.Error("Expected no email for a verified user")
+		}
+	})
+}
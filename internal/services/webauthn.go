@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ibnuzaman/ewallet-ums/helpers"
+	"github.com/ibnuzaman/ewallet-ums/internal/constants"
+	"github.com/ibnuzaman/ewallet-ums/internal/domain"
+	"github.com/ibnuzaman/ewallet-ums/internal/interfaces"
+	"github.com/ibnuzaman/ewallet-ums/internal/models"
+)
+
+// webAuthnUser adapts a user and their stored credentials to webauthn.User. The user
+// handle given to authenticators is the raw user ID.
+type webAuthnUser struct {
+	user        *models.User
+	credentials []webauthn.Credential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return u.user.ID[:]
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.FullName
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// loadWebAuthnUser loads the credentials of a user for a WebAuthn ceremony.
+func loadWebAuthnUser(ctx context.Context, repo interfaces.IWebAuthnRepository, user *models.User) (*webAuthnUser, error) {
+	stored, err := repo.ListCredentialsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(stored))
+	for i := range stored {
+		credentials = append(credentials, toWebAuthnCredential(&stored[i]))
+	}
+	return &webAuthnUser{user: user, credentials: credentials}, nil
+}
+
+func toWebAuthnCredential(c *models.UserCredential) webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, 0, len(c.Transports))
+	for _, transport := range c.Transports {
+		transports = append(transports, protocol.AuthenticatorTransport(transport))
+	}
+
+	return webauthn.Credential{
+		ID:              c.CredentialID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			UserPresent:    true,
+			BackupEligible: c.BackupEligible,
+			BackupState:    c.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    c.AAGUID,
+			SignCount: uint32(c.SignCount), //nolint:gosec // stored from a uint32 counter
+		},
+	}
+}
+
+// saveWebAuthnSession stores a pending ceremony until the client answers its challenge.
+func saveWebAuthnSession(
+	ctx context.Context, repo interfaces.IWebAuthnRepository, purpose string, userID uuid.NullUUID, session *webauthn.SessionData,
+) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode WebAuthn session: %w", err)
+	}
+
+	return repo.CreateSession(ctx, &models.WebAuthnSession{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Purpose:     purpose,
+		Challenge:   session.Challenge,
+		SessionData: data,
+		ExpiresAt:   session.Expires,
+	})
+}
+
+// consumeWebAuthnSession removes the pending ceremony whose challenge the client signed,
+// so every challenge is answered at most once.
+func consumeWebAuthnSession(
+	ctx context.Context, repo interfaces.IWebAuthnRepository, purpose, challenge string,
+) (*webauthn.SessionData, error) {
+	stored, err := repo.ConsumeSession(ctx, purpose, challenge)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrInvalidPasskey
+		}
+		return nil, err
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal(stored.SessionData, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode WebAuthn session: %w", err)
+	}
+	return &session, nil
+}
+
+// recordPasskeyUse stores the signature counter of a verified assertion. A counter that did
+// not increase means the authenticator may have been cloned, so the assertion is rejected.
+func recordPasskeyUse(ctx context.Context, repo interfaces.IWebAuthnRepository, userID uuid.UUID, credential *webauthn.Credential) error {
+	if credential.Authenticator.CloneWarning {
+		helpers.LogSecurityEvent(constants.SecurityEventPasskeyCloneWarning, logrus.Fields{
+			"user_id":       userID,
+			"credential_id": base64.RawURLEncoding.EncodeToString(credential.ID),
+		})
+		return ErrInvalidPasskey
+	}
+
+	err := repo.UpdateCredentialUsage(ctx, credential.ID, credential.Authenticator.SignCount, credential.Flags.BackupState)
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrInvalidPasskey
+	}
+	return err
+}
+
+// invalidPasskey logs the details of a WebAuthn verification failure and returns
+// ErrInvalidPasskey, as error responses include the error text.
+func invalidPasskey(err error) error {
+	if !errors.Is(err, ErrInvalidPasskey) {
+		helpers.Logger.Warnf("Passkey verification failed: %v", err)
+	}
+	return ErrInvalidPasskey
+}
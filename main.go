@@ -17,6 +17,26 @@ func main() {
 	// Setup logger after config
 	helpers.SetupLogger()
 
+	// Load access token signing keys
+	if err := helpers.SetupTokenKeys(); err != nil {
+		helpers.Logger.Fatalf("Failed to load token keys: %v", err)
+	}
+
+	// Load the password hashing parameters
+	if err := helpers.SetupPasswordHashing(); err != nil {
+		helpers.Logger.Fatalf("Failed to load password hashing parameters: %v", err)
+	}
+
+	// Load the password policy and breached password list
+	if err := helpers.SetupPasswordPolicy(); err != nil {
+		helpers.Logger.Fatalf("Failed to load password policy: %v", err)
+	}
+
+	// Load the key encrypting secrets at rest, such as TOTP secrets
+	if err := helpers.SetupEncryptionKey(); err != nil {
+		helpers.Logger.Fatalf("Failed to load data encryption key: %v", err)
+	}
+
 	// Initialize database connection
 	db, err := database.InitPostgres()
 	if err != nil {